@@ -0,0 +1,197 @@
+package collector
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/elohmeier/netscaler-exporter/config"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// circuitState is the state of a module's circuit breaker.
+type circuitState int
+
+const (
+	circuitClosed   circuitState = iota // module runs on every scrape
+	circuitOpen                         // module is suspended until the backoff expires
+	circuitHalfOpen                     // one scrape is retrying the module
+)
+
+func (s circuitState) String() string {
+	switch s {
+	case circuitOpen:
+		return "open"
+	case circuitHalfOpen:
+		return "half_open"
+	default:
+		return "closed"
+	}
+}
+
+// circuit tracks the consecutive failures of one module.
+type circuit struct {
+	state     circuitState
+	failures  int
+	backoff   time.Duration
+	openUntil time.Time
+	lastErr   error
+}
+
+// breaker holds the circuits of an exporter's modules. Modules without
+// failures since their last success have no circuit.
+type breaker struct {
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+// ModuleStatus describes a module whose recent scrapes failed.
+type ModuleStatus struct {
+	Module              string    `json:"module"`
+	State               string    `json:"state"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	Reason              string    `json:"reason"`
+	LastError           string    `json:"last_error"`
+	SuspendedUntil      time.Time `json:"suspended_until,omitzero"`
+}
+
+// breakerCounts reports whether err counts towards opening a circuit. Only
+// lasting errors the appliance answered with count; network, authentication,
+// deadline and transient errors affect every module alike or go away on their
+// own and are left to netscaler_up.
+func breakerCounts(err error) bool {
+	switch scrapeErrorReason(err) {
+	case "permission", "unsupported", "not_found", "http", "parse", "other":
+		return true
+	}
+	return false
+}
+
+// allowModule reports whether the module should run in this scrape. An open
+// circuit whose backoff expired turns half-open and lets this scrape retry it;
+// concurrent scrapes skip the module until the retry is recorded.
+func (e *Exporter) allowModule(module string) bool {
+	if e.config.BreakerFailures <= 0 {
+		return true
+	}
+
+	e.breaker.mu.Lock()
+	defer e.breaker.mu.Unlock()
+	c := e.breaker.circuits[module]
+	if c == nil {
+		return true
+	}
+	switch c.state {
+	case circuitOpen:
+		if time.Now().Before(c.openUntil) {
+			return false
+		}
+		c.state = circuitHalfOpen
+		e.logger.Info("retrying suspended module", "url", e.url, "module", module, "failures", c.failures)
+		return true
+	case circuitHalfOpen:
+		return false
+	default:
+		return true
+	}
+}
+
+// recordModule records the outcome of a module that allowModule let run.
+// A success closes the circuit; after BreakerFailures consecutive failures the
+// circuit opens for BreakerBackoff, doubled on every failed retry up to
+// BreakerMaxBackoff.
+func (e *Exporter) recordModule(module string, err error) {
+	if e.config.BreakerFailures <= 0 {
+		return
+	}
+
+	e.breaker.mu.Lock()
+	defer e.breaker.mu.Unlock()
+	c := e.breaker.circuits[module]
+
+	if err == nil {
+		if c != nil && c.state != circuitClosed {
+			e.logger.Info("module recovered, resuming scrapes", "url", e.url, "module", module, "failures", c.failures)
+		}
+		delete(e.breaker.circuits, module)
+		return
+	}
+
+	if !breakerCounts(err) {
+		// Retry an interrupted half-open probe on the next scrape
+		if c != nil && c.state == circuitHalfOpen {
+			c.state = circuitOpen
+			c.openUntil = time.Now()
+		}
+		return
+	}
+
+	if c == nil {
+		if e.breaker.circuits == nil {
+			e.breaker.circuits = make(map[string]*circuit)
+		}
+		c = &circuit{}
+		e.breaker.circuits[module] = c
+	}
+	c.failures++
+	c.lastErr = err
+
+	switch {
+	case c.state == circuitHalfOpen:
+		c.backoff *= 2
+		if limit := e.config.BreakerMaxBackoff; limit > 0 && c.backoff > limit {
+			c.backoff = limit
+		}
+	case c.failures >= e.config.BreakerFailures:
+		c.backoff = e.config.BreakerBackoff
+	default:
+		return
+	}
+	c.state = circuitOpen
+	c.openUntil = time.Now().Add(c.backoff)
+	e.logger.Warn("suspending failing module", "url", e.url, "module", module, "failures", c.failures, "backoff", c.backoff, "err", err)
+}
+
+// collectModuleCircuits emits the circuit state of the modules that are not skipped.
+func (e *Exporter) collectModuleCircuits(ch chan<- prometheus.Metric, skip func(string) bool) {
+	if e.config.BreakerFailures <= 0 {
+		return
+	}
+
+	e.breaker.mu.Lock()
+	defer e.breaker.mu.Unlock()
+	for _, module := range config.ModuleNames {
+		if skip(module) {
+			continue
+		}
+		state := circuitClosed
+		if c := e.breaker.circuits[module]; c != nil {
+			state = c.state
+		}
+		ch <- prometheus.MustNewConstMetric(e.moduleCircuitState, prometheus.GaugeValue, float64(state), e.buildLabelValues(module)...)
+	}
+}
+
+// ModuleStatus returns the modules that failed since their last success,
+// sorted by name, with the reason and, if suspended, when they are retried.
+func (e *Exporter) ModuleStatus() []ModuleStatus {
+	e.breaker.mu.Lock()
+	defer e.breaker.mu.Unlock()
+
+	status := make([]ModuleStatus, 0, len(e.breaker.circuits))
+	for module, c := range e.breaker.circuits {
+		s := ModuleStatus{
+			Module:              module,
+			State:               c.state.String(),
+			ConsecutiveFailures: c.failures,
+			Reason:              scrapeErrorReason(c.lastErr),
+			LastError:           c.lastErr.Error(),
+		}
+		if c.state == circuitOpen {
+			s.SuspendedUntil = c.openUntil
+		}
+		status = append(status, s)
+	}
+	sort.Slice(status, func(i, j int) bool { return status[i].Module < status[j].Module })
+	return status
+}
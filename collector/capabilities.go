@@ -0,0 +1,222 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elohmeier/netscaler-exporter/config"
+	"github.com/elohmeier/netscaler-exporter/netscaler"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// moduleFeatures maps the modules that depend on a licensed and enabled
+// appliance feature to the nslicense/nsfeature flags that provide it.
+// Any one of the listed features makes the module applicable.
+var moduleFeatures = map[string][]string{
+	"topology":        {"lb"},
+	"virtual_servers": {"lb"},
+	"gslb_services":   {"gslb"},
+	"gslb_vservers":   {"gslb"},
+	"cs_vservers":     {"cs"},
+	"vpn_vservers":    {"sslvpn"},
+	"aaa_stats":       {"aaa", "sslvpn"},
+	"ssl_vservers":    {"ssl"},
+}
+
+// capabilityTimeout bounds a capability detection, separately from the
+// deadlines of the scrape's modules.
+const capabilityTimeout = 10 * time.Second
+
+// capabilities is what capability detection learned about an appliance.
+// A nil map or negative haNodes means the resource could not be read, and
+// modules depending on it are kept. The licensed and enabled maps hold only
+// the flags the appliance reported; a missing flag is unknown.
+type capabilities struct {
+	version  string
+	modes    []string
+	licensed map[string]bool
+	enabled  map[string]bool
+	haNodes  int
+}
+
+// skippedModules returns the modules not applicable to the appliance, by reason.
+func (c capabilities) skippedModules() map[string]string {
+	skipped := make(map[string]string)
+	for module, features := range moduleFeatures {
+		if lacksFeatures(c.licensed, features) {
+			skipped[module] = "not_licensed"
+		} else if lacksFeatures(c.enabled, features) {
+			skipped[module] = "feature_disabled"
+		}
+	}
+	if c.haNodes >= 0 && c.haNodes <= 1 {
+		skipped["ha_stats"] = "standalone"
+	}
+	return skipped
+}
+
+// lacksFeatures reports whether the flags show none of the features: each of
+// them must be reported, and false.
+func lacksFeatures(flags map[string]bool, features []string) bool {
+	for _, f := range features {
+		if set, known := flags[f]; !known || set {
+			return false
+		}
+	}
+	return true
+}
+
+// knownFlags returns the reported flags by feature.
+func knownFlags(flags map[string]*bool) map[string]bool {
+	known := make(map[string]bool, len(flags))
+	for f, set := range flags {
+		if set != nil {
+			known[f] = *set
+		}
+	}
+	return known
+}
+
+// capabilityState caches the modules skipped by capability detection, which
+// a background refresher keeps up to date.
+type capabilityState struct {
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	done    chan struct{}
+	skipped map[string]string
+}
+
+// StartCapabilityDetection detects the appliance's capabilities in the
+// background right away and again every CapabilityRefresh interval, so that
+// scrapes skip unsupported modules without waiting for a detection. Scrapes
+// before the first detection completes run every module. It must be called
+// after the client is configured and does nothing if detection is disabled.
+func (e *Exporter) StartCapabilityDetection() {
+	if e.nsClient == nil || e.config.CapabilityRefresh <= 0 {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	e.capabilities.cancel = cancel
+	e.capabilities.done = make(chan struct{})
+	go e.refreshCapabilities(ctx)
+}
+
+// stopCapabilityDetection stops the background refresher, if it was started.
+func (e *Exporter) stopCapabilityDetection() {
+	if e.capabilities.cancel != nil {
+		e.capabilities.cancel()
+		<-e.capabilities.done
+	}
+}
+
+// refreshCapabilities detects capabilities until ctx is cancelled.
+func (e *Exporter) refreshCapabilities(ctx context.Context) {
+	defer close(e.capabilities.done)
+	ticker := time.NewTicker(e.config.CapabilityRefresh)
+	defer ticker.Stop()
+	for {
+		e.updateCapabilities(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// skippedModules returns the modules the appliance does not support, by reason,
+// as of the last capability detection.
+func (e *Exporter) skippedModules() map[string]string {
+	e.capabilities.mu.Lock()
+	defer e.capabilities.mu.Unlock()
+	return e.capabilities.skipped
+}
+
+// updateCapabilities detects the appliance's capabilities, bounded by
+// capabilityTimeout, and caches the modules to skip. Detection fails open:
+// modules are only skipped on evidence, and a failed detection keeps the
+// previous decision.
+func (e *Exporter) updateCapabilities(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, capabilityTimeout)
+	defer cancel()
+	caps, err := e.detectCapabilities(ctx)
+	if err != nil {
+		if !errors.Is(ctx.Err(), context.Canceled) {
+			e.logger.Warn("failed to detect appliance capabilities", "url", e.url, "err", err)
+		}
+		return
+	}
+	e.logger.Info("detected appliance capabilities", "url", e.url, "version", caps.version, "modes", strings.Join(caps.modes, ","), "ha_nodes", caps.haNodes)
+
+	skipped := caps.skippedModules()
+	e.capabilities.mu.Lock()
+	defer e.capabilities.mu.Unlock()
+	for _, module := range config.ModuleNames {
+		reason, skip := skipped[module]
+		prev, wasSkipped := e.capabilities.skipped[module]
+		switch {
+		case skip && (!wasSkipped || reason != prev):
+			e.logger.Info("skipping module not supported by the appliance", "url", e.url, "module", module, "reason", reason)
+		case !skip && wasSkipped:
+			e.logger.Info("module supported by the appliance again", "url", e.url, "module", module)
+		}
+	}
+	e.capabilities.skipped = skipped
+}
+
+// detectCapabilities queries the appliance's version, license, features,
+// modes and HA nodes. It returns an error only if every query failed.
+func (e *Exporter) detectCapabilities(ctx context.Context) (capabilities, error) {
+	caps := capabilities{haNodes: -1}
+	var errs []error
+
+	if v, err := netscaler.GetNSVersion(ctx, e.nsClient); err != nil {
+		errs = append(errs, err)
+	} else {
+		caps.version = v.NSVersion.Version
+	}
+	if m, err := netscaler.GetNSMode(ctx, e.nsClient); err != nil {
+		errs = append(errs, err)
+	} else {
+		caps.modes = m.NSMode.Mode
+	}
+	if l, err := netscaler.GetNSLicense(ctx, e.nsClient, ""); err != nil {
+		errs = append(errs, err)
+	} else {
+		lic := l.NSLicense
+		caps.licensed = knownFlags(map[string]*bool{"lb": lic.LB, "cs": lic.CS, "ssl": lic.SSL, "gslb": lic.GSLB, "sslvpn": lic.SSLVPN, "aaa": lic.AAA})
+	}
+	if f, err := netscaler.GetNSFeature(ctx, e.nsClient); err != nil {
+		errs = append(errs, err)
+	} else {
+		feat := f.NSFeature
+		caps.enabled = knownFlags(map[string]*bool{"lb": feat.LB, "cs": feat.CS, "ssl": feat.SSL, "gslb": feat.GSLB, "sslvpn": feat.SSLVPN, "aaa": feat.AAA})
+	}
+	if ha, err := netscaler.GetHANodeConfig(ctx, e.nsClient); err != nil {
+		errs = append(errs, err)
+	} else {
+		caps.haNodes = len(ha.HANodes)
+	}
+
+	if len(errs) == 5 {
+		return caps, errors.Join(errs...)
+	}
+	return caps, nil
+}
+
+// collectModuleEnabled emits whether each module runs and, if not, why:
+// "disabled" by configuration or the capability detection reason.
+func (e *Exporter) collectModuleEnabled(ch chan<- prometheus.Metric, skipped map[string]string) {
+	for _, module := range config.ModuleNames {
+		enabled, reason := 1.0, ""
+		if e.config.IsModuleDisabled(module) {
+			enabled, reason = 0, "disabled"
+		} else if r, ok := skipped[module]; ok {
+			enabled, reason = 0, r
+		}
+		ch <- prometheus.MustNewConstMetric(e.moduleEnabled, prometheus.GaugeValue, enabled, e.buildLabelValues(module, reason)...)
+	}
+}
@@ -2,10 +2,12 @@ package collector
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/elohmeier/netscaler-exporter/netscaler"
@@ -13,31 +15,125 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
-// Collect is initiated by the Prometheus handler and gathers the metrics
+// DefaultScrapeTimeout bounds a scrape whose context has no deadline.
+const DefaultScrapeTimeout = 60 * time.Second
+
+// lowPriorityModules run after all other modules when a scrape has a call
+// budget and are deferred once it is spent: service group member stats cost one
+// call per group, and certificates and per-core CPU stats are the least urgent.
+var lowPriorityModules = map[string]bool{
+	"service_groups": true,
+	"ssl_certs":      true,
+	"ssl_vservers":   true,
+	"system_cpu":     true,
+}
+
+// Collect is initiated by the Prometheus handler and gathers the metrics.
+// When background polling is enabled it serves the last snapshot instead.
+// The scrape is bounded by DefaultScrapeTimeout; use WithContext to bound it
+// by the scrape request instead.
 func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
+	e.collect(context.Background(), ch)
+}
+
+// WithContext returns a collector for the exporter whose scrapes run within ctx,
+// typically the context of the HTTP request that Prometheus is waiting on.
+func (e *Exporter) WithContext(ctx context.Context) prometheus.Collector {
+	return &contextCollector{e: e, ctx: ctx}
+}
+
+// contextCollector is an Exporter bound to the context of one scrape request.
+type contextCollector struct {
+	e   *Exporter
+	ctx context.Context
+}
+
+// Describe implements prometheus.Collector.
+func (c *contextCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.e.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *contextCollector) Collect(ch chan<- prometheus.Metric) {
+	c.e.collect(c.ctx, ch)
+}
+
+func (e *Exporter) collect(ctx context.Context, ch chan<- prometheus.Metric) {
+	if e.poller != nil {
+		e.poller.collect(ctx, ch)
+		return
+	}
+	e.scrape(ctx, ch)
+}
+
+// scrape runs a full scrape of the target and reports whether any module failed.
+// Labelled metrics are gathered in a set owned by this scrape, so concurrent
+// scrapes of the same exporter never see each other's values.
+// Without a deadline on ctx the scrape is bounded by DefaultScrapeTimeout.
+func (e *Exporter) scrape(ctx context.Context, ch chan<- prometheus.Metric) (partial bool) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultScrapeTimeout)
+		defer cancel()
+	}
+
+	var status scrapeStatus
+	set := newMetricSet()
 	if e.targetType == "mps" {
-		e.scrapeMPS(ch)
+		e.scrapeMPS(ctx, ch, set, &status)
 	} else {
-		e.scrapeADC(ch)
+		e.scrapeADC(ctx, ch, set, &status)
 	}
+	set.collect(ch)
+	e.collectScrapeHealth(ch, &status)
+	return status.failed.Load() > 0
 }
 
-// scrapeADC scrapes the NetScaler ADC instance
-func (e *Exporter) scrapeADC(ch chan<- prometheus.Metric) {
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
-	defer cancel()
+// moduleContext returns the context a module runs in: ctx, further bounded by
+// the module's timeout if one is configured.
+func (e *Exporter) moduleContext(ctx context.Context, module string) (context.Context, context.CancelFunc) {
+	if timeout := e.config.ModuleTimeout(module); timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
 
+// scrapeADC scrapes the NetScaler ADC instance
+func (e *Exporter) scrapeADC(ctx context.Context, ch chan<- prometheus.Metric, set *metricSet, status *scrapeStatus) {
 	// Use persistent client with session-based authentication
 	nsClient := e.nsClient
 
+	// Skip modules disabled by configuration or not supported by the appliance
+	skipped := e.skippedModules()
+	e.collectModuleEnabled(ch, skipped)
+	skip := func(name string) bool {
+		_, unsupported := skipped[name]
+		return unsupported || e.config.IsModuleDisabled(name)
+	}
+
+	// With a call budget, low-priority modules run after all others and are
+	// deferred to a later scrape once the budget is spent
+	var budget *netscaler.CallBudget
+	if e.callBudget > 0 {
+		budget = netscaler.NewCallBudget(e.callBudget)
+		ctx = netscaler.WithCallBudget(ctx, budget)
+	}
+	type module struct {
+		name     string
+		scrapeFn func(ctx context.Context) error
+	}
+	var lowPriority []module
+
 	var wg sync.WaitGroup
 	// Semaphore to limit concurrent requests to avoid overloading the NetScaler
 	sem := make(chan struct{}, e.parallelism)
+	// Paged collections fetch further pages with the tokens left over
+	ctx = netscaler.WithSemaphore(ctx, sem)
 
-	// Helper to run a scrape function concurrently
-	run := func(name string, scrapeFn func()) {
-		if e.config.IsModuleDisabled(name) {
-			return // Skip disabled modules
+	// Helper to start a scrape function concurrently within the module's deadline
+	start := func(name string, scrapeFn func(ctx context.Context) error) {
+		if !e.allowModule(name) {
+			return // Skip suspended modules
 		}
 		wg.Add(1)
 		go func() {
@@ -45,32 +141,63 @@ func (e *Exporter) scrapeADC(ch chan<- prometheus.Metric) {
 			select {
 			case sem <- struct{}{}: // Acquire token
 				defer func() { <-sem }() // Release token
-				scrapeFn()
+				mctx, cancel := e.moduleContext(ctx, name)
+				defer cancel()
+				start := time.Now()
+				err := scrapeFn(mctx)
+				e.recordModule(name, err)
+				e.observeModule(ch, status, name, time.Since(start), err)
 			case <-ctx.Done():
 				e.logger.Warn("context cancelled, skipping scrape", "url", e.url, "name", name)
+				e.recordModule(name, ctx.Err())
+				e.observeModule(ch, status, name, 0, ctx.Err())
 			}
 		}()
 	}
 
+	// Helper to run a module unless it is skipped or, with a call budget, held
+	// back as low priority
+	run := func(name string, scrapeFn func(ctx context.Context) error) {
+		if skip(name) {
+			return // Skip disabled and unsupported modules
+		}
+		if budget != nil && lowPriorityModules[name] {
+			lowPriority = append(lowPriority, module{name, scrapeFn})
+			return
+		}
+		start(name, scrapeFn)
+	}
+
 	// Build base label values
 	baseLabels := e.buildLabelValues()
 
-	// Collect topology metrics FIRST (synchronously) to populate chainMembership
+	// Collect topology metrics FIRST (synchronously) to build chainMembership
 	// This must complete before service_groups runs so it can use chain labels
-	if !e.config.IsModuleDisabled("topology") {
-		e.collectTopologyMetrics(ctx, nsClient, ch)
+	var chainMembership map[string]string
+	if !skip("topology") && e.allowModule("topology") {
+		// Hold a token like any module, so that its paged collections fetch
+		// further pages with the remaining ones only
+		sem <- struct{}{}
+		mctx, cancel := e.moduleContext(ctx, "topology")
+		start := time.Now()
+		var err error
+		chainMembership, err = e.collectTopologyMetrics(mctx, nsClient, set)
+		e.recordModule("topology", err)
+		e.observeModule(ch, status, "topology", time.Since(start), err)
+		cancel()
+		<-sem
 	}
 
 	// 1. NS Stats
-	run("ns_stats", func() {
+	run("ns_stats", func(ctx context.Context) error {
 		ns, err := netscaler.GetNSStats(ctx, nsClient, "")
 		if err != nil {
 			e.logger.Error("failed to get NS stats", "url", e.url, "err", err)
-			return
+			return err
 		}
 
-		fltTotRxMB, _ := strconv.ParseFloat(ns.NSStats.TotalReceivedMB, 64)
-		fltTotTxMB, _ := strconv.ParseFloat(ns.NSStats.TotalTransmitMB, 64)
+		fltTotRxMbits, _ := strconv.ParseFloat(ns.NSStats.TotalReceivedMB, 64)
+		fltTotTxMbits, _ := strconv.ParseFloat(ns.NSStats.TotalTransmitMB, 64)
 		fltHTTPRequests, _ := strconv.ParseFloat(ns.NSStats.HTTPRequests, 64)
 		fltHTTPResponses, _ := strconv.ParseFloat(ns.NSStats.HTTPResponses, 64)
 		fltTCPCurrentClientConnections, _ := strconv.ParseFloat(ns.NSStats.TCPCurrentClientConnections, 64)
@@ -83,291 +210,202 @@ func (e *Exporter) scrapeADC(ch chan<- prometheus.Metric) {
 		ch <- prometheus.MustNewConstMetric(e.pktCPUUsage, prometheus.GaugeValue, ns.NSStats.PktCPUUsagePcnt, baseLabels...)
 		ch <- prometheus.MustNewConstMetric(e.flashPartitionUsage, prometheus.GaugeValue, ns.NSStats.FlashPartitionUsage, baseLabels...)
 		ch <- prometheus.MustNewConstMetric(e.varPartitionUsage, prometheus.GaugeValue, ns.NSStats.VarPartitionUsage, baseLabels...)
-		ch <- prometheus.MustNewConstMetric(e.totRxMB, prometheus.GaugeValue, fltTotRxMB, baseLabels...)
-		ch <- prometheus.MustNewConstMetric(e.totTxMB, prometheus.GaugeValue, fltTotTxMB, baseLabels...)
-		ch <- prometheus.MustNewConstMetric(e.httpRequests, prometheus.GaugeValue, fltHTTPRequests, baseLabels...)
-		ch <- prometheus.MustNewConstMetric(e.httpResponses, prometheus.GaugeValue, fltHTTPResponses, baseLabels...)
+		e.totRxBytes.collect(ch, fltTotRxMbits*bytesPerMegabit, baseLabels...)
+		e.totTxBytes.collect(ch, fltTotTxMbits*bytesPerMegabit, baseLabels...)
+		e.httpRequests.collect(ch, fltHTTPRequests, baseLabels...)
+		e.httpResponses.collect(ch, fltHTTPResponses, baseLabels...)
 		ch <- prometheus.MustNewConstMetric(e.tcpCurrentClientConnections, prometheus.GaugeValue, fltTCPCurrentClientConnections, baseLabels...)
 		ch <- prometheus.MustNewConstMetric(e.tcpCurrentClientConnectionsEstablished, prometheus.GaugeValue, fltTCPCurrentClientConnectionsEstablished, baseLabels...)
 		ch <- prometheus.MustNewConstMetric(e.tcpCurrentServerConnections, prometheus.GaugeValue, fltTCPCurrentServerConnections, baseLabels...)
 		ch <- prometheus.MustNewConstMetric(e.tcpCurrentServerConnectionsEstablished, prometheus.GaugeValue, fltTCPCurrentServerConnectionsEstablished, baseLabels...)
+		return nil
 	})
 
 	// 2. NS License
-	run("ns_license", func() {
+	run("ns_license", func(ctx context.Context) error {
 		nslicense, err := netscaler.GetNSLicense(ctx, nsClient, "")
 		if err != nil {
 			e.logger.Error("failed to get NS license", "url", e.url, "err", err)
-			return
+			return err
 		}
 		fltModelID, _ := strconv.ParseFloat(nslicense.NSLicense.ModelID, 64)
 		ch <- prometheus.MustNewConstMetric(e.modelID, prometheus.GaugeValue, fltModelID, baseLabels...)
+		return nil
 	})
 
 	// 3. Interfaces
-	run("interfaces", func() {
+	run("interfaces", func(ctx context.Context) error {
 		interfaces, err := netscaler.GetInterfaceStats(ctx, nsClient, "")
 		if err != nil {
 			e.logger.Error("failed to get interface stats", "url", e.url, "err", err)
-			return
+			return err
 		}
-		e.collectInterfacesRxBytes(interfaces)
-		e.interfacesRxBytes.Collect(ch)
-		e.collectInterfacesTxBytes(interfaces)
-		e.interfacesTxBytes.Collect(ch)
-		e.collectInterfacesRxPackets(interfaces)
-		e.interfacesRxPackets.Collect(ch)
-		e.collectInterfacesTxPackets(interfaces)
-		e.interfacesTxPackets.Collect(ch)
-		e.collectInterfacesJumboPacketsRx(interfaces)
-		e.interfacesJumboPacketsRx.Collect(ch)
-		e.collectInterfacesJumboPacketsTx(interfaces)
-		e.interfacesJumboPacketsTx.Collect(ch)
-		e.collectInterfacesErrorPacketsRx(interfaces)
-		e.interfacesErrorPacketsRx.Collect(ch)
+		e.collectInterfacesRxBytes(set, interfaces)
+		e.collectInterfacesTxBytes(set, interfaces)
+		e.collectInterfacesRxPackets(set, interfaces)
+		e.collectInterfacesTxPackets(set, interfaces)
+		e.collectInterfacesJumboPacketsRx(set, interfaces)
+		e.collectInterfacesJumboPacketsTx(set, interfaces)
+		e.collectInterfacesErrorPacketsRx(set, interfaces)
+		return nil
 	})
 
 	// 4. Virtual Servers
-	run("virtual_servers", func() {
+	run("virtual_servers", func(ctx context.Context) error {
 		virtualServers, err := netscaler.GetVirtualServerStats(ctx, nsClient, "")
 		if err != nil {
 			e.logger.Error("failed to get virtual server stats", "url", e.url, "err", err)
-			return
+			return err
 		}
-		e.collectVirtualServerState(virtualServers)
-		e.virtualServersState.Collect(ch)
-		e.collectVirtualServerWaitingRequests(virtualServers)
-		e.virtualServersWaitingRequests.Collect(ch)
-		e.collectVirtualServerHealth(virtualServers)
-		e.virtualServersHealth.Collect(ch)
-		e.collectVirtualServerInactiveServices(virtualServers)
-		e.virtualServersInactiveServices.Collect(ch)
-		e.collectVirtualServerActiveServices(virtualServers)
-		e.virtualServersActiveServices.Collect(ch)
-		e.collectVirtualServerTotalHits(virtualServers)
-		e.virtualServersTotalHits.Collect(ch)
-		e.collectVirtualServerTotalRequests(virtualServers)
-		e.virtualServersTotalRequests.Collect(ch)
-		e.collectVirtualServerTotalResponses(virtualServers)
-		e.virtualServersTotalResponses.Collect(ch)
-		e.collectVirtualServerTotalRequestBytes(virtualServers)
-		e.virtualServersTotalRequestBytes.Collect(ch)
-		e.collectVirtualServerTotalResponseBytes(virtualServers)
-		e.virtualServersTotalResponseBytes.Collect(ch)
-		e.collectVirtualServerCurrentClientConnections(virtualServers)
-		e.virtualServersCurrentClientConnections.Collect(ch)
-		e.collectVirtualServerCurrentServerConnections(virtualServers)
-		e.virtualServersCurrentServerConnections.Collect(ch)
+		e.collectVirtualServerState(set, virtualServers)
+		e.collectVirtualServerWaitingRequests(set, virtualServers)
+		e.collectVirtualServerHealth(set, virtualServers)
+		e.collectVirtualServerInactiveServices(set, virtualServers)
+		e.collectVirtualServerActiveServices(set, virtualServers)
+		e.collectVirtualServerTotalHits(set, virtualServers)
+		e.collectVirtualServerTotalRequests(set, virtualServers)
+		e.collectVirtualServerTotalResponses(set, virtualServers)
+		e.collectVirtualServerTotalRequestBytes(set, virtualServers)
+		e.collectVirtualServerTotalResponseBytes(set, virtualServers)
+		e.collectVirtualServerCurrentClientConnections(set, virtualServers)
+		e.collectVirtualServerCurrentServerConnections(set, virtualServers)
+		return nil
 	})
 
 	// 5. Services
-	run("services", func() {
+	run("services", func(ctx context.Context) error {
 		services, err := netscaler.GetServiceStats(ctx, nsClient, "")
 		if err != nil {
 			e.logger.Error("failed to get service stats", "url", e.url, "err", err)
-			return
+			return err
 		}
-		e.collectServicesThroughput(services)
-		e.servicesThroughput.Collect(ch)
-		e.collectServicesAvgTTFB(services)
-		e.servicesAvgTTFB.Collect(ch)
-		e.collectServicesState(services)
-		e.servicesState.Collect(ch)
-		e.collectServicesTotalRequests(services)
-		e.servicesTotalRequests.Collect(ch)
-		e.collectServicesTotalResponses(services)
-		e.servicesTotalResponses.Collect(ch)
-		e.collectServicesTotalRequestBytes(services)
-		e.servicesTotalRequestBytes.Collect(ch)
-		e.collectServicesTotalResponseBytes(services)
-		e.servicesTotalResponseBytes.Collect(ch)
-		e.collectServicesCurrentClientConns(services)
-		e.servicesCurrentClientConns.Collect(ch)
-		e.collectServicesSurgeCount(services)
-		e.servicesSurgeCount.Collect(ch)
-		e.collectServicesCurrentServerConns(services)
-		e.servicesCurrentServerConns.Collect(ch)
-		e.collectServicesServerEstablishedConnections(services)
-		e.servicesServerEstablishedConnections.Collect(ch)
-		e.collectServicesCurrentReusePool(services)
-		e.servicesCurrentReusePool.Collect(ch)
-		e.collectServicesMaxClients(services)
-		e.servicesMaxClients.Collect(ch)
-		e.collectServicesCurrentLoad(services)
-		e.servicesCurrentLoad.Collect(ch)
-		e.collectServicesVirtualServerServiceHits(services)
-		e.servicesVirtualServerServiceHits.Collect(ch)
-		e.collectServicesActiveTransactions(services)
-		e.servicesActiveTransactions.Collect(ch)
+		e.collectServicesThroughput(set, services)
+		e.collectServicesAvgTTFB(set, services)
+		e.collectServicesState(set, services)
+		e.collectServicesTotalRequests(set, services)
+		e.collectServicesTotalResponses(set, services)
+		e.collectServicesTotalRequestBytes(set, services)
+		e.collectServicesTotalResponseBytes(set, services)
+		e.collectServicesCurrentClientConns(set, services)
+		e.collectServicesSurgeCount(set, services)
+		e.collectServicesCurrentServerConns(set, services)
+		e.collectServicesServerEstablishedConnections(set, services)
+		e.collectServicesCurrentReusePool(set, services)
+		e.collectServicesMaxClients(set, services)
+		e.collectServicesCurrentLoad(set, services)
+		e.collectServicesVirtualServerServiceHits(set, services)
+		e.collectServicesActiveTransactions(set, services)
+		return nil
 	})
 
 	// 6. GSLB Services
-	run("gslb_services", func() {
+	run("gslb_services", func(ctx context.Context) error {
 		gslbServices, err := netscaler.GetGSLBServiceStats(ctx, nsClient, "")
 		if err != nil {
 			e.logger.Error("failed to get GSLB service stats", "url", e.url, "err", err)
-			return
+			return err
 		}
-		e.collectGSLBServicesState(gslbServices)
-		e.gslbServicesState.Collect(ch)
-		e.collectGSLBServicesTotalRequests(gslbServices)
-		e.gslbServicesTotalRequests.Collect(ch)
-		e.collectGSLBServicesTotalResponses(gslbServices)
-		e.gslbServicesTotalResponses.Collect(ch)
-		e.collectGSLBServicesTotalRequestBytes(gslbServices)
-		e.gslbServicesTotalRequestBytes.Collect(ch)
-		e.collectGSLBServicesTotalResponseBytes(gslbServices)
-		e.gslbServicesTotalResponseBytes.Collect(ch)
-		e.collectGSLBServicesCurrentClientConns(gslbServices)
-		e.gslbServicesCurrentClientConns.Collect(ch)
-		e.collectGSLBServicesCurrentServerConns(gslbServices)
-		e.gslbServicesCurrentServerConns.Collect(ch)
-		e.collectGSLBServicesEstablishedConnections(gslbServices)
-		e.gslbServicesEstablishedConnections.Collect(ch)
-		e.collectGSLBServicesCurrentLoad(gslbServices)
-		e.gslbServicesCurrentLoad.Collect(ch)
-		e.collectGSLBServicesVirtualServerServiceHits(gslbServices)
-		e.gslbServicesVirtualServerServiceHits.Collect(ch)
+		e.collectGSLBServicesState(set, gslbServices)
+		e.collectGSLBServicesTotalRequests(set, gslbServices)
+		e.collectGSLBServicesTotalResponses(set, gslbServices)
+		e.collectGSLBServicesTotalRequestBytes(set, gslbServices)
+		e.collectGSLBServicesTotalResponseBytes(set, gslbServices)
+		e.collectGSLBServicesCurrentClientConns(set, gslbServices)
+		e.collectGSLBServicesCurrentServerConns(set, gslbServices)
+		e.collectGSLBServicesEstablishedConnections(set, gslbServices)
+		e.collectGSLBServicesCurrentLoad(set, gslbServices)
+		e.collectGSLBServicesVirtualServerServiceHits(set, gslbServices)
+		return nil
 	})
 
 	// 7. GSLB Virtual Servers
-	run("gslb_vservers", func() {
+	run("gslb_vservers", func(ctx context.Context) error {
 		gslbVirtualServers, err := netscaler.GetGSLBVirtualServerStats(ctx, nsClient, "")
 		if err != nil {
 			e.logger.Error("failed to get GSLB virtual server stats", "url", e.url, "err", err)
-			return
+			return err
 		}
-		e.collectGSLBVirtualServerState(gslbVirtualServers)
-		e.gslbVirtualServersState.Collect(ch)
-		e.collectGSLBVirtualServerHealth(gslbVirtualServers)
-		e.gslbVirtualServersHealth.Collect(ch)
-		e.collectGSLBVirtualServerInactiveServices(gslbVirtualServers)
-		e.gslbVirtualServersInactiveServices.Collect(ch)
-		e.collectGSLBVirtualServerActiveServices(gslbVirtualServers)
-		e.gslbVirtualServersActiveServices.Collect(ch)
-		e.collectGSLBVirtualServerTotalHits(gslbVirtualServers)
-		e.gslbVirtualServersTotalHits.Collect(ch)
-		e.collectGSLBVirtualServerTotalRequests(gslbVirtualServers)
-		e.gslbVirtualServersTotalRequests.Collect(ch)
-		e.collectGSLBVirtualServerTotalResponses(gslbVirtualServers)
-		e.gslbVirtualServersTotalResponses.Collect(ch)
-		e.collectGSLBVirtualServerTotalRequestBytes(gslbVirtualServers)
-		e.gslbVirtualServersTotalRequestBytes.Collect(ch)
-		e.collectGSLBVirtualServerTotalResponseBytes(gslbVirtualServers)
-		e.gslbVirtualServersTotalResponseBytes.Collect(ch)
-		e.collectGSLBVirtualServerCurrentClientConnections(gslbVirtualServers)
-		e.gslbVirtualServersCurrentClientConnections.Collect(ch)
-		e.collectGSLBVirtualServerCurrentServerConnections(gslbVirtualServers)
-		e.gslbVirtualServersCurrentServerConnections.Collect(ch)
+		e.collectGSLBVirtualServerState(set, gslbVirtualServers)
+		e.collectGSLBVirtualServerHealth(set, gslbVirtualServers)
+		e.collectGSLBVirtualServerInactiveServices(set, gslbVirtualServers)
+		e.collectGSLBVirtualServerActiveServices(set, gslbVirtualServers)
+		e.collectGSLBVirtualServerTotalHits(set, gslbVirtualServers)
+		e.collectGSLBVirtualServerTotalRequests(set, gslbVirtualServers)
+		e.collectGSLBVirtualServerTotalResponses(set, gslbVirtualServers)
+		e.collectGSLBVirtualServerTotalRequestBytes(set, gslbVirtualServers)
+		e.collectGSLBVirtualServerTotalResponseBytes(set, gslbVirtualServers)
+		e.collectGSLBVirtualServerCurrentClientConnections(set, gslbVirtualServers)
+		e.collectGSLBVirtualServerCurrentServerConnections(set, gslbVirtualServers)
+		return nil
 	})
 
 	// 8. CS Virtual Servers
-	run("cs_vservers", func() {
+	run("cs_vservers", func(ctx context.Context) error {
 		csVirtualServers, err := netscaler.GetCSVirtualServerStats(ctx, nsClient, "")
 		if err != nil {
 			e.logger.Error("failed to get CS virtual server stats", "url", e.url, "err", err)
-			return
+			return err
 		}
-		e.collectCSVirtualServerState(csVirtualServers)
-		e.csVirtualServersState.Collect(ch)
-		e.collectCSVirtualServerTotalHits(csVirtualServers)
-		e.csVirtualServersTotalHits.Collect(ch)
-		e.collectCSVirtualServerTotalRequests(csVirtualServers)
-		e.csVirtualServersTotalRequests.Collect(ch)
-		e.collectCSVirtualServerTotalResponses(csVirtualServers)
-		e.csVirtualServersTotalResponses.Collect(ch)
-		e.collectCSVirtualServerTotalRequestBytes(csVirtualServers)
-		e.csVirtualServersTotalRequestBytes.Collect(ch)
-		e.collectCSVirtualServerTotalResponseBytes(csVirtualServers)
-		e.csVirtualServersTotalResponseBytes.Collect(ch)
-		e.collectCSVirtualServerCurrentClientConnections(csVirtualServers)
-		e.csVirtualServersCurrentClientConnections.Collect(ch)
-		e.collectCSVirtualServerCurrentServerConnections(csVirtualServers)
-		e.csVirtualServersCurrentServerConnections.Collect(ch)
-		e.collectCSVirtualServerEstablishedConnections(csVirtualServers)
-		e.csVirtualServersEstablishedConnections.Collect(ch)
-		e.collectCSVirtualServerTotalPacketsReceived(csVirtualServers)
-		e.csVirtualServersTotalPacketsReceived.Collect(ch)
-		e.collectCSVirtualServerTotalPacketsSent(csVirtualServers)
-		e.csVirtualServersTotalPacketsSent.Collect(ch)
-		e.collectCSVirtualServerTotalSpillovers(csVirtualServers)
-		e.csVirtualServersTotalSpillovers.Collect(ch)
-		e.collectCSVirtualServerDeferredRequests(csVirtualServers)
-		e.csVirtualServersDeferredRequests.Collect(ch)
-		e.collectCSVirtualServerNumberInvalidRequestResponse(csVirtualServers)
-		e.csVirtualServersNumberInvalidRequestResponse.Collect(ch)
-		e.collectCSVirtualServerNumberInvalidRequestResponseDropped(csVirtualServers)
-		e.csVirtualServersNumberInvalidRequestResponseDropped.Collect(ch)
-		e.collectCSVirtualServerTotalVServerDownBackupHits(csVirtualServers)
-		e.csVirtualServersTotalVServerDownBackupHits.Collect(ch)
-		e.collectCSVirtualServerCurrentMultipathSessions(csVirtualServers)
-		e.csVirtualServersCurrentMultipathSessions.Collect(ch)
-		e.collectCSVirtualServerCurrentMultipathSubflows(csVirtualServers)
-		e.csVirtualServersCurrentMultipathSubflows.Collect(ch)
+		e.collectCSVirtualServerState(set, csVirtualServers)
+		e.collectCSVirtualServerTotalHits(set, csVirtualServers)
+		e.collectCSVirtualServerTotalRequests(set, csVirtualServers)
+		e.collectCSVirtualServerTotalResponses(set, csVirtualServers)
+		e.collectCSVirtualServerTotalRequestBytes(set, csVirtualServers)
+		e.collectCSVirtualServerTotalResponseBytes(set, csVirtualServers)
+		e.collectCSVirtualServerCurrentClientConnections(set, csVirtualServers)
+		e.collectCSVirtualServerCurrentServerConnections(set, csVirtualServers)
+		e.collectCSVirtualServerEstablishedConnections(set, csVirtualServers)
+		e.collectCSVirtualServerTotalPacketsReceived(set, csVirtualServers)
+		e.collectCSVirtualServerTotalPacketsSent(set, csVirtualServers)
+		e.collectCSVirtualServerTotalSpillovers(set, csVirtualServers)
+		e.collectCSVirtualServerDeferredRequests(set, csVirtualServers)
+		e.collectCSVirtualServerNumberInvalidRequestResponse(set, csVirtualServers)
+		e.collectCSVirtualServerNumberInvalidRequestResponseDropped(set, csVirtualServers)
+		e.collectCSVirtualServerTotalVServerDownBackupHits(set, csVirtualServers)
+		e.collectCSVirtualServerCurrentMultipathSessions(set, csVirtualServers)
+		e.collectCSVirtualServerCurrentMultipathSubflows(set, csVirtualServers)
+		return nil
 	})
 
 	// 9. VPN Virtual Servers
-	run("vpn_vservers", func() {
+	run("vpn_vservers", func(ctx context.Context) error {
 		vpnVirtualServers, err := netscaler.GetVPNVirtualServerStats(ctx, nsClient, "")
 		if err != nil {
 			e.logger.Error("failed to get VPN virtual server stats", "url", e.url, "err", err)
-			return
+			return err
 		}
-		e.collectVPNVirtualServerTotalRequests(vpnVirtualServers)
-		e.vpnVirtualServersTotalRequests.Collect(ch)
-		e.collectVPNVirtualServerTotalResponses(vpnVirtualServers)
-		e.vpnVirtualServersTotalResponses.Collect(ch)
-		e.collectVPNVirtualServerTotalRequestBytes(vpnVirtualServers)
-		e.vpnVirtualServersTotalRequestBytes.Collect(ch)
-		e.collectVPNVirtualServerTotalResponseBytes(vpnVirtualServers)
-		e.vpnVirtualServersTotalResponseBytes.Collect(ch)
-		e.collectVPNVirtualServerState(vpnVirtualServers)
-		e.vpnVirtualServersState.Collect(ch)
+		e.collectVPNVirtualServerTotalRequests(set, vpnVirtualServers)
+		e.collectVPNVirtualServerTotalResponses(set, vpnVirtualServers)
+		e.collectVPNVirtualServerTotalRequestBytes(set, vpnVirtualServers)
+		e.collectVPNVirtualServerTotalResponseBytes(set, vpnVirtualServers)
+		e.collectVPNVirtualServerState(set, vpnVirtualServers)
+		return nil
 	})
 
 	// 10. AAA Stats
-	run("aaa_stats", func() {
+	run("aaa_stats", func(ctx context.Context) error {
 		aaa, err := netscaler.GetAAAStats(ctx, nsClient, "")
 		if err != nil {
 			e.logger.Error("failed to get AAA stats", "url", e.url, "err", err)
-			return
+			return err
 		}
-		e.collectAaaAuthSuccess(aaa)
-		e.aaaAuthSuccess.Collect(ch)
-		e.collectAaaAuthFail(aaa)
-		e.aaaAuthFail.Collect(ch)
-		e.collectAaaAuthOnlyHTTPSuccess(aaa)
-		e.aaaAuthOnlyHTTPSuccess.Collect(ch)
-		e.collectAaaAuthOnlyHTTPFail(aaa)
-		e.aaaAuthOnlyHTTPFail.Collect(ch)
-		e.collectAaaCurIcaSessions(aaa)
-		e.aaaCurIcaSessions.Collect(ch)
-		e.collectAaaCurIcaOnlyConn(aaa)
-		e.aaaCurIcaOnlyConn.Collect(ch)
+		e.collectAaaAuthSuccess(set, aaa)
+		e.collectAaaAuthFail(set, aaa)
+		e.collectAaaAuthOnlyHTTPSuccess(set, aaa)
+		e.collectAaaAuthOnlyHTTPFail(set, aaa)
+		e.collectAaaCurIcaSessions(set, aaa)
+		e.collectAaaCurIcaOnlyConn(set, aaa)
+		return nil
 	})
 
 	// 11. Service Groups (Nested parallelization)
-	run("service_groups", func() {
+	run("service_groups", func(ctx context.Context) error {
 		servicegroups, err := netscaler.GetServiceGroups(ctx, nsClient, "attrs=servicegroupname")
 		if err != nil {
 			e.logger.Error("failed to get service groups", "url", e.url, "err", err)
-			return
+			return err
 		}
 
-		// Reset all servicegroup metrics once before processing
-		e.serviceGroupsState.Reset()
-		e.serviceGroupsAvgTTFB.Reset()
-		e.serviceGroupsTotalRequests.Reset()
-		e.serviceGroupsTotalResponses.Reset()
-		e.serviceGroupsTotalRequestBytes.Reset()
-		e.serviceGroupsTotalResponseBytes.Reset()
-		e.serviceGroupsCurrentClientConnections.Reset()
-		e.serviceGroupsSurgeCount.Reset()
-		e.serviceGroupsCurrentServerConnections.Reset()
-		e.serviceGroupsServerEstablishedConnections.Reset()
-		e.serviceGroupsCurrentReusePool.Reset()
-		e.serviceGroupsMaxClients.Reset()
-
 		// Use a separate WaitGroup for service group goroutines
 		var sgWg sync.WaitGroup
 
@@ -375,9 +413,19 @@ func (e *Exporter) scrapeADC(ch chan<- prometheus.Metric) {
 		var seenMu sync.Mutex
 		seenMembers := make(map[string]bool)
 
+		// First member stats error, reported as the module error
+		var memberErrOnce sync.Once
+		var memberErr error
+
+		// Set once the call budget is spent; the remaining groups are skipped
+		var budgetSpent atomic.Bool
+
 		// Deduplicate service groups (API may return duplicates)
 		seenServiceGroups := make(map[string]bool)
 		for _, sg := range servicegroups.ServiceGroups {
+			if budgetSpent.Load() {
+				break
+			}
 			sgName := sg.Name // Capture for closure
 			if seenServiceGroups[sgName] {
 				continue
@@ -390,19 +438,31 @@ func (e *Exporter) scrapeADC(ch chan<- prometheus.Metric) {
 				case sem <- struct{}{}:
 					defer func() { <-sem }()
 				case <-ctx.Done():
+					memberErrOnce.Do(func() { memberErr = ctx.Err() })
+					return
+				}
+				if budgetSpent.Load() {
 					return
 				}
 
 				// Lookup chain membership for topology (node created after stats aggregation)
 				var sgChain string
 				sgNodeID := "servicegroup:" + sgName
-				if !e.config.IsModuleDisabled("topology") {
-					sgChain = e.chainMembership[sgNodeID]
+				if !skip("topology") {
+					sgChain = chainMembership[sgNodeID]
 				}
 
 				stats, err2 := netscaler.GetServiceGroupMemberStats(ctx, nsClient, sgName)
+				if errors.Is(err2, netscaler.ErrCallBudgetExhausted) {
+					if budgetSpent.CompareAndSwap(false, true) {
+						e.logger.Debug("call budget spent, skipping remaining service groups", "url", e.url)
+						memberErrOnce.Do(func() { memberErr = err2 })
+					}
+					return
+				}
 				if err2 != nil {
 					e.logger.Error("failed to get service group member stats", "service_group", sgName, "url", e.url, "err", err2)
+					memberErrOnce.Do(func() { memberErr = err2 })
 					return
 				}
 
@@ -433,7 +493,7 @@ func (e *Exporter) scrapeADC(ch chan<- prometheus.Metric) {
 					seenMembers[key] = true
 					seenMu.Unlock()
 
-					// Set metric values (no Reset, no Collect - done once after all goroutines)
+					// Set metric values (the scrape's set is flushed once after all modules)
 					port := strconv.Itoa(s.PrimaryPort)
 					labels := e.buildLabelValues(sgName, memberName, port)
 
@@ -442,7 +502,7 @@ func (e *Exporter) scrapeADC(ch chan<- prometheus.Metric) {
 						state = 1.0
 						sgUpCount++
 					}
-					e.serviceGroupsState.WithLabelValues(labels...).Set(state)
+					set.gauge(e.serviceGroupsState, state, labels...)
 
 					// Aggregate stats for servicegroup topology node
 					sgMemberCount++
@@ -454,41 +514,41 @@ func (e *Exporter) scrapeADC(ch chan<- prometheus.Metric) {
 					}
 
 					if val, err := strconv.ParseFloat(s.AvgTimeToFirstByte, 64); err == nil {
-						e.serviceGroupsAvgTTFB.WithLabelValues(labels...).Set(val)
+						set.gauge(e.serviceGroupsAvgTTFB, val, labels...)
 					}
 					if val, err := strconv.ParseFloat(s.TotalRequests, 64); err == nil {
-						e.serviceGroupsTotalRequests.WithLabelValues(labels...).Set(val)
+						set.counter(e.serviceGroupsTotalRequests, val, labels...)
 					}
 					if val, err := strconv.ParseFloat(s.TotalResponses, 64); err == nil {
-						e.serviceGroupsTotalResponses.WithLabelValues(labels...).Set(val)
+						set.counter(e.serviceGroupsTotalResponses, val, labels...)
 					}
 					if val, err := strconv.ParseFloat(s.TotalRequestBytes, 64); err == nil {
-						e.serviceGroupsTotalRequestBytes.WithLabelValues(labels...).Set(val)
+						set.counter(e.serviceGroupsTotalRequestBytes, val, labels...)
 					}
 					if val, err := strconv.ParseFloat(s.TotalResponseBytes, 64); err == nil {
-						e.serviceGroupsTotalResponseBytes.WithLabelValues(labels...).Set(val)
+						set.counter(e.serviceGroupsTotalResponseBytes, val, labels...)
 					}
 					if val, err := strconv.ParseFloat(s.CurrentClientConnections, 64); err == nil {
-						e.serviceGroupsCurrentClientConnections.WithLabelValues(labels...).Set(val)
+						set.gauge(e.serviceGroupsCurrentClientConnections, val, labels...)
 					}
 					if val, err := strconv.ParseFloat(s.SurgeCount, 64); err == nil {
-						e.serviceGroupsSurgeCount.WithLabelValues(labels...).Set(val)
+						set.gauge(e.serviceGroupsSurgeCount, val, labels...)
 					}
 					if val, err := strconv.ParseFloat(s.CurrentServerConnections, 64); err == nil {
-						e.serviceGroupsCurrentServerConnections.WithLabelValues(labels...).Set(val)
+						set.gauge(e.serviceGroupsCurrentServerConnections, val, labels...)
 					}
 					if val, err := strconv.ParseFloat(s.ServerEstablishedConnections, 64); err == nil {
-						e.serviceGroupsServerEstablishedConnections.WithLabelValues(labels...).Set(val)
+						set.gauge(e.serviceGroupsServerEstablishedConnections, val, labels...)
 					}
 					if val, err := strconv.ParseFloat(s.CurrentReusePool, 64); err == nil {
-						e.serviceGroupsCurrentReusePool.WithLabelValues(labels...).Set(val)
+						set.gauge(e.serviceGroupsCurrentReusePool, val, labels...)
 					}
 					if val, err := strconv.ParseFloat(s.MaxClients, 64); err == nil {
-						e.serviceGroupsMaxClients.WithLabelValues(labels...).Set(val)
+						set.gauge(e.serviceGroupsMaxClients, val, labels...)
 					}
 
 					// Create topology server node and edge (reusing already-fetched data)
-					if !e.config.IsModuleDisabled("topology") {
+					if !skip("topology") {
 						serverID := fmt.Sprintf("server:%s:%d", s.PrimaryIPAddress, s.PrimaryPort)
 						// Use server name (memberName) for title if available, otherwise fall back to IP
 						serverTitle := fmt.Sprintf("%s:%d", memberName, s.PrimaryPort)
@@ -507,31 +567,31 @@ func (e *Exporter) scrapeADC(ch chan<- prometheus.Metric) {
 						nodeLabels := e.buildLabelValues(serverID, serverTitle, subtitle, "server", topoState, sgChain,
 							s.AvgTimeToFirstByte, s.CurrentServerConnections, color,
 							"", s.CurrentServerConnections, s.TotalRequests, s.AvgTimeToFirstByte)
-						e.topologyNode.WithLabelValues(nodeLabels...).Set(value)
+						set.gauge(e.topologyNode, value, nodeLabels...)
 
 						edgeID := fmt.Sprintf("servicegroup:%s->server:%s:%d", sgName, s.PrimaryIPAddress, s.PrimaryPort)
 						sourceID := "servicegroup:" + sgName
 						edgeLabels := e.buildLabelValues(edgeID, sourceID, serverID, "1", "", sgChain, "", "")
-						e.topologyEdge.WithLabelValues(edgeLabels...).Set(1)
+						set.gauge(e.topologyEdge, 1, edgeLabels...)
 
 						// Emit topology node stats for server
 						serverStatsLabels := e.buildLabelValues(serverID, "server", sgChain)
-						e.topologyNodeState.WithLabelValues(serverStatsLabels...).Set(value)
+						set.gauge(e.topologyNodeState, value, serverStatsLabels...)
 
 						if requests, err := strconv.ParseFloat(s.TotalRequests, 64); err == nil {
-							e.topologyNodeRequestsTotal.WithLabelValues(serverStatsLabels...).Set(requests)
+							set.gauge(e.topologyNodeRequestsTotal, requests, serverStatsLabels...)
 						}
 						if conns, err := strconv.ParseFloat(s.CurrentServerConnections, 64); err == nil {
-							e.topologyNodeConnections.WithLabelValues(serverStatsLabels...).Set(conns)
+							set.gauge(e.topologyNodeConnections, conns, serverStatsLabels...)
 						}
 						if ttfb, err := strconv.ParseFloat(s.AvgTimeToFirstByte, 64); err == nil {
-							e.topologyNodeTTFBMs.WithLabelValues(serverStatsLabels...).Set(ttfb)
+							set.gauge(e.topologyNodeTTFBMs, ttfb, serverStatsLabels...)
 						}
 					}
 				}
 
 				// Emit servicegroup topology node and stats (aggregated from members)
-				if !e.config.IsModuleDisabled("topology") && sgMemberCount > 0 {
+				if !skip("topology") && sgMemberCount > 0 {
 					// State: 1 if all members are UP, 0 otherwise
 					sgState := 0.0
 					sgStateStr := "DOWN"
@@ -557,14 +617,14 @@ func (e *Exporter) scrapeADC(ch chan<- prometheus.Metric) {
 					nodeLabels := e.buildLabelValues(sgNodeID, sgName, subtitle, "servicegroup", sgStateStr, sgChain,
 						membersStr, avgTTFBStr, color,
 						"", "", requestsStr, avgTTFBStr)
-					e.topologyNode.WithLabelValues(nodeLabels...).Set(sgState)
+					set.gauge(e.topologyNode, sgState, nodeLabels...)
 
 					// Also emit separate stats metrics
 					sgStatsLabels := e.buildLabelValues(sgNodeID, "servicegroup", sgChain)
-					e.topologyNodeState.WithLabelValues(sgStatsLabels...).Set(sgState)
-					e.topologyNodeRequestsTotal.WithLabelValues(sgStatsLabels...).Set(sgTotalRequests)
+					set.gauge(e.topologyNodeState, sgState, sgStatsLabels...)
+					set.gauge(e.topologyNodeRequestsTotal, sgTotalRequests, sgStatsLabels...)
 					if sgMemberCount > 0 {
-						e.topologyNodeTTFBMs.WithLabelValues(sgStatsLabels...).Set(sgTotalTTFB / float64(sgMemberCount))
+						set.gauge(e.topologyNodeTTFBMs, sgTotalTTFB/float64(sgMemberCount), sgStatsLabels...)
 					}
 				}
 			}()
@@ -572,103 +632,87 @@ func (e *Exporter) scrapeADC(ch chan<- prometheus.Metric) {
 
 		// Wait for all service group goroutines to complete
 		sgWg.Wait()
-
-		// Collect all servicegroup metrics once after all data is set
-		e.serviceGroupsState.Collect(ch)
-		e.serviceGroupsAvgTTFB.Collect(ch)
-		e.serviceGroupsTotalRequests.Collect(ch)
-		e.serviceGroupsTotalResponses.Collect(ch)
-		e.serviceGroupsTotalRequestBytes.Collect(ch)
-		e.serviceGroupsTotalResponseBytes.Collect(ch)
-		e.serviceGroupsCurrentClientConnections.Collect(ch)
-		e.serviceGroupsSurgeCount.Collect(ch)
-		e.serviceGroupsCurrentServerConnections.Collect(ch)
-		e.serviceGroupsServerEstablishedConnections.Collect(ch)
-		e.serviceGroupsCurrentReusePool.Collect(ch)
-		e.serviceGroupsMaxClients.Collect(ch)
+		return memberErr
 	})
 
 	// 12. Protocol HTTP Stats
-	run("protocol_http", func() {
-		e.collectProtocolHTTPStats(ctx, nsClient, ch)
+	run("protocol_http", func(ctx context.Context) error {
+		return e.collectProtocolHTTPStats(ctx, nsClient, ch)
 	})
 
 	// 14. Protocol TCP Stats
-	run("protocol_tcp", func() {
-		e.collectProtocolTCPStats(ctx, nsClient, ch)
+	run("protocol_tcp", func(ctx context.Context) error {
+		return e.collectProtocolTCPStats(ctx, nsClient, ch)
 	})
 
 	// 15. Protocol IP Stats
-	run("protocol_ip", func() {
-		e.collectProtocolIPStats(ctx, nsClient, ch)
+	run("protocol_ip", func(ctx context.Context) error {
+		return e.collectProtocolIPStats(ctx, nsClient, ch)
 	})
 
 	// 16. SSL Stats
-	run("ssl_stats", func() {
-		e.collectSSLStats(ctx, nsClient, ch)
+	run("ssl_stats", func(ctx context.Context) error {
+		return e.collectSSLStats(ctx, nsClient, ch)
 	})
 
 	// 17. SSL Cert Keys
-	run("ssl_certs", func() {
-		e.collectSSLCertKeys(ctx, nsClient, ch)
+	run("ssl_certs", func(ctx context.Context) error {
+		return e.collectSSLCertKeys(ctx, nsClient, set)
 	})
 
 	// 18. SSL VServer Stats
-	run("ssl_vservers", func() {
-		e.collectSSLVServerStats(ctx, nsClient, ch)
+	run("ssl_vservers", func(ctx context.Context) error {
+		return e.collectSSLVServerStats(ctx, nsClient, set)
 	})
 
 	// 19. System CPU per-core Stats
-	run("system_cpu", func() {
-		e.collectSystemCPUStats(ctx, nsClient, ch)
+	run("system_cpu", func(ctx context.Context) error {
+		return e.collectSystemCPUStats(ctx, nsClient, set)
 	})
 
 	// 20. Bandwidth Capacity Stats
-	run("ns_capacity", func() {
-		e.collectNSCapacityStats(ctx, nsClient, ch)
+	run("ns_capacity", func(ctx context.Context) error {
+		return e.collectNSCapacityStats(ctx, nsClient, ch)
 	})
 
 	// 21. HA (High Availability) Stats
-	run("ha_stats", func() {
-		e.collectHAStats(ctx, nsClient, ch)
+	run("ha_stats", func(ctx context.Context) error {
+		return e.collectHAStats(ctx, nsClient, ch, set)
 	})
 
 	wg.Wait()
 
-	// Collect topology metrics after all modules have added their nodes/edges
-	if !e.config.IsModuleDisabled("topology") {
-		e.topologyNode.Collect(ch)
-		e.topologyEdge.Collect(ch)
-		e.topologyNodeState.Collect(ch)
-		e.topologyNodeHealth.Collect(ch)
-		e.topologyNodeRequestsTotal.Collect(ch)
-		e.topologyNodeConnections.Collect(ch)
-		e.topologyNodeTTFBMs.Collect(ch)
+	if budget != nil {
+		for _, m := range lowPriority {
+			if budget.Remaining() <= 0 {
+				e.logger.Debug("call budget spent, deferring module", "url", e.url, "module", m.name)
+				e.deferredCounts.inc(m.name)
+				continue
+			}
+			start(m.name, m.scrapeFn)
+		}
+		wg.Wait()
+		ch <- prometheus.MustNewConstMetric(e.callBudgetRemaining, prometheus.GaugeValue, float64(budget.Remaining()), e.buildLabelValues()...)
 	}
+	e.deferredCounts.collect(ch, e.modulesDeferred, e.buildLabelValues)
+	e.collectModuleCircuits(ch, skip)
 }
 
 // scrapeMPS scrapes the Citrix ADM (MPS) instance
-func (e *Exporter) scrapeMPS(ch chan<- prometheus.Metric) {
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
-	defer cancel()
-
+func (e *Exporter) scrapeMPS(ctx context.Context, ch chan<- prometheus.Metric, set *metricSet, status *scrapeStatus) {
 	// Use persistent client with session-based authentication
 	mpsClient := e.mpsClient
 
 	// MPS Health stats
-	mpsHealth, err := netscaler.GetMPSHealth(ctx, mpsClient)
+	start := time.Now()
+	mctx, cancel := e.moduleContext(ctx, "mps_health")
+	mpsHealth, err := netscaler.GetMPSHealth(mctx, mpsClient)
+	cancel()
+	e.observeModule(ch, status, "mps_health", time.Since(start), err)
 	if err != nil {
 		e.logger.Error("failed to get MPS health stats", "url", e.url, "err", err)
 		return
 	}
 
-	e.collectMPSHealth(mpsHealth)
-	e.mpsHealthCPUUsage.Collect(ch)
-	e.mpsHealthDiskUsage.Collect(ch)
-	e.mpsHealthDiskFree.Collect(ch)
-	e.mpsHealthDiskTotal.Collect(ch)
-	e.mpsHealthDiskUsed.Collect(ch)
-	e.mpsHealthMemoryUsage.Collect(ch)
-	e.mpsHealthMemoryFree.Collect(ch)
-	e.mpsHealthMemoryTotal.Collect(ch)
+	e.collectMPSHealth(set, mpsHealth)
 }
@@ -0,0 +1,784 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/elohmeier/netscaler-exporter/config"
+	"github.com/elohmeier/netscaler-exporter/netscaler"
+	"github.com/elohmeier/netscaler-exporter/netscaler/nitrotest"
+)
+
+// nitroFixtures maps Nitro resource paths to canned responses. The topology
+// spans csvserver cs-web -> lbvserver lb-web -> servicegroup sg-web so that
+// service group metrics depend on the chain membership of the same scrape.
+var nitroFixtures = nitrotest.Fixtures{
+	"stat/ns":                               `{"ns":{"memusagepcnt":42}}`,
+	"stat/Interface":                        `{"Interface":[{"id":"0/1","totrxbytes":"100","tottxbytes":"200"}]}`,
+	"stat/lbvserver":                        `{"lbvserver":[{"name":"lb-web","state":"UP","vslbhealth":"100","totalrequests":"10"}]}`,
+	"stat/csvserver":                        `{"csvserver":[{"name":"cs-web","state":"UP","totalrequests":"10"}]}`,
+	"stat/service":                          `{"service":[{"name":"svc-a","state":"UP"}]}`,
+	"config/servicegroup":                   `{"servicegroup":[{"servicegroupname":"sg-web"},{"servicegroupname":"sg-web"}]}`,
+	"config/lbvserver_servicegroup_binding": `{"lbvserver_servicegroup_binding":[{"name":"lb-web","servicegroupname":"sg-web"}]}`,
+	"config/csvserver_lbvserver_binding":    `{"csvserver_lbvserver_binding":[{"name":"cs-web","lbvserver":"lb-web"}]}`,
+	"stat/servicegroup/sg-web": `{"servicegroup":[{"servicegroupname":"sg-web","servicegroupmember":[
+		{"servicegroupname":"sg-web?web-1","primaryipaddress":"10.0.0.1","primaryport":80,"state":"UP","totalrequests":"5","avgsvrttfb":"3"},
+		{"servicegroupname":"sg-web?web-2","primaryipaddress":"10.0.0.2","primaryport":80,"state":"DOWN","totalrequests":"7","avgsvrttfb":"4"},
+		{"servicegroupname":"sg-web?web-2","primaryipaddress":"10.0.0.2","primaryport":80,"state":"DOWN","totalrequests":"7","avgsvrttfb":"4"}]}]}`,
+	"stat/sslcertkey": `{"sslcertkey":[{"certkey":"web","daystoexpiration":30}]}`,
+	"stat/systemcpu":  `{"systemcpu":[{"id":"1","percpuuse":"12"}]}`,
+}
+
+// newFakeNitro starts a Nitro API stand-in that serves nitroFixtures and an
+// empty result for every other resource.
+func newFakeNitro(t *testing.T) *nitrotest.Server {
+	t.Helper()
+	srv := nitrotest.NewServer(nitroFixtures)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// newTestExporter returns an ADC exporter of the target at url with cfg, or
+// with only the label env=test if cfg is nil. It is closed with the test.
+func newTestExporter(t *testing.T, url string, cfg *config.Config) *Exporter {
+	t.Helper()
+	return newTestExporterOfType(t, url, "adc", cfg)
+}
+
+// newTestExporterOfType is newTestExporter for a target of the given type.
+func newTestExporterOfType(t *testing.T, url, targetType string, cfg *config.Config) *Exporter {
+	t.Helper()
+	if cfg == nil {
+		cfg = &config.Config{Labels: map[string]string{"env": "test"}}
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	e, err := NewExporter(cfg, url, targetType, "", netscaler.StaticCredentials{Username: "user", Password: "pass"}, config.TLSConfig{}, 4, logger)
+	if err != nil {
+		t.Fatalf("NewExporter: %v", err)
+	}
+	t.Cleanup(e.Close)
+	return e
+}
+
+// TestConcurrentScrapes runs overlapping scrapes of one exporter. Each gather
+// must succeed on its own, without duplicate series or values leaking in from
+// another scrape. Run with -race to check the scrape state for data races.
+func TestConcurrentScrapes(t *testing.T) {
+	srv := newFakeNitro(t)
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(newTestExporter(t, srv.URL, nil))
+
+	const scrapes = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, scrapes)
+	counts := make(chan int, scrapes)
+	for range scrapes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			families, err := reg.Gather()
+			if err != nil {
+				errs <- err
+				return
+			}
+			n := 0
+			for _, mf := range families {
+				n += len(mf.GetMetric())
+			}
+			counts <- n
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	close(counts)
+
+	for err := range errs {
+		t.Errorf("gather failed: %v", err)
+	}
+	want := -1
+	for n := range counts {
+		if want == -1 {
+			want = n
+		} else if n != want {
+			t.Errorf("scrapes returned %d and %d series, want the same number", want, n)
+		}
+	}
+}
+
+// TestServiceGroupChainLabels checks that service group topology nodes carry
+// the chain computed by the topology module of the same scrape.
+func TestServiceGroupChainLabels(t *testing.T) {
+	srv := newFakeNitro(t)
+	e := newTestExporter(t, srv.URL, nil)
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(e)
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather failed: %v", err)
+	}
+
+	var found bool
+	for _, mf := range families {
+		if mf.GetName() != "netscaler_topology_node_state" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			labels := make(map[string]string)
+			for _, lp := range m.GetLabel() {
+				labels[lp.GetName()] = lp.GetValue()
+			}
+			if labels["id"] != "servicegroup:sg-web" {
+				continue
+			}
+			found = true
+			if labels["chain"] != "cs-web" {
+				t.Errorf("servicegroup chain = %q, want %q", labels["chain"], "cs-web")
+			}
+			if got := m.GetGauge().GetValue(); got != 0 {
+				t.Errorf("servicegroup state = %v, want 0 (one member down)", got)
+			}
+		}
+	}
+	if !found {
+		t.Fatal("no topology node state for servicegroup:sg-web")
+	}
+}
+
+// TestCSToLBTopology checks that CS vserver edges are resolved from direct
+// bindings, policy targets and policy actions alike.
+func TestCSToLBTopology(t *testing.T) {
+	srv := nitrotest.NewServer(nitrotest.Fixtures{
+		"stat/csvserver":                     `{"csvserver":[{"name":"cs-web","state":"UP"}]}`,
+		"stat/lbvserver":                     `{"lbvserver":[{"name":"lb-a","state":"UP"},{"name":"lb-b","state":"UP"},{"name":"lb-c","state":"UP"}]}`,
+		"config/csvserver_lbvserver_binding": `{"csvserver_lbvserver_binding":[{"name":"cs-web","lbvserver":"lb-a"}]}`,
+		"config/csvserver_cspolicy_binding": `{"csvserver_cspolicy_binding":[
+			{"name":"cs-web","policyname":"pol-b","priority":"10","targetlbvserver":"lb-b"},
+			{"name":"cs-web","policyname":"pol-c","priority":"20"},
+			{"name":"cs-web","policyname":"pol-a","priority":"30","targetlbvserver":"lb-a"}]}`,
+		"config/cspolicy": `{"cspolicy":[{"policyname":"pol-c","action":"act-c"}]}`,
+		"config/csaction": `{"csaction":[{"name":"act-c","targetlbvserver":"lb-c"}]}`,
+	})
+	defer srv.Close()
+
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(newTestExporter(t, srv.URL, nil))
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather failed: %v", err)
+	}
+
+	priorities := make(map[string]string)
+	for _, mf := range families {
+		if mf.GetName() != "netscaler_topology_edge" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			labels := make(map[string]string)
+			for _, lp := range m.GetLabel() {
+				labels[lp.GetName()] = lp.GetValue()
+			}
+			if labels["source"] == "csvserver:cs-web" {
+				priorities[labels["target"]] = labels["priority"]
+			}
+		}
+	}
+	want := map[string]string{"lbvserver:lb-a": "0", "lbvserver:lb-b": "10", "lbvserver:lb-c": "20"}
+	if len(priorities) != len(want) {
+		t.Errorf("got edges %v, want %v", priorities, want)
+	}
+	for target, priority := range want {
+		if priorities[target] != priority {
+			t.Errorf("edge to %s has priority %q, want %q", target, priorities[target], priority)
+		}
+	}
+}
+
+// TestTopologyParallelism checks that the topology holds a parallelism token,
+// so that its paged collections fetch further pages only with idle ones.
+func TestTopologyParallelism(t *testing.T) {
+	srv := newFakeNitro(t)
+	srv.SetResource("config/lbvserver_servicegroup_binding", `{"lbvserver_servicegroup_binding":[
+		{"name":"lb-web","servicegroupname":"sg-web"},{"name":"lb-web","servicegroupname":"sg-web-2"},{"name":"lb-web","servicegroupname":"sg-web-3"}]}`)
+	srv.AddFault(nitrotest.Fault{Resource: "config/lbvserver_servicegroup_binding", Latency: 50 * time.Millisecond})
+
+	target, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatalf("parsing %s: %v", srv.URL, err)
+	}
+	var inFlight, maxInFlight atomic.Int64
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	counting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/lbvserver_servicegroup_binding") {
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for m := maxInFlight.Load(); n > m && !maxInFlight.CompareAndSwap(m, n); m = maxInFlight.Load() {
+			}
+		}
+		proxy.ServeHTTP(w, r)
+	}))
+	t.Cleanup(counting.Close)
+
+	cfg := &config.Config{Labels: map[string]string{}, DisabledModules: allModulesExcept("topology")}
+	e := newTestExporter(t, counting.URL, cfg)
+	e.parallelism = 1
+	e.SetPageSize(1)
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(e)
+	if _, err := reg.Gather(); err != nil {
+		t.Fatalf("gather failed: %v", err)
+	}
+	if n := srv.Count("config/lbvserver_servicegroup_binding"); n != 3 {
+		t.Errorf("binding requests = %d, want 3 pages", n)
+	}
+	if n := maxInFlight.Load(); n != 1 {
+		t.Errorf("%d concurrent binding page requests, want 1 with parallelism 1", n)
+	}
+}
+
+// TestScrapeSessionExpiry checks that a scrape logs in again when the
+// appliance ended the session since the previous one.
+func TestScrapeSessionExpiry(t *testing.T) {
+	srv := newFakeNitro(t)
+	srv.SetCredentials("user", "pass")
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(newTestExporter(t, srv.URL, nil))
+
+	up := func() float64 {
+		t.Helper()
+		families, err := reg.Gather()
+		if err != nil {
+			t.Fatalf("gather failed: %v", err)
+		}
+		for _, mf := range families {
+			if mf.GetName() == "netscaler_up" {
+				return mf.GetMetric()[0].GetGauge().GetValue()
+			}
+		}
+		t.Fatal("no netscaler_up")
+		return 0
+	}
+
+	if v := up(); v != 1 {
+		t.Fatalf("first scrape: up = %v, want 1", v)
+	}
+	srv.ExpireSessions()
+	if v := up(); v != 1 {
+		t.Fatalf("scrape after session expiry: up = %v, want 1", v)
+	}
+	if n := srv.Count("config/login"); n < 2 {
+		t.Errorf("logins = %d, want a second one", n)
+	}
+}
+
+// TestScrapeAfterTargetShrinks checks that series of objects that disappear
+// from the target are not reported by later scrapes.
+func TestScrapeAfterTargetShrinks(t *testing.T) {
+	members := `{"servicegroupname":"sg-web?web-1","primaryipaddress":"10.0.0.1","primaryport":80,"state":"UP"},
+		{"servicegroupname":"sg-web?web-2","primaryipaddress":"10.0.0.2","primaryport":80,"state":"UP"}`
+	srv := nitrotest.NewServer(nitrotest.Fixtures{
+		"config/servicegroup":      `{"servicegroup":[{"servicegroupname":"sg-web"}]}`,
+		"stat/servicegroup/sg-web": `{"servicegroup":[{"servicegroupname":"sg-web","servicegroupmember":[` + members + `]}]}`,
+	})
+	defer srv.Close()
+
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(newTestExporter(t, srv.URL, nil))
+
+	countMembers := func() int {
+		t.Helper()
+		families, err := reg.Gather()
+		if err != nil {
+			t.Fatalf("gather failed: %v", err)
+		}
+		for _, mf := range families {
+			if mf.GetName() == "netscaler_servicegroup_state" {
+				return len(mf.GetMetric())
+			}
+		}
+		return 0
+	}
+
+	if n := countMembers(); n != 2 {
+		t.Fatalf("first scrape: %d members, want 2", n)
+	}
+	members = `{"servicegroupname":"sg-web?web-1","primaryipaddress":"10.0.0.1","primaryport":80,"state":"UP"}`
+	srv.SetResource("stat/servicegroup/sg-web", `{"servicegroup":[{"servicegroupname":"sg-web","servicegroupmember":[`+members+`]}]}`)
+	if n := countMembers(); n != 1 {
+		t.Fatalf("second scrape: %d members, want 1", n)
+	}
+}
+
+// TestLegacyMetricNames checks that cumulative counters are published as
+// counters and, with legacy names enabled, also under their schema 1 names.
+func TestLegacyMetricNames(t *testing.T) {
+	srv := newFakeNitro(t)
+
+	types := func(legacy bool) map[string]string {
+		t.Helper()
+		cfg := &config.Config{Labels: map[string]string{"env": "test"}, LegacyMetricNames: legacy}
+		e := newTestExporter(t, srv.URL, cfg)
+		reg := prometheus.NewPedanticRegistry()
+		reg.MustRegister(e)
+		families, err := reg.Gather()
+		if err != nil {
+			t.Fatalf("gather failed: %v", err)
+		}
+		byName := make(map[string]string)
+		for _, mf := range families {
+			byName[mf.GetName()] = mf.GetType().String()
+		}
+		return byName
+	}
+
+	got := types(false)
+	if typ := got["netscaler_virtual_servers_requests_total"]; typ != "COUNTER" {
+		t.Errorf("netscaler_virtual_servers_requests_total type = %q, want COUNTER", typ)
+	}
+	if _, ok := got["netscaler_virtual_servers_total_requests"]; ok {
+		t.Error("legacy name netscaler_virtual_servers_total_requests published without -legacy-metric-names")
+	}
+
+	got = types(true)
+	if typ := got["netscaler_virtual_servers_requests_total"]; typ != "COUNTER" {
+		t.Errorf("netscaler_virtual_servers_requests_total type = %q, want COUNTER", typ)
+	}
+	if typ := got["netscaler_virtual_servers_total_requests"]; typ != "GAUGE" {
+		t.Errorf("netscaler_virtual_servers_total_requests type = %q, want GAUGE", typ)
+	}
+}
+
+// TestTrafficTotals checks that the traffic totals, which Nitro reports in
+// megabits, are published in bytes and, under their legacy names, unconverted.
+func TestTrafficTotals(t *testing.T) {
+	srv := newFakeNitro(t)
+	srv.SetResource("stat/ns", `{"ns":{"totrxmbits":"8","tottxmbits":"16"}}`)
+	cfg := &config.Config{Labels: map[string]string{"env": "test"}, DisabledModules: allModulesExcept("ns_stats"), LegacyMetricNames: true}
+	e := newTestExporter(t, srv.URL, cfg)
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(e)
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather failed: %v", err)
+	}
+
+	values := make(map[string]float64)
+	for _, mf := range families {
+		for _, m := range mf.GetMetric() {
+			values[mf.GetName()] = m.GetCounter().GetValue() + m.GetGauge().GetValue()
+		}
+	}
+	want := map[string]float64{
+		"netscaler_received_bytes_total":    1e6,
+		"netscaler_transmitted_bytes_total": 2e6,
+		"netscaler_total_received_mb":       8,
+		"netscaler_total_transmit_mb":       16,
+	}
+	for name, v := range want {
+		if values[name] != v {
+			t.Errorf("%s = %v, want %v", name, values[name], v)
+		}
+	}
+}
+
+// TestModuleTimeout checks that a module exceeding its timeout is reported as
+// timed out without holding up the other modules.
+func TestModuleTimeout(t *testing.T) {
+	srv := newFakeNitro(t)
+	srv.AddFault(nitrotest.Fault{Resource: "stat/lbvserver", Latency: 5 * time.Second})
+
+	cfg := &config.Config{
+		Labels:          map[string]string{},
+		DisabledModules: []string{"topology"},
+		Timeouts:        map[string]time.Duration{"virtual_servers": 100 * time.Millisecond},
+	}
+	e := newTestExporter(t, srv.URL, cfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(e.WithContext(ctx))
+
+	start := time.Now()
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("scrape took %s, want the module timeout to cut it short", elapsed)
+	}
+
+	success := make(map[string]float64)
+	timeouts := make(map[string]float64)
+	for _, mf := range families {
+		for _, m := range mf.GetMetric() {
+			labels := make(map[string]string)
+			for _, lp := range m.GetLabel() {
+				labels[lp.GetName()] = lp.GetValue()
+			}
+			switch mf.GetName() {
+			case "netscaler_scrape_success":
+				success[labels["module"]] = m.GetGauge().GetValue()
+			case "netscaler_scrape_errors_total":
+				if labels["reason"] == "timeout" {
+					timeouts[labels["module"]] = m.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+
+	if success["virtual_servers"] != 0 {
+		t.Errorf("virtual_servers success = %v, want 0", success["virtual_servers"])
+	}
+	if timeouts["virtual_servers"] != 1 {
+		t.Errorf("virtual_servers timeouts = %v, want 1", timeouts["virtual_servers"])
+	}
+	if success["ns_stats"] != 1 {
+		t.Errorf("ns_stats success = %v, want 1", success["ns_stats"])
+	}
+}
+
+// TestCapabilityDetection checks that modules the appliance does not license,
+// enable or need are skipped and reported, and that detection is cached.
+func TestCapabilityDetection(t *testing.T) {
+	srv := newFakeNitro(t)
+	for resource, body := range map[string]string{
+		"config/nsversion": `{"nsversion":{"version":"NetScaler NS14.1: Build 25.53.nc"}}`,
+		"config/nsmode":    `{"nsmode":{"mode":["FR","L3","USNIP"]}}`,
+		"config/nslicense": `{"nslicense":{"modelid":"1000","lb":true,"cs":true,"ssl":true,"gslb":false,"sslvpn":true,"aaa":false}}`,
+		"config/nsfeature": `{"nsfeature":{"lb":true,"cs":true,"ssl":true,"gslb":false,"sslvpn":false,"aaa":false}}`,
+		"config/hanode":    `{"hanode":[{"id":"0","ipaddress":"10.0.0.10","state":"Primary"}]}`,
+	} {
+		srv.SetResource(resource, body)
+	}
+
+	cfg := &config.Config{
+		Labels:            map[string]string{},
+		DisabledModules:   []string{"ssl_certs"},
+		CapabilityRefresh: time.Hour,
+	}
+	e := newTestExporter(t, srv.URL, cfg)
+	e.StartCapabilityDetection()
+	waitForCapabilities(t, e)
+
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(e)
+
+	if _, err := reg.Gather(); err != nil {
+		t.Fatalf("gather failed: %v", err)
+	}
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather failed: %v", err)
+	}
+
+	reasons := make(map[string]string)
+	for _, mf := range families {
+		if mf.GetName() != "netscaler_module_enabled" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			labels := make(map[string]string)
+			for _, lp := range m.GetLabel() {
+				labels[lp.GetName()] = lp.GetValue()
+			}
+			if m.GetGauge().GetValue() == 0 {
+				reasons[labels["module"]] = labels["reason"]
+			}
+		}
+	}
+	want := map[string]string{
+		"gslb_services": "not_licensed",
+		"gslb_vservers": "not_licensed",
+		"vpn_vservers":  "feature_disabled",
+		"aaa_stats":     "feature_disabled",
+		"ha_stats":      "standalone",
+		"ssl_certs":     "disabled",
+	}
+	if len(reasons) != len(want) {
+		t.Errorf("skipped modules = %v, want %v", reasons, want)
+	}
+	for module, reason := range want {
+		if reasons[module] != reason {
+			t.Errorf("module %s reason = %q, want %q", module, reasons[module], reason)
+		}
+	}
+
+	for _, resource := range []string{"stat/gslbvserver", "stat/vpnvserver", "stat/aaa", "stat/hanode"} {
+		if n := srv.Count(resource); n != 0 {
+			t.Errorf("%s requested %d times, want skipped", resource, n)
+		}
+	}
+	if n := srv.Count("config/nsversion"); n != 1 {
+		t.Errorf("config/nsversion requested %d times, want capabilities detected once", n)
+	}
+}
+
+// TestCapabilityUnknownFlags checks that license and feature flags missing
+// from the appliance's response keep their modules enabled.
+func TestCapabilityUnknownFlags(t *testing.T) {
+	caps := capabilities{
+		licensed: map[string]bool{"lb": true, "gslb": false},
+		enabled:  map[string]bool{"lb": true, "sslvpn": false},
+		haNodes:  -1,
+	}
+	want := map[string]string{"gslb_services": "not_licensed", "gslb_vservers": "not_licensed", "vpn_vservers": "feature_disabled"}
+	if got := caps.skippedModules(); !reflect.DeepEqual(got, want) {
+		t.Errorf("skipped modules = %v, want %v", got, want)
+	}
+}
+
+// waitForCapabilities waits for the exporter's first capability detection.
+func waitForCapabilities(t *testing.T, e *Exporter) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); e.skippedModules() == nil; {
+		if time.Now().After(deadline) {
+			t.Fatal("capabilities not detected")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// TestCapabilityDetectionBackground checks that scrapes neither wait for a
+// slow detection nor start one.
+func TestCapabilityDetectionBackground(t *testing.T) {
+	srv := newFakeNitro(t)
+	srv.AddFault(nitrotest.Fault{Resource: "config/hanode", Latency: 2 * time.Second})
+	cfg := &config.Config{Labels: map[string]string{}, DisabledModules: allModulesExcept("ns_stats"), CapabilityRefresh: time.Hour}
+	e := newTestExporter(t, srv.URL, cfg)
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(e)
+
+	if _, err := reg.Gather(); err != nil {
+		t.Fatalf("gather failed: %v", err)
+	}
+	if n := srv.Count("config/nsversion"); n != 0 {
+		t.Errorf("config/nsversion requested %d times by a scrape, want no detection", n)
+	}
+
+	e.StartCapabilityDetection()
+	for deadline := time.Now().Add(5 * time.Second); srv.Count("config/hanode") == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("capability detection did not start")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	start := time.Now()
+	if _, err := reg.Gather(); err != nil {
+		t.Fatalf("gather failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("scrape took %s, want it not to wait for the running detection", elapsed)
+	}
+
+	waitForCapabilities(t, e)
+	if _, err := reg.Gather(); err != nil {
+		t.Fatalf("gather failed: %v", err)
+	}
+	if n := srv.Count("config/nsversion"); n != 1 {
+		t.Errorf("config/nsversion requested %d times, want capabilities detected once", n)
+	}
+}
+
+// TestCircuitBreaker checks that a module failing on every scrape is suspended
+// after the configured number of failures and resumed after a successful retry.
+func TestCircuitBreaker(t *testing.T) {
+	srv := newFakeNitro(t)
+	// Fails the two scrapes before the circuit opens, not the retry after it
+	srv.AddFault(nitrotest.Fault{Resource: "stat/csvserver", Times: 2, StatusCode: 599, ErrorCode: netscaler.NSERR_NOT_AUTHORIZED, Message: "Not authorized to execute this command"})
+
+	cfg := &config.Config{
+		Labels:            map[string]string{},
+		DisabledModules:   []string{"topology"},
+		BreakerFailures:   2,
+		BreakerBackoff:    time.Hour,
+		BreakerMaxBackoff: 2 * time.Hour,
+	}
+	e := newTestExporter(t, srv.URL, cfg)
+
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(e)
+	circuitState := func() float64 {
+		t.Helper()
+		families, err := reg.Gather()
+		if err != nil {
+			t.Fatalf("gather failed: %v", err)
+		}
+		for _, mf := range families {
+			if mf.GetName() != "netscaler_module_circuit_state" {
+				continue
+			}
+			for _, m := range mf.GetMetric() {
+				for _, lp := range m.GetLabel() {
+					if lp.GetName() == "module" && lp.GetValue() == "cs_vservers" {
+						return m.GetGauge().GetValue()
+					}
+				}
+			}
+		}
+		t.Fatal("no circuit state for cs_vservers")
+		return 0
+	}
+
+	for i := range 3 {
+		want := 0.0
+		if i >= 1 {
+			want = 1 // opened by the second failure
+		}
+		if state := circuitState(); state != want {
+			t.Errorf("scrape %d: circuit state = %v, want %v", i+1, state, want)
+		}
+	}
+	if n := srv.Count("stat/csvserver"); n != 2 {
+		t.Errorf("stat/csvserver requested %d times, want 2 before the circuit opened", n)
+	}
+
+	status := e.ModuleStatus()
+	if len(status) != 1 || status[0].Module != "cs_vservers" || status[0].State != "open" || status[0].Reason != "permission" {
+		t.Fatalf("module status = %+v, want cs_vservers open with reason permission", status)
+	}
+
+	// Expire the backoff; the next scrape retries the module and closes the circuit
+	e.breaker.mu.Lock()
+	e.breaker.circuits["cs_vservers"].openUntil = time.Now()
+	e.breaker.mu.Unlock()
+	if state := circuitState(); state != 0 {
+		t.Errorf("circuit state after successful retry = %v, want 0", state)
+	}
+	if status := e.ModuleStatus(); len(status) != 0 {
+		t.Errorf("module status after recovery = %+v, want none", status)
+	}
+}
+
+// TestCallBudget checks that low-priority modules are deferred once the
+// scrape's call budget is spent by the other modules.
+func TestCallBudget(t *testing.T) {
+	srv := newFakeNitro(t)
+	enabled := map[string]bool{"ns_stats": true, "interfaces": true, "service_groups": true, "system_cpu": true}
+	var disabled []string
+	for _, m := range config.ModuleNames {
+		if !enabled[m] {
+			disabled = append(disabled, m)
+		}
+	}
+	cfg := &config.Config{Labels: map[string]string{}, DisabledModules: disabled}
+	e := newTestExporter(t, srv.URL, cfg)
+	e.SetCallBudget(2)
+
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(e)
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather failed: %v", err)
+	}
+
+	deferred := make(map[string]float64)
+	remaining := -1.0
+	for _, mf := range families {
+		for _, m := range mf.GetMetric() {
+			switch mf.GetName() {
+			case "netscaler_modules_deferred_total":
+				deferred[m.GetLabel()[0].GetValue()] = m.GetCounter().GetValue()
+			case "netscaler_scrape_call_budget_remaining":
+				remaining = m.GetGauge().GetValue()
+			}
+		}
+	}
+	if deferred["service_groups"] != 1 || deferred["system_cpu"] != 1 || len(deferred) != 2 {
+		t.Errorf("deferred modules = %v, want service_groups and system_cpu", deferred)
+	}
+	if remaining != 0 {
+		t.Errorf("call budget remaining = %v, want 0", remaining)
+	}
+
+	if srv.Count("stat/ns") != 1 || srv.Count("stat/Interface") != 1 {
+		t.Errorf("high-priority requests = %v, want stat/ns and stat/Interface once", srv.Requests())
+	}
+	if srv.Count("config/servicegroup") != 0 || srv.Count("stat/systemcpu") != 0 {
+		t.Errorf("low-priority modules made requests: %v", srv.Requests())
+	}
+}
+
+// TestCallBudgetServiceGroups checks that service_groups stops fetching member
+// stats once the call budget is spent, and that the budget errors are counted
+// across scrapes.
+func TestCallBudgetServiceGroups(t *testing.T) {
+	srv := newFakeNitro(t)
+	var groups []string
+	for i := range 20 {
+		groups = append(groups, fmt.Sprintf(`{"servicegroupname":"sg-%d"}`, i))
+		srv.SetResource(fmt.Sprintf("stat/servicegroup/sg-%d", i), `{"servicegroup":[]}`)
+	}
+	srv.SetResource("config/servicegroup", `{"servicegroup":[`+strings.Join(groups, ",")+`]}`)
+
+	cfg := &config.Config{Labels: map[string]string{}, DisabledModules: allModulesExcept("service_groups")}
+	e := newTestExporter(t, srv.URL, cfg)
+	// The service group list and three groups
+	e.SetCallBudget(4)
+
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(e)
+	var families []*dto.MetricFamily
+	for range 2 {
+		var err error
+		if families, err = reg.Gather(); err != nil {
+			t.Fatalf("gather failed: %v", err)
+		}
+	}
+	budgetErrors := -1.0
+	for _, mf := range families {
+		if mf.GetName() != "netscaler_scrape_errors_total" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			if m.GetLabel()[1].GetValue() != "budget" {
+				t.Errorf("scrape error %v, want only budget errors", m.GetLabel())
+				continue
+			}
+			budgetErrors = m.GetCounter().GetValue()
+		}
+	}
+	if budgetErrors != 2 {
+		t.Errorf("service_groups budget errors = %v, want 2", budgetErrors)
+	}
+
+	apiReg := prometheus.NewPedanticRegistry()
+	if err := netscaler.RegisterMetrics(apiReg); err != nil {
+		t.Fatalf("RegisterMetrics: %v", err)
+	}
+	apiFamilies, err := apiReg.Gather()
+	if err != nil {
+		t.Fatalf("gather failed: %v", err)
+	}
+	target := strings.TrimPrefix(srv.URL, "http://")
+	refused := 0.0
+	for _, mf := range apiFamilies {
+		if mf.GetName() != "netscaler_exporter_api_call_budget_exhausted_total" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			if m.GetLabel()[0].GetValue() == target {
+				refused = m.GetCounter().GetValue()
+			}
+		}
+	}
+	// At most the groups in flight when the budget ran out, per scrape
+	if refused < 2 || refused > 2*3 {
+		t.Errorf("%v requests refused by the budget, want the remaining groups skipped", refused)
+	}
+}
@@ -1,7 +1,10 @@
 package collector
 
 import (
+	"context"
+	"errors"
 	"log/slog"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 
@@ -16,21 +19,34 @@ type Exporter struct {
 	config      *config.Config
 	url         string
 	targetType  string // "adc" or "mps"
-	username    string
-	password    string
-	ignoreCert  bool
-	caFile      string
+	authMode    string
+	tls         config.TLSConfig
 	parallelism int
 	labelKeys   []string
 	logger      *slog.Logger
 
-	// Chain membership for topology filtering (nodeID → comma-separated chain names)
-	chainMembership map[string]string
-
-	// Persistent clients for session-based authentication
+	// Persistent clients for session-based authentication; base is the
+	// shared core of whichever client the target type uses
+	base      *netscaler.BaseClient
 	nsClient  *netscaler.NitroClient
 	mpsClient *netscaler.MPSClient
 
+	// Background poller, nil when scraping on every Collect
+	poller *poller
+
+	// Modules skipped by capability detection
+	capabilities capabilityState
+
+	// Circuit breakers of failing modules
+	breaker breaker
+
+	// Maximum API requests per scrape, 0 for no limit
+	callBudget int
+
+	// Module scrape errors by module and reason, and deferred modules
+	scrapeErrorCounts labelCounter
+	deferredCounts    labelCounter
+
 	// System metrics (descriptors)
 	modelID                                *prometheus.Desc
 	mgmtCPUUsage                           *prometheus.Desc
@@ -38,167 +54,167 @@ type Exporter struct {
 	pktCPUUsage                            *prometheus.Desc
 	flashPartitionUsage                    *prometheus.Desc
 	varPartitionUsage                      *prometheus.Desc
-	totRxMB                                *prometheus.Desc
-	totTxMB                                *prometheus.Desc
-	httpRequests                           *prometheus.Desc
-	httpResponses                          *prometheus.Desc
+	totRxBytes                             counterDesc
+	totTxBytes                             counterDesc
+	httpRequests                           counterDesc
+	httpResponses                          counterDesc
 	tcpCurrentClientConnections            *prometheus.Desc
 	tcpCurrentClientConnectionsEstablished *prometheus.Desc
 	tcpCurrentServerConnections            *prometheus.Desc
 	tcpCurrentServerConnectionsEstablished *prometheus.Desc
 
 	// Interface metrics
-	interfacesRxBytes        *prometheus.GaugeVec
-	interfacesTxBytes        *prometheus.GaugeVec
-	interfacesRxPackets      *prometheus.GaugeVec
-	interfacesTxPackets      *prometheus.GaugeVec
-	interfacesJumboPacketsRx *prometheus.GaugeVec
-	interfacesJumboPacketsTx *prometheus.GaugeVec
-	interfacesErrorPacketsRx *prometheus.GaugeVec
+	interfacesRxBytes        counterDesc
+	interfacesTxBytes        counterDesc
+	interfacesRxPackets      counterDesc
+	interfacesTxPackets      counterDesc
+	interfacesJumboPacketsRx counterDesc
+	interfacesJumboPacketsTx counterDesc
+	interfacesErrorPacketsRx counterDesc
 
 	// Virtual Server metrics
-	virtualServersState                    *prometheus.GaugeVec
-	virtualServersWaitingRequests          *prometheus.GaugeVec
-	virtualServersHealth                   *prometheus.GaugeVec
-	virtualServersInactiveServices         *prometheus.GaugeVec
-	virtualServersActiveServices           *prometheus.GaugeVec
-	virtualServersTotalHits                *prometheus.GaugeVec
-	virtualServersTotalRequests            *prometheus.GaugeVec
-	virtualServersTotalResponses           *prometheus.GaugeVec
-	virtualServersTotalRequestBytes        *prometheus.GaugeVec
-	virtualServersTotalResponseBytes       *prometheus.GaugeVec
-	virtualServersCurrentClientConnections *prometheus.GaugeVec
-	virtualServersCurrentServerConnections *prometheus.GaugeVec
+	virtualServersState                    *prometheus.Desc
+	virtualServersWaitingRequests          *prometheus.Desc
+	virtualServersHealth                   *prometheus.Desc
+	virtualServersInactiveServices         *prometheus.Desc
+	virtualServersActiveServices           *prometheus.Desc
+	virtualServersTotalHits                counterDesc
+	virtualServersTotalRequests            counterDesc
+	virtualServersTotalResponses           counterDesc
+	virtualServersTotalRequestBytes        counterDesc
+	virtualServersTotalResponseBytes       counterDesc
+	virtualServersCurrentClientConnections *prometheus.Desc
+	virtualServersCurrentServerConnections *prometheus.Desc
 
 	// Service metrics
-	servicesThroughput                   *prometheus.GaugeVec
-	servicesAvgTTFB                      *prometheus.GaugeVec
-	servicesState                        *prometheus.GaugeVec
-	servicesTotalRequests                *prometheus.GaugeVec
-	servicesTotalResponses               *prometheus.GaugeVec
-	servicesTotalRequestBytes            *prometheus.GaugeVec
-	servicesTotalResponseBytes           *prometheus.GaugeVec
-	servicesCurrentClientConns           *prometheus.GaugeVec
-	servicesSurgeCount                   *prometheus.GaugeVec
-	servicesCurrentServerConns           *prometheus.GaugeVec
-	servicesServerEstablishedConnections *prometheus.GaugeVec
-	servicesCurrentReusePool             *prometheus.GaugeVec
-	servicesMaxClients                   *prometheus.GaugeVec
-	servicesCurrentLoad                  *prometheus.GaugeVec
-	servicesVirtualServerServiceHits     *prometheus.GaugeVec
-	servicesActiveTransactions           *prometheus.GaugeVec
+	servicesThroughput                   *prometheus.Desc
+	servicesAvgTTFB                      *prometheus.Desc
+	servicesState                        *prometheus.Desc
+	servicesTotalRequests                counterDesc
+	servicesTotalResponses               counterDesc
+	servicesTotalRequestBytes            counterDesc
+	servicesTotalResponseBytes           counterDesc
+	servicesCurrentClientConns           *prometheus.Desc
+	servicesSurgeCount                   *prometheus.Desc
+	servicesCurrentServerConns           *prometheus.Desc
+	servicesServerEstablishedConnections *prometheus.Desc
+	servicesCurrentReusePool             *prometheus.Desc
+	servicesMaxClients                   *prometheus.Desc
+	servicesCurrentLoad                  *prometheus.Desc
+	servicesVirtualServerServiceHits     counterDesc
+	servicesActiveTransactions           *prometheus.Desc
 
 	// Service Group metrics
-	serviceGroupsState                        *prometheus.GaugeVec
-	serviceGroupsAvgTTFB                      *prometheus.GaugeVec
-	serviceGroupsTotalRequests                *prometheus.GaugeVec
-	serviceGroupsTotalResponses               *prometheus.GaugeVec
-	serviceGroupsTotalRequestBytes            *prometheus.GaugeVec
-	serviceGroupsTotalResponseBytes           *prometheus.GaugeVec
-	serviceGroupsCurrentClientConnections     *prometheus.GaugeVec
-	serviceGroupsSurgeCount                   *prometheus.GaugeVec
-	serviceGroupsCurrentServerConnections     *prometheus.GaugeVec
-	serviceGroupsServerEstablishedConnections *prometheus.GaugeVec
-	serviceGroupsCurrentReusePool             *prometheus.GaugeVec
-	serviceGroupsMaxClients                   *prometheus.GaugeVec
+	serviceGroupsState                        *prometheus.Desc
+	serviceGroupsAvgTTFB                      *prometheus.Desc
+	serviceGroupsTotalRequests                counterDesc
+	serviceGroupsTotalResponses               counterDesc
+	serviceGroupsTotalRequestBytes            counterDesc
+	serviceGroupsTotalResponseBytes           counterDesc
+	serviceGroupsCurrentClientConnections     *prometheus.Desc
+	serviceGroupsSurgeCount                   *prometheus.Desc
+	serviceGroupsCurrentServerConnections     *prometheus.Desc
+	serviceGroupsServerEstablishedConnections *prometheus.Desc
+	serviceGroupsCurrentReusePool             *prometheus.Desc
+	serviceGroupsMaxClients                   *prometheus.Desc
 
 	// GSLB Service metrics
-	gslbServicesState                    *prometheus.GaugeVec
-	gslbServicesTotalRequests            *prometheus.GaugeVec
-	gslbServicesTotalResponses           *prometheus.GaugeVec
-	gslbServicesTotalRequestBytes        *prometheus.GaugeVec
-	gslbServicesTotalResponseBytes       *prometheus.GaugeVec
-	gslbServicesCurrentClientConns       *prometheus.GaugeVec
-	gslbServicesCurrentServerConns       *prometheus.GaugeVec
-	gslbServicesCurrentLoad              *prometheus.GaugeVec
-	gslbServicesVirtualServerServiceHits *prometheus.GaugeVec
-	gslbServicesEstablishedConnections   *prometheus.GaugeVec
+	gslbServicesState                    *prometheus.Desc
+	gslbServicesTotalRequests            counterDesc
+	gslbServicesTotalResponses           counterDesc
+	gslbServicesTotalRequestBytes        counterDesc
+	gslbServicesTotalResponseBytes       counterDesc
+	gslbServicesCurrentClientConns       *prometheus.Desc
+	gslbServicesCurrentServerConns       *prometheus.Desc
+	gslbServicesCurrentLoad              *prometheus.Desc
+	gslbServicesVirtualServerServiceHits counterDesc
+	gslbServicesEstablishedConnections   *prometheus.Desc
 
 	// GSLB Virtual Server metrics
-	gslbVirtualServersState                    *prometheus.GaugeVec
-	gslbVirtualServersHealth                   *prometheus.GaugeVec
-	gslbVirtualServersInactiveServices         *prometheus.GaugeVec
-	gslbVirtualServersActiveServices           *prometheus.GaugeVec
-	gslbVirtualServersTotalHits                *prometheus.GaugeVec
-	gslbVirtualServersTotalRequests            *prometheus.GaugeVec
-	gslbVirtualServersTotalResponses           *prometheus.GaugeVec
-	gslbVirtualServersTotalRequestBytes        *prometheus.GaugeVec
-	gslbVirtualServersTotalResponseBytes       *prometheus.GaugeVec
-	gslbVirtualServersCurrentClientConnections *prometheus.GaugeVec
-	gslbVirtualServersCurrentServerConnections *prometheus.GaugeVec
+	gslbVirtualServersState                    *prometheus.Desc
+	gslbVirtualServersHealth                   *prometheus.Desc
+	gslbVirtualServersInactiveServices         *prometheus.Desc
+	gslbVirtualServersActiveServices           *prometheus.Desc
+	gslbVirtualServersTotalHits                counterDesc
+	gslbVirtualServersTotalRequests            counterDesc
+	gslbVirtualServersTotalResponses           counterDesc
+	gslbVirtualServersTotalRequestBytes        counterDesc
+	gslbVirtualServersTotalResponseBytes       counterDesc
+	gslbVirtualServersCurrentClientConnections *prometheus.Desc
+	gslbVirtualServersCurrentServerConnections *prometheus.Desc
 
 	// CS Virtual Server metrics
-	csVirtualServersState                              *prometheus.GaugeVec
-	csVirtualServersTotalHits                          *prometheus.GaugeVec
-	csVirtualServersTotalRequests                      *prometheus.GaugeVec
-	csVirtualServersTotalResponses                     *prometheus.GaugeVec
-	csVirtualServersTotalRequestBytes                  *prometheus.GaugeVec
-	csVirtualServersTotalResponseBytes                 *prometheus.GaugeVec
-	csVirtualServersCurrentClientConnections           *prometheus.GaugeVec
-	csVirtualServersCurrentServerConnections           *prometheus.GaugeVec
-	csVirtualServersEstablishedConnections             *prometheus.GaugeVec
-	csVirtualServersTotalPacketsReceived               *prometheus.GaugeVec
-	csVirtualServersTotalPacketsSent                   *prometheus.GaugeVec
-	csVirtualServersTotalSpillovers                    *prometheus.GaugeVec
-	csVirtualServersDeferredRequests                   *prometheus.GaugeVec
-	csVirtualServersNumberInvalidRequestResponse       *prometheus.GaugeVec
-	csVirtualServersNumberInvalidRequestResponseDropped *prometheus.GaugeVec
-	csVirtualServersTotalVServerDownBackupHits         *prometheus.GaugeVec
-	csVirtualServersCurrentMultipathSessions           *prometheus.GaugeVec
-	csVirtualServersCurrentMultipathSubflows           *prometheus.GaugeVec
+	csVirtualServersState                               *prometheus.Desc
+	csVirtualServersTotalHits                           counterDesc
+	csVirtualServersTotalRequests                       counterDesc
+	csVirtualServersTotalResponses                      counterDesc
+	csVirtualServersTotalRequestBytes                   counterDesc
+	csVirtualServersTotalResponseBytes                  counterDesc
+	csVirtualServersCurrentClientConnections            *prometheus.Desc
+	csVirtualServersCurrentServerConnections            *prometheus.Desc
+	csVirtualServersEstablishedConnections              *prometheus.Desc
+	csVirtualServersTotalPacketsReceived                counterDesc
+	csVirtualServersTotalPacketsSent                    counterDesc
+	csVirtualServersTotalSpillovers                     counterDesc
+	csVirtualServersDeferredRequests                    counterDesc
+	csVirtualServersNumberInvalidRequestResponse        counterDesc
+	csVirtualServersNumberInvalidRequestResponseDropped counterDesc
+	csVirtualServersTotalVServerDownBackupHits          counterDesc
+	csVirtualServersCurrentMultipathSessions            *prometheus.Desc
+	csVirtualServersCurrentMultipathSubflows            *prometheus.Desc
 
 	// VPN Virtual Server metrics
-	vpnVirtualServersTotalRequests      *prometheus.GaugeVec
-	vpnVirtualServersTotalResponses     *prometheus.GaugeVec
-	vpnVirtualServersTotalRequestBytes  *prometheus.GaugeVec
-	vpnVirtualServersTotalResponseBytes *prometheus.GaugeVec
-	vpnVirtualServersState              *prometheus.GaugeVec
+	vpnVirtualServersTotalRequests      counterDesc
+	vpnVirtualServersTotalResponses     counterDesc
+	vpnVirtualServersTotalRequestBytes  counterDesc
+	vpnVirtualServersTotalResponseBytes counterDesc
+	vpnVirtualServersState              *prometheus.Desc
 
 	// AAA metrics
-	aaaAuthSuccess         *prometheus.GaugeVec
-	aaaAuthFail            *prometheus.GaugeVec
-	aaaAuthOnlyHTTPSuccess *prometheus.GaugeVec
-	aaaAuthOnlyHTTPFail    *prometheus.GaugeVec
-	aaaCurIcaSessions      *prometheus.GaugeVec
-	aaaCurIcaOnlyConn      *prometheus.GaugeVec
+	aaaAuthSuccess         counterDesc
+	aaaAuthFail            counterDesc
+	aaaAuthOnlyHTTPSuccess counterDesc
+	aaaAuthOnlyHTTPFail    counterDesc
+	aaaCurIcaSessions      *prometheus.Desc
+	aaaCurIcaOnlyConn      *prometheus.Desc
 
 	// Topology metrics
-	topologyNode *prometheus.GaugeVec
-	topologyEdge *prometheus.GaugeVec
+	topologyNode *prometheus.Desc
+	topologyEdge *prometheus.Desc
 
 	// Topology node stats (for node graph visualization)
-	topologyNodeState        *prometheus.GaugeVec
-	topologyNodeHealth       *prometheus.GaugeVec
-	topologyNodeRequestsTotal *prometheus.GaugeVec
-	topologyNodeConnections  *prometheus.GaugeVec
-	topologyNodeTTFBMs       *prometheus.GaugeVec
+	topologyNodeState         *prometheus.Desc
+	topologyNodeHealth        *prometheus.Desc
+	topologyNodeRequestsTotal *prometheus.Desc
+	topologyNodeConnections   *prometheus.Desc
+	topologyNodeTTFBMs        *prometheus.Desc
 
 	// Protocol HTTP metrics
-	httpTotalRequests              *prometheus.Desc
-	httpTotalResponses             *prometheus.Desc
-	httpTotalPosts                 *prometheus.Desc
-	httpTotalGets                  *prometheus.Desc
-	httpTotalOthers                *prometheus.Desc
-	httpTotalRxRequestBytes        *prometheus.Desc
-	httpTotalRxResponseBytes       *prometheus.Desc
-	httpTotalTxRequestBytes        *prometheus.Desc
-	httpTotal10Requests            *prometheus.Desc
-	httpTotal11Requests            *prometheus.Desc
-	httpTotal10Responses           *prometheus.Desc
-	httpTotal11Responses           *prometheus.Desc
-	httpTotalChunkedRequests       *prometheus.Desc
-	httpTotalChunkedResponses      *prometheus.Desc
-	httpTotalSPDYStreams           *prometheus.Desc
-	httpTotalSPDYv2Streams         *prometheus.Desc
-	httpTotalSPDYv3Streams         *prometheus.Desc
-	httpErrNoReuseMultipart        *prometheus.Desc
-	httpErrIncompleteHeaders       *prometheus.Desc
-	httpErrIncompleteRequests      *prometheus.Desc
-	httpErrIncompleteResponses     *prometheus.Desc
-	httpErrServerBusy              *prometheus.Desc
-	httpErrLargeContent            *prometheus.Desc
-	httpErrLargeChunk              *prometheus.Desc
-	httpErrLargeCtlen              *prometheus.Desc
+	httpTotalRequests              counterDesc
+	httpTotalResponses             counterDesc
+	httpTotalPosts                 counterDesc
+	httpTotalGets                  counterDesc
+	httpTotalOthers                counterDesc
+	httpTotalRxRequestBytes        counterDesc
+	httpTotalRxResponseBytes       counterDesc
+	httpTotalTxRequestBytes        counterDesc
+	httpTotal10Requests            counterDesc
+	httpTotal11Requests            counterDesc
+	httpTotal10Responses           counterDesc
+	httpTotal11Responses           counterDesc
+	httpTotalChunkedRequests       counterDesc
+	httpTotalChunkedResponses      counterDesc
+	httpTotalSPDYStreams           counterDesc
+	httpTotalSPDYv2Streams         counterDesc
+	httpTotalSPDYv3Streams         counterDesc
+	httpErrNoReuseMultipart        counterDesc
+	httpErrIncompleteHeaders       counterDesc
+	httpErrIncompleteRequests      counterDesc
+	httpErrIncompleteResponses     counterDesc
+	httpErrServerBusy              counterDesc
+	httpErrLargeContent            counterDesc
+	httpErrLargeChunk              counterDesc
+	httpErrLargeCtlen              counterDesc
 	httpRequestsRate               *prometheus.Desc
 	httpResponsesRate              *prometheus.Desc
 	httpPostsRate                  *prometheus.Desc
@@ -222,16 +238,16 @@ type Exporter struct {
 	httpErrServerBusyRate          *prometheus.Desc
 
 	// Protocol TCP metrics
-	tcpTotalRxPackets           *prometheus.Desc
-	tcpTotalRxBytes             *prometheus.Desc
-	tcpTotalTxBytes             *prometheus.Desc
-	tcpTotalTxPackets           *prometheus.Desc
-	tcpTotalClientConnOpened    *prometheus.Desc
-	tcpTotalServerConnOpened    *prometheus.Desc
-	tcpTotalSyn                 *prometheus.Desc
-	tcpTotalSynProbe            *prometheus.Desc
-	tcpTotalServerFin           *prometheus.Desc
-	tcpTotalClientFin           *prometheus.Desc
+	tcpTotalRxPackets           counterDesc
+	tcpTotalRxBytes             counterDesc
+	tcpTotalTxBytes             counterDesc
+	tcpTotalTxPackets           counterDesc
+	tcpTotalClientConnOpened    counterDesc
+	tcpTotalServerConnOpened    counterDesc
+	tcpTotalSyn                 counterDesc
+	tcpTotalSynProbe            counterDesc
+	tcpTotalServerFin           counterDesc
+	tcpTotalClientFin           counterDesc
 	tcpActiveServerConn         *prometheus.Desc
 	tcpCurClientConnEstablished *prometheus.Desc
 	tcpCurServerConnEstablished *prometheus.Desc
@@ -240,44 +256,44 @@ type Exporter struct {
 	tcpTxPacketsRate            *prometheus.Desc
 	tcpTxBytesRate              *prometheus.Desc
 	tcpClientConnOpenedRate     *prometheus.Desc
-	tcpErrBadChecksum           *prometheus.Desc
+	tcpErrBadChecksum           counterDesc
 	tcpErrBadChecksumRate       *prometheus.Desc
-	tcpErrAnyPortFail           *prometheus.Desc
-	tcpErrIPPortFail            *prometheus.Desc
-	tcpErrBadStateConn          *prometheus.Desc
-	tcpErrRstThreshold          *prometheus.Desc
+	tcpErrAnyPortFail           counterDesc
+	tcpErrIPPortFail            counterDesc
+	tcpErrBadStateConn          counterDesc
+	tcpErrRstThreshold          counterDesc
 	tcpSynRate                  *prometheus.Desc
 	tcpSynProbeRate             *prometheus.Desc
 
 	// Protocol IP metrics
-	ipTotalRxPackets          *prometheus.Desc
-	ipTotalRxBytes            *prometheus.Desc
-	ipTotalTxPackets          *prometheus.Desc
-	ipTotalTxBytes            *prometheus.Desc
-	ipTotalRxMbits            *prometheus.Desc
-	ipTotalTxMbits            *prometheus.Desc
-	ipTotalRoutedPackets      *prometheus.Desc
-	ipTotalRoutedMbits        *prometheus.Desc
-	ipTotalFragments          *prometheus.Desc
-	ipTotalSuccReassembly     *prometheus.Desc
-	ipTotalAddrLookup         *prometheus.Desc
-	ipTotalAddrLookupFail     *prometheus.Desc
-	ipTotalUDPFragmentsFwd    *prometheus.Desc
-	ipTotalTCPFragmentsFwd    *prometheus.Desc
-	ipTotalBadChecksums       *prometheus.Desc
-	ipTotalUnsuccReassembly   *prometheus.Desc
-	ipTotalTooBig             *prometheus.Desc
-	ipTotalDupFragments       *prometheus.Desc
-	ipTotalOutOfOrderFrag     *prometheus.Desc
-	ipTotalVIPDown            *prometheus.Desc
-	ipTotalTTLExpired         *prometheus.Desc
-	ipTotalMaxClients         *prometheus.Desc
-	ipTotalUnknownSvcs        *prometheus.Desc
-	ipTotalInvalidHeaderSz    *prometheus.Desc
-	ipTotalInvalidPacketSize  *prometheus.Desc
-	ipTotalTruncatedPackets   *prometheus.Desc
-	ipNonIPTotalTruncatedPkts *prometheus.Desc
-	ipTotalBadMacAddrs        *prometheus.Desc
+	ipTotalRxPackets          counterDesc
+	ipTotalRxBytes            counterDesc
+	ipTotalTxPackets          counterDesc
+	ipTotalTxBytes            counterDesc
+	ipTotalRxMbits            counterDesc
+	ipTotalTxMbits            counterDesc
+	ipTotalRoutedPackets      counterDesc
+	ipTotalRoutedMbits        counterDesc
+	ipTotalFragments          counterDesc
+	ipTotalSuccReassembly     counterDesc
+	ipTotalAddrLookup         counterDesc
+	ipTotalAddrLookupFail     counterDesc
+	ipTotalUDPFragmentsFwd    counterDesc
+	ipTotalTCPFragmentsFwd    counterDesc
+	ipTotalBadChecksums       counterDesc
+	ipTotalUnsuccReassembly   counterDesc
+	ipTotalTooBig             counterDesc
+	ipTotalDupFragments       counterDesc
+	ipTotalOutOfOrderFrag     counterDesc
+	ipTotalVIPDown            counterDesc
+	ipTotalTTLExpired         counterDesc
+	ipTotalMaxClients         counterDesc
+	ipTotalUnknownSvcs        counterDesc
+	ipTotalInvalidHeaderSz    counterDesc
+	ipTotalInvalidPacketSize  counterDesc
+	ipTotalTruncatedPackets   counterDesc
+	ipNonIPTotalTruncatedPkts counterDesc
+	ipTotalBadMacAddrs        counterDesc
 	ipRxPacketsRate           *prometheus.Desc
 	ipRxBytesRate             *prometheus.Desc
 	ipTxPacketsRate           *prometheus.Desc
@@ -288,13 +304,13 @@ type Exporter struct {
 	ipRoutedMbitsRate         *prometheus.Desc
 
 	// SSL global metrics
-	sslTotalTLSv11Sessions  *prometheus.Desc
-	sslTotalSSLv2Sessions   *prometheus.Desc
-	sslTotalSessions        *prometheus.Desc
-	sslTotalSSLv2Handshakes *prometheus.Desc
-	sslTotalEnc             *prometheus.Desc
+	sslTotalTLSv11Sessions  counterDesc
+	sslTotalSSLv2Sessions   counterDesc
+	sslTotalSessions        counterDesc
+	sslTotalSSLv2Handshakes counterDesc
+	sslTotalEnc             counterDesc
 	sslCryptoUtilization    *prometheus.Desc
-	sslTotalNewSessions     *prometheus.Desc
+	sslTotalNewSessions     counterDesc
 	sslSessionsRate         *prometheus.Desc
 	sslDecRate              *prometheus.Desc
 	sslEncRate              *prometheus.Desc
@@ -302,30 +318,30 @@ type Exporter struct {
 	sslNewSessionsRate      *prometheus.Desc
 
 	// SSL certificate metrics
-	sslCertDaysToExpire *prometheus.GaugeVec
+	sslCertDaysToExpire *prometheus.Desc
 
 	// SSL VServer metrics
-	sslVServerTotalDecBytes          *prometheus.GaugeVec
-	sslVServerTotalEncBytes          *prometheus.GaugeVec
-	sslVServerTotalHWDecBytes        *prometheus.GaugeVec
-	sslVServerTotalHWEncBytes        *prometheus.GaugeVec
-	sslVServerTotalSessionNew        *prometheus.GaugeVec
-	sslVServerTotalSessionHits       *prometheus.GaugeVec
-	sslVServerTotalClientAuthSuccess *prometheus.GaugeVec
-	sslVServerTotalClientAuthFailure *prometheus.GaugeVec
-	sslVServerHealth                 *prometheus.GaugeVec
-	sslVServerActiveServices         *prometheus.GaugeVec
-	sslVServerClientAuthSuccessRate  *prometheus.GaugeVec
-	sslVServerClientAuthFailureRate  *prometheus.GaugeVec
-	sslVServerEncBytesRate           *prometheus.GaugeVec
-	sslVServerDecBytesRate           *prometheus.GaugeVec
-	sslVServerHWEncBytesRate         *prometheus.GaugeVec
-	sslVServerHWDecBytesRate         *prometheus.GaugeVec
-	sslVServerSessionNewRate         *prometheus.GaugeVec
-	sslVServerSessionHitsRate        *prometheus.GaugeVec
+	sslVServerTotalDecBytes          counterDesc
+	sslVServerTotalEncBytes          counterDesc
+	sslVServerTotalHWDecBytes        counterDesc
+	sslVServerTotalHWEncBytes        counterDesc
+	sslVServerTotalSessionNew        counterDesc
+	sslVServerTotalSessionHits       counterDesc
+	sslVServerTotalClientAuthSuccess counterDesc
+	sslVServerTotalClientAuthFailure counterDesc
+	sslVServerHealth                 *prometheus.Desc
+	sslVServerActiveServices         *prometheus.Desc
+	sslVServerClientAuthSuccessRate  *prometheus.Desc
+	sslVServerClientAuthFailureRate  *prometheus.Desc
+	sslVServerEncBytesRate           *prometheus.Desc
+	sslVServerDecBytesRate           *prometheus.Desc
+	sslVServerHWEncBytesRate         *prometheus.Desc
+	sslVServerHWDecBytesRate         *prometheus.Desc
+	sslVServerSessionNewRate         *prometheus.Desc
+	sslVServerSessionHitsRate        *prometheus.Desc
 
 	// System CPU per-core metrics
-	cpuCoreUsage *prometheus.GaugeVec
+	cpuCoreUsage *prometheus.Desc
 
 	// Bandwidth capacity metrics
 	capacityMaxBandwidth    *prometheus.Desc
@@ -334,29 +350,49 @@ type Exporter struct {
 	capacityBandwidth       *prometheus.Desc
 
 	// MPS Health metrics
-	mpsHealthCPUUsage      *prometheus.GaugeVec
-	mpsHealthDiskUsage     *prometheus.GaugeVec
-	mpsHealthDiskFree      *prometheus.GaugeVec
-	mpsHealthDiskTotal     *prometheus.GaugeVec
-	mpsHealthDiskUsed      *prometheus.GaugeVec
-	mpsHealthMemoryUsage   *prometheus.GaugeVec
-	mpsHealthMemoryFree    *prometheus.GaugeVec
-	mpsHealthMemoryTotal   *prometheus.GaugeVec
+	mpsHealthCPUUsage    *prometheus.Desc
+	mpsHealthDiskUsage   *prometheus.Desc
+	mpsHealthDiskFree    *prometheus.Desc
+	mpsHealthDiskTotal   *prometheus.Desc
+	mpsHealthDiskUsed    *prometheus.Desc
+	mpsHealthMemoryUsage *prometheus.Desc
+	mpsHealthMemoryFree  *prometheus.Desc
+	mpsHealthMemoryTotal *prometheus.Desc
 
 	// HA (High Availability) metrics
-	haNodeState              *prometheus.GaugeVec // Per-node: 1=Primary, 0=Secondary
-	haNodeStatus             *prometheus.GaugeVec // Per-node: 1=UP, 0=DOWN
-	haNodeSyncState          *prometheus.GaugeVec // Per-node: 1=SUCCESS/ENABLED, 0=other
-	haNodeMasterStateSeconds *prometheus.GaugeVec // Per-node: seconds in current state
-	haCurState               *prometheus.Desc     // Global: 1=UP, 0=DOWN
-	haPacketsRxTotal         *prometheus.Desc     // Global: total packets received
-	haPacketsTxTotal         *prometheus.Desc     // Global: total packets transmitted
-	haSyncFailuresTotal      *prometheus.Desc     // Global: sync failure count
-	haPropTimeoutsTotal      *prometheus.Desc     // Global: propagation timeout count
+	haNodeState              *prometheus.Desc // Per-node: 1=Primary, 0=Secondary
+	haNodeStatus             *prometheus.Desc // Per-node: 1=UP, 0=DOWN
+	haNodeSyncState          *prometheus.Desc // Per-node: 1=SUCCESS/ENABLED, 0=other
+	haNodeMasterStateSeconds *prometheus.Desc // Per-node: seconds in current state
+	haCurState               *prometheus.Desc // Global: 1=UP, 0=DOWN
+	haPacketsRxTotal         *prometheus.Desc // Global: total packets received
+	haPacketsTxTotal         *prometheus.Desc // Global: total packets transmitted
+	haSyncFailuresTotal      *prometheus.Desc // Global: sync failure count
+	haPropTimeoutsTotal      *prometheus.Desc // Global: propagation timeout count
+
+	// Scrape health metrics
+	up             *prometheus.Desc
+	scrapeDuration *prometheus.Desc
+	scrapeSuccess  *prometheus.Desc
+	scrapeErrors   *prometheus.Desc
+	moduleEnabled  *prometheus.Desc
+
+	// Module circuit breaker metrics
+	moduleCircuitState *prometheus.Desc
+
+	// Call budget metrics
+	callBudgetRemaining *prometheus.Desc
+	modulesDeferred     *prometheus.Desc
+
+	// Snapshot metrics (background polling only)
+	snapshotAge     *prometheus.Desc
+	snapshotPartial *prometheus.Desc
 }
 
-// NewExporter initialises the exporter with the given configuration
-func NewExporter(cfg *config.Config, url, targetType, username, password string, ignoreCert bool, caFile string, parallelism int, logger *slog.Logger) (*Exporter, error) {
+// NewExporter initialises the exporter with the given configuration.
+// authMode selects how ADC targets are authenticated (see netscaler.NewAuthenticator);
+// creds are asked for on every login, so rotated credentials are picked up.
+func NewExporter(cfg *config.Config, url, targetType, authMode string, creds netscaler.Credentials, tls config.TLSConfig, parallelism int, logger *slog.Logger) (*Exporter, error) {
 	labelKeys := cfg.LabelKeys()
 
 	// Build base label names for different metric types
@@ -382,186 +418,193 @@ func NewExporter(cfg *config.Config, url, targetType, username, password string,
 	// HA-specific labels (node_id, node_name, node_ip for per-node metrics)
 	haNodeLabels := append(baseLabels, "node_id", "node_name", "node_ip")
 
+	// Scrape health labels
+	moduleLabels := append(baseLabels, "module")
+	scrapeErrorLabels := append(baseLabels, "module", "reason")
+
+	// Cumulative Nitro counters, with their schema 1 gauge names for -legacy-metric-names
+	counter := func(name, legacyName, help string, labels []string) counterDesc {
+		return newCounterDesc(name, legacyName, help, labels, cfg.LegacyMetricNames)
+	}
+
 	e := &Exporter{
 		config:      cfg,
 		url:         url,
 		targetType:  targetType,
-		username:    username,
-		password:    password,
-		ignoreCert:  ignoreCert,
-		caFile:      caFile,
+		authMode:    authMode,
+		tls:         tls,
 		parallelism: parallelism,
 		labelKeys:   labelKeys,
 		logger:      logger,
 
 		// System metrics (descriptors)
-		modelID:             prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "model_id"), "NetScaler model - reflects the bandwidth available", baseLabels, nil),
-		mgmtCPUUsage:        prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "mgmt_cpu_usage"), "Current CPU utilisation for management", baseLabels, nil),
-		memUsage:            prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "mem_usage"), "Current memory utilisation", baseLabels, nil),
-		pktCPUUsage:         prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "pkt_cpu_usage"), "Current CPU utilisation for packet engines", baseLabels, nil),
-		flashPartitionUsage: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "flash_partition_usage"), "Used space in /flash partition", baseLabels, nil),
-		varPartitionUsage:   prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "var_partition_usage"), "Used space in /var partition", baseLabels, nil),
-		totRxMB:             prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "total_received_mb"), "Total Megabytes received", baseLabels, nil),
-		totTxMB:             prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "total_transmit_mb"), "Total Megabytes transmitted", baseLabels, nil),
-		httpRequests:        prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "http_requests"), "Total HTTP requests received", baseLabels, nil),
-		httpResponses:       prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "http_responses"), "Total HTTP responses sent", baseLabels, nil),
+		modelID:                                prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "model_id"), "NetScaler model - reflects the bandwidth available", baseLabels, nil),
+		mgmtCPUUsage:                           prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "mgmt_cpu_usage"), "Current CPU utilisation for management", baseLabels, nil),
+		memUsage:                               prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "mem_usage"), "Current memory utilisation", baseLabels, nil),
+		pktCPUUsage:                            prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "pkt_cpu_usage"), "Current CPU utilisation for packet engines", baseLabels, nil),
+		flashPartitionUsage:                    prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "flash_partition_usage"), "Used space in /flash partition", baseLabels, nil),
+		varPartitionUsage:                      prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "var_partition_usage"), "Used space in /var partition", baseLabels, nil),
+		totRxBytes:                             newConvertedCounterDesc("received_bytes_total", "total_received_mb", "Total bytes received", "Total Megabits received", bytesPerMegabit, baseLabels, cfg.LegacyMetricNames),
+		totTxBytes:                             newConvertedCounterDesc("transmitted_bytes_total", "total_transmit_mb", "Total bytes transmitted", "Total Megabits transmitted", bytesPerMegabit, baseLabels, cfg.LegacyMetricNames),
+		httpRequests:                           counter("http_requests_received_total", "http_requests", "Total HTTP requests received", baseLabels),
+		httpResponses:                          counter("http_responses_sent_total", "http_responses", "Total HTTP responses sent", baseLabels),
 		tcpCurrentClientConnections:            prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "tcp_current_client_connections"), "Current client connections", baseLabels, nil),
 		tcpCurrentClientConnectionsEstablished: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "tcp_current_client_connections_established"), "Current established client connections", baseLabels, nil),
 		tcpCurrentServerConnections:            prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "tcp_current_server_connections"), "Current server connections", baseLabels, nil),
 		tcpCurrentServerConnectionsEstablished: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "tcp_current_server_connections_established"), "Current established server connections", baseLabels, nil),
 
 		// Interface metrics
-		interfacesRxBytes:        prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "interfaces_received_bytes", Help: "Bytes received by interface"}, ifLabels),
-		interfacesTxBytes:        prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "interfaces_transmitted_bytes", Help: "Bytes transmitted by interface"}, ifLabels),
-		interfacesRxPackets:      prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "interfaces_received_packets", Help: "Packets received by interface"}, ifLabels),
-		interfacesTxPackets:      prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "interfaces_transmitted_packets", Help: "Packets transmitted by interface"}, ifLabels),
-		interfacesJumboPacketsRx: prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "interfaces_jumbo_packets_received", Help: "Jumbo packets received by interface"}, ifLabels),
-		interfacesJumboPacketsTx: prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "interfaces_jumbo_packets_transmitted", Help: "Jumbo packets transmitted by interface"}, ifLabels),
-		interfacesErrorPacketsRx: prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "interfaces_error_packets_received", Help: "Error packets received by interface"}, ifLabels),
+		interfacesRxBytes:        counter("interfaces_received_bytes_total", "interfaces_received_bytes", "Bytes received by interface", ifLabels),
+		interfacesTxBytes:        counter("interfaces_transmitted_bytes_total", "interfaces_transmitted_bytes", "Bytes transmitted by interface", ifLabels),
+		interfacesRxPackets:      counter("interfaces_received_packets_total", "interfaces_received_packets", "Packets received by interface", ifLabels),
+		interfacesTxPackets:      counter("interfaces_transmitted_packets_total", "interfaces_transmitted_packets", "Packets transmitted by interface", ifLabels),
+		interfacesJumboPacketsRx: counter("interfaces_jumbo_packets_received_total", "interfaces_jumbo_packets_received", "Jumbo packets received by interface", ifLabels),
+		interfacesJumboPacketsTx: counter("interfaces_jumbo_packets_transmitted_total", "interfaces_jumbo_packets_transmitted", "Jumbo packets transmitted by interface", ifLabels),
+		interfacesErrorPacketsRx: counter("interfaces_error_packets_received_total", "interfaces_error_packets_received", "Error packets received by interface", ifLabels),
 
 		// Virtual Server metrics
-		virtualServersState:                    prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "virtual_servers_state", Help: "Current state of the server"}, vsLabels),
-		virtualServersWaitingRequests:          prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "virtual_servers_waiting_requests", Help: "Number of waiting requests"}, vsLabels),
-		virtualServersHealth:                   prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "virtual_servers_health", Help: "Percentage of UP services"}, vsLabels),
-		virtualServersInactiveServices:         prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "virtual_servers_inactive_services", Help: "Number of inactive services"}, vsLabels),
-		virtualServersActiveServices:           prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "virtual_servers_active_services", Help: "Number of active services"}, vsLabels),
-		virtualServersTotalHits:                prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "virtual_servers_total_hits", Help: "Total hits"}, vsLabels),
-		virtualServersTotalRequests:            prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "virtual_servers_total_requests", Help: "Total requests"}, vsLabels),
-		virtualServersTotalResponses:           prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "virtual_servers_total_responses", Help: "Total responses"}, vsLabels),
-		virtualServersTotalRequestBytes:        prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "virtual_servers_total_request_bytes", Help: "Total request bytes"}, vsLabels),
-		virtualServersTotalResponseBytes:       prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "virtual_servers_total_response_bytes", Help: "Total response bytes"}, vsLabels),
-		virtualServersCurrentClientConnections: prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "virtual_servers_current_client_connections", Help: "Current client connections"}, vsLabels),
-		virtualServersCurrentServerConnections: prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "virtual_servers_current_server_connections", Help: "Current server connections"}, vsLabels),
+		virtualServersState:                    prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "virtual_servers_state"), "Current state of the server", vsLabels, nil),
+		virtualServersWaitingRequests:          prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "virtual_servers_waiting_requests"), "Number of waiting requests", vsLabels, nil),
+		virtualServersHealth:                   prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "virtual_servers_health"), "Percentage of UP services", vsLabels, nil),
+		virtualServersInactiveServices:         prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "virtual_servers_inactive_services"), "Number of inactive services", vsLabels, nil),
+		virtualServersActiveServices:           prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "virtual_servers_active_services"), "Number of active services", vsLabels, nil),
+		virtualServersTotalHits:                counter("virtual_servers_hits_total", "virtual_servers_total_hits", "Total hits", vsLabels),
+		virtualServersTotalRequests:            counter("virtual_servers_requests_total", "virtual_servers_total_requests", "Total requests", vsLabels),
+		virtualServersTotalResponses:           counter("virtual_servers_responses_total", "virtual_servers_total_responses", "Total responses", vsLabels),
+		virtualServersTotalRequestBytes:        counter("virtual_servers_request_bytes_total", "virtual_servers_total_request_bytes", "Total request bytes", vsLabels),
+		virtualServersTotalResponseBytes:       counter("virtual_servers_response_bytes_total", "virtual_servers_total_response_bytes", "Total response bytes", vsLabels),
+		virtualServersCurrentClientConnections: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "virtual_servers_current_client_connections"), "Current client connections", vsLabels, nil),
+		virtualServersCurrentServerConnections: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "virtual_servers_current_server_connections"), "Current server connections", vsLabels, nil),
 
 		// Service metrics
-		servicesThroughput:                   prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "service_throughput", Help: "Throughput in Mbps"}, svcLabels),
-		servicesAvgTTFB:                      prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "service_average_time_to_first_byte", Help: "Average TTFB"}, svcLabels),
-		servicesState:                        prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "service_state", Help: "Current state"}, svcLabels),
-		servicesTotalRequests:                prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "service_total_requests", Help: "Total requests"}, svcLabels),
-		servicesTotalResponses:               prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "service_total_responses", Help: "Total responses"}, svcLabels),
-		servicesTotalRequestBytes:            prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "service_total_request_bytes", Help: "Total request bytes"}, svcLabels),
-		servicesTotalResponseBytes:           prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "service_total_response_bytes", Help: "Total response bytes"}, svcLabels),
-		servicesCurrentClientConns:           prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "service_current_client_connections", Help: "Current client connections"}, svcLabels),
-		servicesSurgeCount:                   prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "service_surge_count", Help: "Requests in surge queue"}, svcLabels),
-		servicesCurrentServerConns:           prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "service_current_server_connections", Help: "Current server connections"}, svcLabels),
-		servicesServerEstablishedConnections: prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "service_server_established_connections", Help: "Established server connections"}, svcLabels),
-		servicesCurrentReusePool:             prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "service_current_reuse_pool", Help: "Requests in reuse pool"}, svcLabels),
-		servicesMaxClients:                   prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "service_max_clients", Help: "Max open connections"}, svcLabels),
-		servicesCurrentLoad:                  prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "service_current_load", Help: "Current load"}, svcLabels),
-		servicesVirtualServerServiceHits:     prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "service_virtual_server_service_hits", Help: "Service hits"}, svcLabels),
-		servicesActiveTransactions:           prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "service_active_transactions", Help: "Active transactions"}, svcLabels),
+		servicesThroughput:                   prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "service_throughput"), "Throughput in Mbps", svcLabels, nil),
+		servicesAvgTTFB:                      prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "service_average_time_to_first_byte"), "Average TTFB", svcLabels, nil),
+		servicesState:                        prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "service_state"), "Current state", svcLabels, nil),
+		servicesTotalRequests:                counter("service_requests_total", "service_total_requests", "Total requests", svcLabels),
+		servicesTotalResponses:               counter("service_responses_total", "service_total_responses", "Total responses", svcLabels),
+		servicesTotalRequestBytes:            counter("service_request_bytes_total", "service_total_request_bytes", "Total request bytes", svcLabels),
+		servicesTotalResponseBytes:           counter("service_response_bytes_total", "service_total_response_bytes", "Total response bytes", svcLabels),
+		servicesCurrentClientConns:           prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "service_current_client_connections"), "Current client connections", svcLabels, nil),
+		servicesSurgeCount:                   prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "service_surge_count"), "Requests in surge queue", svcLabels, nil),
+		servicesCurrentServerConns:           prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "service_current_server_connections"), "Current server connections", svcLabels, nil),
+		servicesServerEstablishedConnections: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "service_server_established_connections"), "Established server connections", svcLabels, nil),
+		servicesCurrentReusePool:             prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "service_current_reuse_pool"), "Requests in reuse pool", svcLabels, nil),
+		servicesMaxClients:                   prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "service_max_clients"), "Max open connections", svcLabels, nil),
+		servicesCurrentLoad:                  prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "service_current_load"), "Current load", svcLabels, nil),
+		servicesVirtualServerServiceHits:     counter("service_virtual_server_service_hits_total", "service_virtual_server_service_hits", "Service hits", svcLabels),
+		servicesActiveTransactions:           prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "service_active_transactions"), "Active transactions", svcLabels, nil),
 
 		// Service Group metrics
-		serviceGroupsState:                        prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "servicegroup_state", Help: "Current state"}, sgLabels),
-		serviceGroupsAvgTTFB:                      prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "servicegroup_average_time_to_first_byte", Help: "Average TTFB"}, sgLabels),
-		serviceGroupsTotalRequests:                prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "servicegroup_total_requests", Help: "Total requests"}, sgLabels),
-		serviceGroupsTotalResponses:               prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "servicegroup_total_responses", Help: "Total responses"}, sgLabels),
-		serviceGroupsTotalRequestBytes:            prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "servicegroup_total_request_bytes", Help: "Total request bytes"}, sgLabels),
-		serviceGroupsTotalResponseBytes:           prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "servicegroup_total_response_bytes", Help: "Total response bytes"}, sgLabels),
-		serviceGroupsCurrentClientConnections:     prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "servicegroup_current_client_connections", Help: "Current client connections"}, sgLabels),
-		serviceGroupsSurgeCount:                   prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "servicegroup_surge_count", Help: "Requests in surge queue"}, sgLabels),
-		serviceGroupsCurrentServerConnections:     prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "servicegroup_current_server_connections", Help: "Current server connections"}, sgLabels),
-		serviceGroupsServerEstablishedConnections: prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "servicegroup_server_established_connections", Help: "Established server connections"}, sgLabels),
-		serviceGroupsCurrentReusePool:             prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "servicegroup_current_reuse_pool", Help: "Requests in reuse pool"}, sgLabels),
-		serviceGroupsMaxClients:                   prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "servicegroup_max_clients", Help: "Max open connections"}, sgLabels),
+		serviceGroupsState:                        prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "servicegroup_state"), "Current state", sgLabels, nil),
+		serviceGroupsAvgTTFB:                      prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "servicegroup_average_time_to_first_byte"), "Average TTFB", sgLabels, nil),
+		serviceGroupsTotalRequests:                counter("servicegroup_requests_total", "servicegroup_total_requests", "Total requests", sgLabels),
+		serviceGroupsTotalResponses:               counter("servicegroup_responses_total", "servicegroup_total_responses", "Total responses", sgLabels),
+		serviceGroupsTotalRequestBytes:            counter("servicegroup_request_bytes_total", "servicegroup_total_request_bytes", "Total request bytes", sgLabels),
+		serviceGroupsTotalResponseBytes:           counter("servicegroup_response_bytes_total", "servicegroup_total_response_bytes", "Total response bytes", sgLabels),
+		serviceGroupsCurrentClientConnections:     prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "servicegroup_current_client_connections"), "Current client connections", sgLabels, nil),
+		serviceGroupsSurgeCount:                   prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "servicegroup_surge_count"), "Requests in surge queue", sgLabels, nil),
+		serviceGroupsCurrentServerConnections:     prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "servicegroup_current_server_connections"), "Current server connections", sgLabels, nil),
+		serviceGroupsServerEstablishedConnections: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "servicegroup_server_established_connections"), "Established server connections", sgLabels, nil),
+		serviceGroupsCurrentReusePool:             prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "servicegroup_current_reuse_pool"), "Requests in reuse pool", sgLabels, nil),
+		serviceGroupsMaxClients:                   prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "servicegroup_max_clients"), "Max open connections", sgLabels, nil),
 
 		// GSLB Service metrics
-		gslbServicesState:                    prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "gslb_service_state", Help: "Current state"}, svcLabels),
-		gslbServicesTotalRequests:            prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "gslb_service_total_requests", Help: "Total requests"}, svcLabels),
-		gslbServicesTotalResponses:           prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "gslb_service_total_responses", Help: "Total responses"}, svcLabels),
-		gslbServicesTotalRequestBytes:        prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "gslb_service_total_request_bytes", Help: "Total request bytes"}, svcLabels),
-		gslbServicesTotalResponseBytes:       prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "gslb_service_total_response_bytes", Help: "Total response bytes"}, svcLabels),
-		gslbServicesCurrentClientConns:       prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "gslb_service_current_client_connections", Help: "Current client connections"}, svcLabels),
-		gslbServicesCurrentServerConns:       prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "gslb_service_current_server_connections", Help: "Current server connections"}, svcLabels),
-		gslbServicesCurrentLoad:              prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "gslb_service_current_load", Help: "Current load"}, svcLabels),
-		gslbServicesVirtualServerServiceHits: prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "gslb_service_virtual_server_service_hits", Help: "Service hits"}, svcLabels),
-		gslbServicesEstablishedConnections:   prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "gslb_service_established_connections", Help: "Established connections"}, svcLabels),
+		gslbServicesState:                    prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "gslb_service_state"), "Current state", svcLabels, nil),
+		gslbServicesTotalRequests:            counter("gslb_service_requests_total", "gslb_service_total_requests", "Total requests", svcLabels),
+		gslbServicesTotalResponses:           counter("gslb_service_responses_total", "gslb_service_total_responses", "Total responses", svcLabels),
+		gslbServicesTotalRequestBytes:        counter("gslb_service_request_bytes_total", "gslb_service_total_request_bytes", "Total request bytes", svcLabels),
+		gslbServicesTotalResponseBytes:       counter("gslb_service_response_bytes_total", "gslb_service_total_response_bytes", "Total response bytes", svcLabels),
+		gslbServicesCurrentClientConns:       prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "gslb_service_current_client_connections"), "Current client connections", svcLabels, nil),
+		gslbServicesCurrentServerConns:       prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "gslb_service_current_server_connections"), "Current server connections", svcLabels, nil),
+		gslbServicesCurrentLoad:              prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "gslb_service_current_load"), "Current load", svcLabels, nil),
+		gslbServicesVirtualServerServiceHits: counter("gslb_service_virtual_server_service_hits_total", "gslb_service_virtual_server_service_hits", "Service hits", svcLabels),
+		gslbServicesEstablishedConnections:   prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "gslb_service_established_connections"), "Established connections", svcLabels, nil),
 
 		// GSLB Virtual Server metrics
-		gslbVirtualServersState:                    prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "gslb_virtual_servers_state", Help: "Current state"}, vsLabels),
-		gslbVirtualServersHealth:                   prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "gslb_virtual_servers_health", Help: "Percentage of UP services"}, vsLabels),
-		gslbVirtualServersInactiveServices:         prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "gslb_virtual_servers_inactive_services", Help: "Inactive services"}, vsLabels),
-		gslbVirtualServersActiveServices:           prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "gslb_virtual_servers_active_services", Help: "Active services"}, vsLabels),
-		gslbVirtualServersTotalHits:                prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "gslb_virtual_servers_total_hits", Help: "Total hits"}, vsLabels),
-		gslbVirtualServersTotalRequests:            prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "gslb_virtual_servers_total_requests", Help: "Total requests"}, vsLabels),
-		gslbVirtualServersTotalResponses:           prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "gslb_virtual_servers_total_responses", Help: "Total responses"}, vsLabels),
-		gslbVirtualServersTotalRequestBytes:        prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "gslb_virtual_servers_total_request_bytes", Help: "Total request bytes"}, vsLabels),
-		gslbVirtualServersTotalResponseBytes:       prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "gslb_virtual_servers_total_response_bytes", Help: "Total response bytes"}, vsLabels),
-		gslbVirtualServersCurrentClientConnections: prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "gslb_virtual_servers_current_client_connections", Help: "Current client connections"}, vsLabels),
-		gslbVirtualServersCurrentServerConnections: prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "gslb_virtual_servers_current_server_connections", Help: "Current server connections"}, vsLabels),
+		gslbVirtualServersState:                    prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "gslb_virtual_servers_state"), "Current state", vsLabels, nil),
+		gslbVirtualServersHealth:                   prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "gslb_virtual_servers_health"), "Percentage of UP services", vsLabels, nil),
+		gslbVirtualServersInactiveServices:         prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "gslb_virtual_servers_inactive_services"), "Inactive services", vsLabels, nil),
+		gslbVirtualServersActiveServices:           prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "gslb_virtual_servers_active_services"), "Active services", vsLabels, nil),
+		gslbVirtualServersTotalHits:                counter("gslb_virtual_servers_hits_total", "gslb_virtual_servers_total_hits", "Total hits", vsLabels),
+		gslbVirtualServersTotalRequests:            counter("gslb_virtual_servers_requests_total", "gslb_virtual_servers_total_requests", "Total requests", vsLabels),
+		gslbVirtualServersTotalResponses:           counter("gslb_virtual_servers_responses_total", "gslb_virtual_servers_total_responses", "Total responses", vsLabels),
+		gslbVirtualServersTotalRequestBytes:        counter("gslb_virtual_servers_request_bytes_total", "gslb_virtual_servers_total_request_bytes", "Total request bytes", vsLabels),
+		gslbVirtualServersTotalResponseBytes:       counter("gslb_virtual_servers_response_bytes_total", "gslb_virtual_servers_total_response_bytes", "Total response bytes", vsLabels),
+		gslbVirtualServersCurrentClientConnections: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "gslb_virtual_servers_current_client_connections"), "Current client connections", vsLabels, nil),
+		gslbVirtualServersCurrentServerConnections: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "gslb_virtual_servers_current_server_connections"), "Current server connections", vsLabels, nil),
 
 		// CS Virtual Server metrics
-		csVirtualServersState:                               prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "cs_virtual_servers_state", Help: "Current state"}, vsLabels),
-		csVirtualServersTotalHits:                           prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "cs_virtual_servers_total_hits", Help: "Total hits"}, vsLabels),
-		csVirtualServersTotalRequests:                       prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "cs_virtual_servers_total_requests", Help: "Total requests"}, vsLabels),
-		csVirtualServersTotalResponses:                      prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "cs_virtual_servers_total_responses", Help: "Total responses"}, vsLabels),
-		csVirtualServersTotalRequestBytes:                   prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "cs_virtual_servers_total_request_bytes", Help: "Total request bytes"}, vsLabels),
-		csVirtualServersTotalResponseBytes:                  prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "cs_virtual_servers_total_response_bytes", Help: "Total response bytes"}, vsLabels),
-		csVirtualServersCurrentClientConnections:            prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "cs_virtual_servers_current_client_connections", Help: "Current client connections"}, vsLabels),
-		csVirtualServersCurrentServerConnections:            prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "cs_virtual_servers_current_server_connections", Help: "Current server connections"}, vsLabels),
-		csVirtualServersEstablishedConnections:              prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "cs_virtual_servers_established_connections", Help: "Established connections"}, vsLabels),
-		csVirtualServersTotalPacketsReceived:                prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "cs_virtual_servers_total_packets_received", Help: "Total packets received"}, vsLabels),
-		csVirtualServersTotalPacketsSent:                    prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "cs_virtual_servers_total_packets_sent", Help: "Total packets sent"}, vsLabels),
-		csVirtualServersTotalSpillovers:                     prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "cs_virtual_servers_total_spillovers", Help: "Total spillovers"}, vsLabels),
-		csVirtualServersDeferredRequests:                    prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "cs_virtual_servers_deferred_requests", Help: "Deferred requests"}, vsLabels),
-		csVirtualServersNumberInvalidRequestResponse:        prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "cs_virtual_servers_number_invalid_request_response", Help: "Invalid request/responses"}, vsLabels),
-		csVirtualServersNumberInvalidRequestResponseDropped: prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "cs_virtual_servers_number_invalid_request_response_dropped", Help: "Invalid request/responses dropped"}, vsLabels),
-		csVirtualServersTotalVServerDownBackupHits:          prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "cs_virtual_servers_total_vserver_down_backup_hits", Help: "Backup hits when vserver down"}, vsLabels),
-		csVirtualServersCurrentMultipathSessions:            prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "cs_virtual_servers_current_multipath_sessions", Help: "Current multipath TCP sessions"}, vsLabels),
-		csVirtualServersCurrentMultipathSubflows:            prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "cs_virtual_servers_current_multipath_subflows", Help: "Current multipath TCP subflows"}, vsLabels),
+		csVirtualServersState:                               prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "cs_virtual_servers_state"), "Current state", vsLabels, nil),
+		csVirtualServersTotalHits:                           counter("cs_virtual_servers_hits_total", "cs_virtual_servers_total_hits", "Total hits", vsLabels),
+		csVirtualServersTotalRequests:                       counter("cs_virtual_servers_requests_total", "cs_virtual_servers_total_requests", "Total requests", vsLabels),
+		csVirtualServersTotalResponses:                      counter("cs_virtual_servers_responses_total", "cs_virtual_servers_total_responses", "Total responses", vsLabels),
+		csVirtualServersTotalRequestBytes:                   counter("cs_virtual_servers_request_bytes_total", "cs_virtual_servers_total_request_bytes", "Total request bytes", vsLabels),
+		csVirtualServersTotalResponseBytes:                  counter("cs_virtual_servers_response_bytes_total", "cs_virtual_servers_total_response_bytes", "Total response bytes", vsLabels),
+		csVirtualServersCurrentClientConnections:            prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "cs_virtual_servers_current_client_connections"), "Current client connections", vsLabels, nil),
+		csVirtualServersCurrentServerConnections:            prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "cs_virtual_servers_current_server_connections"), "Current server connections", vsLabels, nil),
+		csVirtualServersEstablishedConnections:              prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "cs_virtual_servers_established_connections"), "Established connections", vsLabels, nil),
+		csVirtualServersTotalPacketsReceived:                counter("cs_virtual_servers_packets_received_total", "cs_virtual_servers_total_packets_received", "Total packets received", vsLabels),
+		csVirtualServersTotalPacketsSent:                    counter("cs_virtual_servers_packets_sent_total", "cs_virtual_servers_total_packets_sent", "Total packets sent", vsLabels),
+		csVirtualServersTotalSpillovers:                     counter("cs_virtual_servers_spillovers_total", "cs_virtual_servers_total_spillovers", "Total spillovers", vsLabels),
+		csVirtualServersDeferredRequests:                    counter("cs_virtual_servers_deferred_requests_total", "cs_virtual_servers_deferred_requests", "Deferred requests", vsLabels),
+		csVirtualServersNumberInvalidRequestResponse:        counter("cs_virtual_servers_invalid_request_responses_total", "cs_virtual_servers_number_invalid_request_response", "Invalid request/responses", vsLabels),
+		csVirtualServersNumberInvalidRequestResponseDropped: counter("cs_virtual_servers_invalid_request_responses_dropped_total", "cs_virtual_servers_number_invalid_request_response_dropped", "Invalid request/responses dropped", vsLabels),
+		csVirtualServersTotalVServerDownBackupHits:          counter("cs_virtual_servers_vserver_down_backup_hits_total", "cs_virtual_servers_total_vserver_down_backup_hits", "Backup hits when vserver down", vsLabels),
+		csVirtualServersCurrentMultipathSessions:            prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "cs_virtual_servers_current_multipath_sessions"), "Current multipath TCP sessions", vsLabels, nil),
+		csVirtualServersCurrentMultipathSubflows:            prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "cs_virtual_servers_current_multipath_subflows"), "Current multipath TCP subflows", vsLabels, nil),
 
 		// VPN Virtual Server metrics
-		vpnVirtualServersTotalRequests:      prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "vpn_virtual_servers_total_requests", Help: "Total requests"}, vpnVsLabels),
-		vpnVirtualServersTotalResponses:     prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "vpn_virtual_servers_total_responses", Help: "Total responses"}, vpnVsLabels),
-		vpnVirtualServersTotalRequestBytes:  prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "vpn_virtual_servers_total_request_bytes", Help: "Total request bytes"}, vpnVsLabels),
-		vpnVirtualServersTotalResponseBytes: prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "vpn_virtual_servers_total_response_bytes", Help: "Total response bytes"}, vpnVsLabels),
-		vpnVirtualServersState:              prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "vpn_virtual_servers_state", Help: "Current state"}, vpnVsLabels),
+		vpnVirtualServersTotalRequests:      counter("vpn_virtual_servers_requests_total", "vpn_virtual_servers_total_requests", "Total requests", vpnVsLabels),
+		vpnVirtualServersTotalResponses:     counter("vpn_virtual_servers_responses_total", "vpn_virtual_servers_total_responses", "Total responses", vpnVsLabels),
+		vpnVirtualServersTotalRequestBytes:  counter("vpn_virtual_servers_request_bytes_total", "vpn_virtual_servers_total_request_bytes", "Total request bytes", vpnVsLabels),
+		vpnVirtualServersTotalResponseBytes: counter("vpn_virtual_servers_response_bytes_total", "vpn_virtual_servers_total_response_bytes", "Total response bytes", vpnVsLabels),
+		vpnVirtualServersState:              prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "vpn_virtual_servers_state"), "Current state", vpnVsLabels, nil),
 
 		// AAA metrics
-		aaaAuthSuccess:         prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "aaa_auth_success", Help: "Authentication successes"}, baseLabels),
-		aaaAuthFail:            prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "aaa_auth_fail", Help: "Authentication failures"}, baseLabels),
-		aaaAuthOnlyHTTPSuccess: prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "aaa_auth_only_http_success", Help: "HTTP auth successes"}, baseLabels),
-		aaaAuthOnlyHTTPFail:    prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "aaa_auth_only_http_fail", Help: "HTTP auth failures"}, baseLabels),
-		aaaCurIcaSessions:      prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "aaa_current_ica_sessions", Help: "Current ICA sessions"}, baseLabels),
-		aaaCurIcaOnlyConn:      prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "aaa_current_ica_only_connections", Help: "Current ICA connections"}, baseLabels),
+		aaaAuthSuccess:         counter("aaa_auth_success_total", "aaa_auth_success", "Authentication successes", baseLabels),
+		aaaAuthFail:            counter("aaa_auth_fail_total", "aaa_auth_fail", "Authentication failures", baseLabels),
+		aaaAuthOnlyHTTPSuccess: counter("aaa_auth_only_http_success_total", "aaa_auth_only_http_success", "HTTP auth successes", baseLabels),
+		aaaAuthOnlyHTTPFail:    counter("aaa_auth_only_http_fail_total", "aaa_auth_only_http_fail", "HTTP auth failures", baseLabels),
+		aaaCurIcaSessions:      prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "aaa_current_ica_sessions"), "Current ICA sessions", baseLabels, nil),
+		aaaCurIcaOnlyConn:      prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "aaa_current_ica_only_connections"), "Current ICA connections", baseLabels, nil),
 
 		// Topology metrics
-		topologyNode: prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "topology_node", Help: "Node for topology visualization"}, topoNodeLabels),
-		topologyEdge: prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "topology_edge", Help: "Edge between frontend and backend"}, topoEdgeLabels),
+		topologyNode: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "topology_node"), "Node for topology visualization", topoNodeLabels, nil),
+		topologyEdge: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "topology_edge"), "Edge between frontend and backend", topoEdgeLabels, nil),
 
 		// Topology node stats (for node graph visualization)
-		topologyNodeState:         prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "topology_node_state", Help: "Node state (1=UP, 0=DOWN)"}, topoNodeStatsLabels),
-		topologyNodeHealth:        prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "topology_node_health", Help: "Node health percentage (0-100)"}, topoNodeStatsLabels),
-		topologyNodeRequestsTotal: prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "topology_node_requests_total", Help: "Total requests processed by node"}, topoNodeStatsLabels),
-		topologyNodeConnections:   prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "topology_node_connections", Help: "Current client connections to node"}, topoNodeStatsLabels),
-		topologyNodeTTFBMs:        prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "topology_node_ttfb_ms", Help: "Average time to first byte in milliseconds"}, topoNodeStatsLabels),
+		topologyNodeState:         prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "topology_node_state"), "Node state (1=UP, 0=DOWN)", topoNodeStatsLabels, nil),
+		topologyNodeHealth:        prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "topology_node_health"), "Node health percentage (0-100)", topoNodeStatsLabels, nil),
+		topologyNodeRequestsTotal: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "topology_node_requests_total"), "Total requests processed by node", topoNodeStatsLabels, nil),
+		topologyNodeConnections:   prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "topology_node_connections"), "Current client connections to node", topoNodeStatsLabels, nil),
+		topologyNodeTTFBMs:        prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "topology_node_ttfb_ms"), "Average time to first byte in milliseconds", topoNodeStatsLabels, nil),
 
 		// Protocol HTTP metrics
-		httpTotalRequests:              prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "http_requests_total"), "Total HTTP requests", baseLabels, nil),
-		httpTotalResponses:             prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "http_responses_total"), "Total HTTP responses", baseLabels, nil),
-		httpTotalPosts:                 prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "http_posts_total"), "Total HTTP POST requests", baseLabels, nil),
-		httpTotalGets:                  prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "http_gets_total"), "Total HTTP GET requests", baseLabels, nil),
-		httpTotalOthers:                prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "http_others_total"), "Total other HTTP requests", baseLabels, nil),
-		httpTotalRxRequestBytes:        prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "http_rx_request_bytes_total"), "Total HTTP request bytes received", baseLabels, nil),
-		httpTotalRxResponseBytes:       prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "http_rx_response_bytes_total"), "Total HTTP response bytes received", baseLabels, nil),
-		httpTotalTxRequestBytes:        prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "http_tx_request_bytes_total"), "Total HTTP request bytes transmitted", baseLabels, nil),
-		httpTotal10Requests:            prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "http_10_requests_total"), "Total HTTP/1.0 requests", baseLabels, nil),
-		httpTotal11Requests:            prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "http_11_requests_total"), "Total HTTP/1.1 requests", baseLabels, nil),
-		httpTotal10Responses:           prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "http_10_responses_total"), "Total HTTP/1.0 responses", baseLabels, nil),
-		httpTotal11Responses:           prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "http_11_responses_total"), "Total HTTP/1.1 responses", baseLabels, nil),
-		httpTotalChunkedRequests:       prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "http_chunked_requests_total"), "Total chunked HTTP requests", baseLabels, nil),
-		httpTotalChunkedResponses:      prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "http_chunked_responses_total"), "Total chunked HTTP responses", baseLabels, nil),
-		httpTotalSPDYStreams:           prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "http_spdy_streams_total"), "Total SPDY streams", baseLabels, nil),
-		httpTotalSPDYv2Streams:         prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "http_spdy_v2_streams_total"), "Total SPDY v2 streams", baseLabels, nil),
-		httpTotalSPDYv3Streams:         prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "http_spdy_v3_streams_total"), "Total SPDY v3 streams", baseLabels, nil),
-		httpErrNoReuseMultipart:        prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "http_err_noreuse_multipart_total"), "No-reuse multipart errors", baseLabels, nil),
-		httpErrIncompleteHeaders:       prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "http_err_incomplete_headers_total"), "Incomplete header errors", baseLabels, nil),
-		httpErrIncompleteRequests:      prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "http_err_incomplete_requests_total"), "Incomplete request errors", baseLabels, nil),
-		httpErrIncompleteResponses:     prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "http_err_incomplete_responses_total"), "Incomplete response errors", baseLabels, nil),
-		httpErrServerBusy:              prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "http_err_server_busy_total"), "Server busy errors", baseLabels, nil),
-		httpErrLargeContent:            prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "http_err_large_content_total"), "Large content errors", baseLabels, nil),
-		httpErrLargeChunk:              prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "http_err_large_chunk_total"), "Large chunk errors", baseLabels, nil),
-		httpErrLargeCtlen:              prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "http_err_large_ctlen_total"), "Large content-length errors", baseLabels, nil),
+		httpTotalRequests:              counter("http_requests_total", "", "Total HTTP requests", baseLabels),
+		httpTotalResponses:             counter("http_responses_total", "", "Total HTTP responses", baseLabels),
+		httpTotalPosts:                 counter("http_posts_total", "", "Total HTTP POST requests", baseLabels),
+		httpTotalGets:                  counter("http_gets_total", "", "Total HTTP GET requests", baseLabels),
+		httpTotalOthers:                counter("http_others_total", "", "Total other HTTP requests", baseLabels),
+		httpTotalRxRequestBytes:        counter("http_rx_request_bytes_total", "", "Total HTTP request bytes received", baseLabels),
+		httpTotalRxResponseBytes:       counter("http_rx_response_bytes_total", "", "Total HTTP response bytes received", baseLabels),
+		httpTotalTxRequestBytes:        counter("http_tx_request_bytes_total", "", "Total HTTP request bytes transmitted", baseLabels),
+		httpTotal10Requests:            counter("http_10_requests_total", "", "Total HTTP/1.0 requests", baseLabels),
+		httpTotal11Requests:            counter("http_11_requests_total", "", "Total HTTP/1.1 requests", baseLabels),
+		httpTotal10Responses:           counter("http_10_responses_total", "", "Total HTTP/1.0 responses", baseLabels),
+		httpTotal11Responses:           counter("http_11_responses_total", "", "Total HTTP/1.1 responses", baseLabels),
+		httpTotalChunkedRequests:       counter("http_chunked_requests_total", "", "Total chunked HTTP requests", baseLabels),
+		httpTotalChunkedResponses:      counter("http_chunked_responses_total", "", "Total chunked HTTP responses", baseLabels),
+		httpTotalSPDYStreams:           counter("http_spdy_streams_total", "", "Total SPDY streams", baseLabels),
+		httpTotalSPDYv2Streams:         counter("http_spdy_v2_streams_total", "", "Total SPDY v2 streams", baseLabels),
+		httpTotalSPDYv3Streams:         counter("http_spdy_v3_streams_total", "", "Total SPDY v3 streams", baseLabels),
+		httpErrNoReuseMultipart:        counter("http_err_noreuse_multipart_total", "", "No-reuse multipart errors", baseLabels),
+		httpErrIncompleteHeaders:       counter("http_err_incomplete_headers_total", "", "Incomplete header errors", baseLabels),
+		httpErrIncompleteRequests:      counter("http_err_incomplete_requests_total", "", "Incomplete request errors", baseLabels),
+		httpErrIncompleteResponses:     counter("http_err_incomplete_responses_total", "", "Incomplete response errors", baseLabels),
+		httpErrServerBusy:              counter("http_err_server_busy_total", "", "Server busy errors", baseLabels),
+		httpErrLargeContent:            counter("http_err_large_content_total", "", "Large content errors", baseLabels),
+		httpErrLargeChunk:              counter("http_err_large_chunk_total", "", "Large chunk errors", baseLabels),
+		httpErrLargeCtlen:              counter("http_err_large_ctlen_total", "", "Large content-length errors", baseLabels),
 		httpRequestsRate:               prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "http_requests_rate"), "HTTP requests rate", baseLabels, nil),
 		httpResponsesRate:              prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "http_responses_rate"), "HTTP responses rate", baseLabels, nil),
 		httpPostsRate:                  prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "http_posts_rate"), "HTTP POST rate", baseLabels, nil),
@@ -585,16 +628,16 @@ func NewExporter(cfg *config.Config, url, targetType, username, password string,
 		httpErrServerBusyRate:          prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "http_err_server_busy_rate"), "Server busy errors rate", baseLabels, nil),
 
 		// Protocol TCP metrics
-		tcpTotalRxPackets:           prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "tcp_rx_packets_total"), "Total TCP packets received", baseLabels, nil),
-		tcpTotalRxBytes:             prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "tcp_rx_bytes_total"), "Total TCP bytes received", baseLabels, nil),
-		tcpTotalTxBytes:             prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "tcp_tx_bytes_total"), "Total TCP bytes transmitted", baseLabels, nil),
-		tcpTotalTxPackets:           prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "tcp_tx_packets_total"), "Total TCP packets transmitted", baseLabels, nil),
-		tcpTotalClientConnOpened:    prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "tcp_client_connections_opened_total"), "Total TCP client connections opened", baseLabels, nil),
-		tcpTotalServerConnOpened:    prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "tcp_server_connections_opened_total"), "Total TCP server connections opened", baseLabels, nil),
-		tcpTotalSyn:                 prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "tcp_syn_total"), "Total TCP SYN packets", baseLabels, nil),
-		tcpTotalSynProbe:            prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "tcp_syn_probe_total"), "Total TCP SYN probe packets", baseLabels, nil),
-		tcpTotalServerFin:           prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "tcp_server_fin_total"), "Total TCP server FIN packets", baseLabels, nil),
-		tcpTotalClientFin:           prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "tcp_client_fin_total"), "Total TCP client FIN packets", baseLabels, nil),
+		tcpTotalRxPackets:           counter("tcp_rx_packets_total", "", "Total TCP packets received", baseLabels),
+		tcpTotalRxBytes:             counter("tcp_rx_bytes_total", "", "Total TCP bytes received", baseLabels),
+		tcpTotalTxBytes:             counter("tcp_tx_bytes_total", "", "Total TCP bytes transmitted", baseLabels),
+		tcpTotalTxPackets:           counter("tcp_tx_packets_total", "", "Total TCP packets transmitted", baseLabels),
+		tcpTotalClientConnOpened:    counter("tcp_client_connections_opened_total", "", "Total TCP client connections opened", baseLabels),
+		tcpTotalServerConnOpened:    counter("tcp_server_connections_opened_total", "", "Total TCP server connections opened", baseLabels),
+		tcpTotalSyn:                 counter("tcp_syn_total", "", "Total TCP SYN packets", baseLabels),
+		tcpTotalSynProbe:            counter("tcp_syn_probe_total", "", "Total TCP SYN probe packets", baseLabels),
+		tcpTotalServerFin:           counter("tcp_server_fin_total", "", "Total TCP server FIN packets", baseLabels),
+		tcpTotalClientFin:           counter("tcp_client_fin_total", "", "Total TCP client FIN packets", baseLabels),
 		tcpActiveServerConn:         prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "tcp_active_server_connections"), "Active TCP server connections", baseLabels, nil),
 		tcpCurClientConnEstablished: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "tcp_cur_client_connections_established"), "Current established client connections", baseLabels, nil),
 		tcpCurServerConnEstablished: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "tcp_cur_server_connections_established"), "Current established server connections", baseLabels, nil),
@@ -603,44 +646,44 @@ func NewExporter(cfg *config.Config, url, targetType, username, password string,
 		tcpTxPacketsRate:            prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "tcp_tx_packets_rate"), "TCP packets transmitted rate", baseLabels, nil),
 		tcpTxBytesRate:              prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "tcp_tx_bytes_rate"), "TCP bytes transmitted rate", baseLabels, nil),
 		tcpClientConnOpenedRate:     prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "tcp_client_connections_opened_rate"), "TCP client connections opened rate", baseLabels, nil),
-		tcpErrBadChecksum:           prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "tcp_err_bad_checksum_total"), "TCP bad checksum errors", baseLabels, nil),
+		tcpErrBadChecksum:           counter("tcp_err_bad_checksum_total", "", "TCP bad checksum errors", baseLabels),
 		tcpErrBadChecksumRate:       prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "tcp_err_bad_checksum_rate"), "TCP bad checksum errors rate", baseLabels, nil),
-		tcpErrAnyPortFail:           prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "tcp_err_any_port_fail"), "TCP any port fail errors", baseLabels, nil),
-		tcpErrIPPortFail:            prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "tcp_err_ip_port_fail"), "TCP IP port fail errors", baseLabels, nil),
-		tcpErrBadStateConn:          prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "tcp_err_bad_state_conn"), "TCP bad state connection errors", baseLabels, nil),
-		tcpErrRstThreshold:          prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "tcp_err_rst_threshold"), "TCP RST threshold errors", baseLabels, nil),
+		tcpErrAnyPortFail:           counter("tcp_err_any_port_fail_total", "tcp_err_any_port_fail", "TCP any port fail errors", baseLabels),
+		tcpErrIPPortFail:            counter("tcp_err_ip_port_fail_total", "tcp_err_ip_port_fail", "TCP IP port fail errors", baseLabels),
+		tcpErrBadStateConn:          counter("tcp_err_bad_state_conn_total", "tcp_err_bad_state_conn", "TCP bad state connection errors", baseLabels),
+		tcpErrRstThreshold:          counter("tcp_err_rst_threshold_total", "tcp_err_rst_threshold", "TCP RST threshold errors", baseLabels),
 		tcpSynRate:                  prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "tcp_syn_rate"), "TCP SYN rate", baseLabels, nil),
 		tcpSynProbeRate:             prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "tcp_syn_probe_rate"), "TCP SYN probe rate", baseLabels, nil),
 
 		// Protocol IP metrics
-		ipTotalRxPackets:          prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ip_rx_packets_total"), "Total IP packets received", baseLabels, nil),
-		ipTotalRxBytes:            prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ip_rx_bytes_total"), "Total IP bytes received", baseLabels, nil),
-		ipTotalTxPackets:          prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ip_tx_packets_total"), "Total IP packets transmitted", baseLabels, nil),
-		ipTotalTxBytes:            prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ip_tx_bytes_total"), "Total IP bytes transmitted", baseLabels, nil),
-		ipTotalRxMbits:            prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ip_rx_mbits_total"), "Total IP Mbits received", baseLabels, nil),
-		ipTotalTxMbits:            prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ip_tx_mbits_total"), "Total IP Mbits transmitted", baseLabels, nil),
-		ipTotalRoutedPackets:      prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ip_routed_packets_total"), "Total routed packets", baseLabels, nil),
-		ipTotalRoutedMbits:        prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ip_routed_mbits_total"), "Total routed Mbits", baseLabels, nil),
-		ipTotalFragments:          prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ip_fragments_total"), "Total IP fragments", baseLabels, nil),
-		ipTotalSuccReassembly:     prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ip_successful_reassembly_total"), "Total successful reassemblies", baseLabels, nil),
-		ipTotalAddrLookup:         prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ip_address_lookup_total"), "Total address lookups", baseLabels, nil),
-		ipTotalAddrLookupFail:     prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ip_address_lookup_fail_total"), "Total failed address lookups", baseLabels, nil),
-		ipTotalUDPFragmentsFwd:    prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ip_udp_fragments_fwd_total"), "Total UDP fragments forwarded", baseLabels, nil),
-		ipTotalTCPFragmentsFwd:    prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ip_tcp_fragments_fwd_total"), "Total TCP fragments forwarded", baseLabels, nil),
-		ipTotalBadChecksums:       prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ip_bad_checksums_total"), "Total bad checksums", baseLabels, nil),
-		ipTotalUnsuccReassembly:   prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ip_unsuccessful_reassembly_total"), "Total unsuccessful reassemblies", baseLabels, nil),
-		ipTotalTooBig:             prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ip_too_big_total"), "Total too big packets", baseLabels, nil),
-		ipTotalDupFragments:       prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ip_duplicate_fragments_total"), "Total duplicate fragments", baseLabels, nil),
-		ipTotalOutOfOrderFrag:     prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ip_out_of_order_fragments_total"), "Total out of order fragments", baseLabels, nil),
-		ipTotalVIPDown:            prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ip_vip_down_total"), "Total VIP down events", baseLabels, nil),
-		ipTotalTTLExpired:         prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ip_ttl_expired_total"), "Total TTL expired", baseLabels, nil),
-		ipTotalMaxClients:         prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ip_max_clients_total"), "Total max clients reached", baseLabels, nil),
-		ipTotalUnknownSvcs:        prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ip_unknown_services_total"), "Total unknown services", baseLabels, nil),
-		ipTotalInvalidHeaderSz:    prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ip_invalid_header_size_total"), "Total invalid header sizes", baseLabels, nil),
-		ipTotalInvalidPacketSize:  prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ip_invalid_packet_size_total"), "Total invalid packet sizes", baseLabels, nil),
-		ipTotalTruncatedPackets:   prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ip_truncated_packets_total"), "Total truncated packets", baseLabels, nil),
-		ipNonIPTotalTruncatedPkts: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ip_non_ip_truncated_packets_total"), "Total non-IP truncated packets", baseLabels, nil),
-		ipTotalBadMacAddrs:        prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ip_bad_mac_addresses_total"), "Total bad MAC addresses", baseLabels, nil),
+		ipTotalRxPackets:          counter("ip_rx_packets_total", "", "Total IP packets received", baseLabels),
+		ipTotalRxBytes:            counter("ip_rx_bytes_total", "", "Total IP bytes received", baseLabels),
+		ipTotalTxPackets:          counter("ip_tx_packets_total", "", "Total IP packets transmitted", baseLabels),
+		ipTotalTxBytes:            counter("ip_tx_bytes_total", "", "Total IP bytes transmitted", baseLabels),
+		ipTotalRxMbits:            counter("ip_rx_mbits_total", "", "Total IP Mbits received", baseLabels),
+		ipTotalTxMbits:            counter("ip_tx_mbits_total", "", "Total IP Mbits transmitted", baseLabels),
+		ipTotalRoutedPackets:      counter("ip_routed_packets_total", "", "Total routed packets", baseLabels),
+		ipTotalRoutedMbits:        counter("ip_routed_mbits_total", "", "Total routed Mbits", baseLabels),
+		ipTotalFragments:          counter("ip_fragments_total", "", "Total IP fragments", baseLabels),
+		ipTotalSuccReassembly:     counter("ip_successful_reassembly_total", "", "Total successful reassemblies", baseLabels),
+		ipTotalAddrLookup:         counter("ip_address_lookup_total", "", "Total address lookups", baseLabels),
+		ipTotalAddrLookupFail:     counter("ip_address_lookup_fail_total", "", "Total failed address lookups", baseLabels),
+		ipTotalUDPFragmentsFwd:    counter("ip_udp_fragments_fwd_total", "", "Total UDP fragments forwarded", baseLabels),
+		ipTotalTCPFragmentsFwd:    counter("ip_tcp_fragments_fwd_total", "", "Total TCP fragments forwarded", baseLabels),
+		ipTotalBadChecksums:       counter("ip_bad_checksums_total", "", "Total bad checksums", baseLabels),
+		ipTotalUnsuccReassembly:   counter("ip_unsuccessful_reassembly_total", "", "Total unsuccessful reassemblies", baseLabels),
+		ipTotalTooBig:             counter("ip_too_big_total", "", "Total too big packets", baseLabels),
+		ipTotalDupFragments:       counter("ip_duplicate_fragments_total", "", "Total duplicate fragments", baseLabels),
+		ipTotalOutOfOrderFrag:     counter("ip_out_of_order_fragments_total", "", "Total out of order fragments", baseLabels),
+		ipTotalVIPDown:            counter("ip_vip_down_total", "", "Total VIP down events", baseLabels),
+		ipTotalTTLExpired:         counter("ip_ttl_expired_total", "", "Total TTL expired", baseLabels),
+		ipTotalMaxClients:         counter("ip_max_clients_total", "", "Total max clients reached", baseLabels),
+		ipTotalUnknownSvcs:        counter("ip_unknown_services_total", "", "Total unknown services", baseLabels),
+		ipTotalInvalidHeaderSz:    counter("ip_invalid_header_size_total", "", "Total invalid header sizes", baseLabels),
+		ipTotalInvalidPacketSize:  counter("ip_invalid_packet_size_total", "", "Total invalid packet sizes", baseLabels),
+		ipTotalTruncatedPackets:   counter("ip_truncated_packets_total", "", "Total truncated packets", baseLabels),
+		ipNonIPTotalTruncatedPkts: counter("ip_non_ip_truncated_packets_total", "", "Total non-IP truncated packets", baseLabels),
+		ipTotalBadMacAddrs:        counter("ip_bad_mac_addresses_total", "", "Total bad MAC addresses", baseLabels),
 		ipRxPacketsRate:           prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ip_rx_packets_rate"), "IP packets received rate", baseLabels, nil),
 		ipRxBytesRate:             prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ip_rx_bytes_rate"), "IP bytes received rate", baseLabels, nil),
 		ipTxPacketsRate:           prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ip_tx_packets_rate"), "IP packets transmitted rate", baseLabels, nil),
@@ -651,13 +694,13 @@ func NewExporter(cfg *config.Config, url, targetType, username, password string,
 		ipRoutedMbitsRate:         prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ip_routed_mbits_rate"), "Routed Mbits rate", baseLabels, nil),
 
 		// SSL global metrics
-		sslTotalTLSv11Sessions:  prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ssl_tls11_sessions_total"), "Total TLS v1.1 sessions", baseLabels, nil),
-		sslTotalSSLv2Sessions:   prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ssl_v2_sessions_total"), "Total SSL v2 sessions", baseLabels, nil),
-		sslTotalSessions:        prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ssl_sessions_total"), "Total SSL sessions", baseLabels, nil),
-		sslTotalSSLv2Handshakes: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ssl_v2_handshakes_total"), "Total SSL v2 handshakes", baseLabels, nil),
-		sslTotalEnc:             prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ssl_encode_total"), "Total SSL encodes", baseLabels, nil),
+		sslTotalTLSv11Sessions:  counter("ssl_tls11_sessions_total", "", "Total TLS v1.1 sessions", baseLabels),
+		sslTotalSSLv2Sessions:   counter("ssl_v2_sessions_total", "", "Total SSL v2 sessions", baseLabels),
+		sslTotalSessions:        counter("ssl_sessions_total", "", "Total SSL sessions", baseLabels),
+		sslTotalSSLv2Handshakes: counter("ssl_v2_handshakes_total", "", "Total SSL v2 handshakes", baseLabels),
+		sslTotalEnc:             counter("ssl_encode_total", "", "Total SSL encodes", baseLabels),
 		sslCryptoUtilization:    prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ssl_crypto_utilization"), "SSL crypto utilization", baseLabels, nil),
-		sslTotalNewSessions:     prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ssl_new_sessions_total"), "Total new SSL sessions", baseLabels, nil),
+		sslTotalNewSessions:     counter("ssl_new_sessions_total", "", "Total new SSL sessions", baseLabels),
 		sslSessionsRate:         prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ssl_sessions_rate"), "SSL sessions rate", baseLabels, nil),
 		sslDecRate:              prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ssl_decode_rate"), "SSL decode rate", baseLabels, nil),
 		sslEncRate:              prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ssl_encode_rate"), "SSL encode rate", baseLabels, nil),
@@ -665,30 +708,30 @@ func NewExporter(cfg *config.Config, url, targetType, username, password string,
 		sslNewSessionsRate:      prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ssl_new_sessions_rate"), "New SSL sessions rate", baseLabels, nil),
 
 		// SSL certificate metrics
-		sslCertDaysToExpire: prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "ssl_cert_days_to_expire", Help: "Days until SSL certificate expires"}, sslCertLabels),
+		sslCertDaysToExpire: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ssl_cert_days_to_expire"), "Days until SSL certificate expires", sslCertLabels, nil),
 
 		// SSL VServer metrics
-		sslVServerTotalDecBytes:          prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "sslvserver_decrypt_bytes_total", Help: "Total bytes decrypted"}, sslVsLabels),
-		sslVServerTotalEncBytes:          prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "sslvserver_encrypt_bytes_total", Help: "Total bytes encrypted"}, sslVsLabels),
-		sslVServerTotalHWDecBytes:        prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "sslvserver_hw_decrypt_bytes_total", Help: "Total hardware decrypted bytes"}, sslVsLabels),
-		sslVServerTotalHWEncBytes:        prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "sslvserver_hw_encrypt_bytes_total", Help: "Total hardware encrypted bytes"}, sslVsLabels),
-		sslVServerTotalSessionNew:        prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "sslvserver_session_new_total", Help: "Total new sessions"}, sslVsLabels),
-		sslVServerTotalSessionHits:       prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "sslvserver_session_hits_total", Help: "Total session hits"}, sslVsLabels),
-		sslVServerTotalClientAuthSuccess: prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "sslvserver_client_auth_success_total", Help: "Total client auth successes"}, sslVsLabels),
-		sslVServerTotalClientAuthFailure: prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "sslvserver_client_auth_failure_total", Help: "Total client auth failures"}, sslVsLabels),
-		sslVServerHealth:                 prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "sslvserver_health", Help: "SSL vserver health"}, sslVsLabels),
-		sslVServerActiveServices:         prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "sslvserver_active_services", Help: "Active services"}, sslVsLabels),
-		sslVServerClientAuthSuccessRate:  prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "sslvserver_client_auth_success_rate", Help: "Client auth success rate"}, sslVsLabels),
-		sslVServerClientAuthFailureRate:  prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "sslvserver_client_auth_failure_rate", Help: "Client auth failure rate"}, sslVsLabels),
-		sslVServerEncBytesRate:           prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "sslvserver_encrypt_bytes_rate", Help: "Encrypt bytes rate"}, sslVsLabels),
-		sslVServerDecBytesRate:           prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "sslvserver_decrypt_bytes_rate", Help: "Decrypt bytes rate"}, sslVsLabels),
-		sslVServerHWEncBytesRate:         prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "sslvserver_hw_encrypt_bytes_rate", Help: "HW encrypt bytes rate"}, sslVsLabels),
-		sslVServerHWDecBytesRate:         prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "sslvserver_hw_decrypt_bytes_rate", Help: "HW decrypt bytes rate"}, sslVsLabels),
-		sslVServerSessionNewRate:         prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "sslvserver_session_new_rate", Help: "New session rate"}, sslVsLabels),
-		sslVServerSessionHitsRate:        prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "sslvserver_session_hits_rate", Help: "Session hits rate"}, sslVsLabels),
+		sslVServerTotalDecBytes:          counter("sslvserver_decrypt_bytes_total", "", "Total bytes decrypted", sslVsLabels),
+		sslVServerTotalEncBytes:          counter("sslvserver_encrypt_bytes_total", "", "Total bytes encrypted", sslVsLabels),
+		sslVServerTotalHWDecBytes:        counter("sslvserver_hw_decrypt_bytes_total", "", "Total hardware decrypted bytes", sslVsLabels),
+		sslVServerTotalHWEncBytes:        counter("sslvserver_hw_encrypt_bytes_total", "", "Total hardware encrypted bytes", sslVsLabels),
+		sslVServerTotalSessionNew:        counter("sslvserver_session_new_total", "", "Total new sessions", sslVsLabels),
+		sslVServerTotalSessionHits:       counter("sslvserver_session_hits_total", "", "Total session hits", sslVsLabels),
+		sslVServerTotalClientAuthSuccess: counter("sslvserver_client_auth_success_total", "", "Total client auth successes", sslVsLabels),
+		sslVServerTotalClientAuthFailure: counter("sslvserver_client_auth_failure_total", "", "Total client auth failures", sslVsLabels),
+		sslVServerHealth:                 prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "sslvserver_health"), "SSL vserver health", sslVsLabels, nil),
+		sslVServerActiveServices:         prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "sslvserver_active_services"), "Active services", sslVsLabels, nil),
+		sslVServerClientAuthSuccessRate:  prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "sslvserver_client_auth_success_rate"), "Client auth success rate", sslVsLabels, nil),
+		sslVServerClientAuthFailureRate:  prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "sslvserver_client_auth_failure_rate"), "Client auth failure rate", sslVsLabels, nil),
+		sslVServerEncBytesRate:           prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "sslvserver_encrypt_bytes_rate"), "Encrypt bytes rate", sslVsLabels, nil),
+		sslVServerDecBytesRate:           prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "sslvserver_decrypt_bytes_rate"), "Decrypt bytes rate", sslVsLabels, nil),
+		sslVServerHWEncBytesRate:         prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "sslvserver_hw_encrypt_bytes_rate"), "HW encrypt bytes rate", sslVsLabels, nil),
+		sslVServerHWDecBytesRate:         prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "sslvserver_hw_decrypt_bytes_rate"), "HW decrypt bytes rate", sslVsLabels, nil),
+		sslVServerSessionNewRate:         prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "sslvserver_session_new_rate"), "New session rate", sslVsLabels, nil),
+		sslVServerSessionHitsRate:        prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "sslvserver_session_hits_rate"), "Session hits rate", sslVsLabels, nil),
 
 		// System CPU per-core metrics
-		cpuCoreUsage: prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "cpu_core_usage_percent", Help: "CPU usage per core"}, cpuCoreLabels),
+		cpuCoreUsage: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "cpu_core_usage_percent"), "CPU usage per core", cpuCoreLabels, nil),
 
 		// Bandwidth capacity metrics
 		capacityMaxBandwidth:    prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "capacity_max_bandwidth"), "Maximum licensed bandwidth in Mbps", baseLabels, nil),
@@ -697,41 +740,64 @@ func NewExporter(cfg *config.Config, url, targetType, username, password string,
 		capacityBandwidth:       prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "capacity_allocated_bandwidth"), "Allocated licensed bandwidth in Mbps", baseLabels, nil),
 
 		// MPS Health metrics
-		mpsHealthCPUUsage:    prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "mps_health_cpu_usage", Help: "MPS CPU usage percentage"}, mpsHealthLabels),
-		mpsHealthDiskUsage:   prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "mps_health_disk_usage", Help: "MPS disk usage percentage"}, mpsHealthLabels),
-		mpsHealthDiskFree:    prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "mps_health_disk_free_bytes", Help: "MPS disk free space in bytes"}, mpsHealthLabels),
-		mpsHealthDiskTotal:   prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "mps_health_disk_total_bytes", Help: "MPS disk total space in bytes"}, mpsHealthLabels),
-		mpsHealthDiskUsed:    prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "mps_health_disk_used_bytes", Help: "MPS disk used space in bytes"}, mpsHealthLabels),
-		mpsHealthMemoryUsage: prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "mps_health_memory_usage", Help: "MPS memory usage percentage"}, mpsHealthLabels),
-		mpsHealthMemoryFree:  prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "mps_health_memory_free_bytes", Help: "MPS memory free in bytes"}, mpsHealthLabels),
-		mpsHealthMemoryTotal: prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "mps_health_memory_total_bytes", Help: "MPS memory total in bytes"}, mpsHealthLabels),
+		mpsHealthCPUUsage:    prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "mps_health_cpu_usage"), "MPS CPU usage percentage", mpsHealthLabels, nil),
+		mpsHealthDiskUsage:   prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "mps_health_disk_usage"), "MPS disk usage percentage", mpsHealthLabels, nil),
+		mpsHealthDiskFree:    prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "mps_health_disk_free_bytes"), "MPS disk free space in bytes", mpsHealthLabels, nil),
+		mpsHealthDiskTotal:   prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "mps_health_disk_total_bytes"), "MPS disk total space in bytes", mpsHealthLabels, nil),
+		mpsHealthDiskUsed:    prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "mps_health_disk_used_bytes"), "MPS disk used space in bytes", mpsHealthLabels, nil),
+		mpsHealthMemoryUsage: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "mps_health_memory_usage"), "MPS memory usage percentage", mpsHealthLabels, nil),
+		mpsHealthMemoryFree:  prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "mps_health_memory_free_bytes"), "MPS memory free in bytes", mpsHealthLabels, nil),
+		mpsHealthMemoryTotal: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "mps_health_memory_total_bytes"), "MPS memory total in bytes", mpsHealthLabels, nil),
 
 		// HA (High Availability) metrics - per-node
-		haNodeState:              prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "ha_node_state", Help: "HA node state (1=Primary, 0=Secondary)"}, haNodeLabels),
-		haNodeStatus:             prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "ha_node_status", Help: "HA node status (1=UP, 0=DOWN)"}, haNodeLabels),
-		haNodeSyncState:          prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "ha_node_sync_state", Help: "HA node sync state (1=SUCCESS/ENABLED, 0=other)"}, haNodeLabels),
-		haNodeMasterStateSeconds: prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "ha_node_master_state_seconds", Help: "Seconds in current master state"}, haNodeLabels),
+		haNodeState:              prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ha_node_state"), "HA node state (1=Primary, 0=Secondary)", haNodeLabels, nil),
+		haNodeStatus:             prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ha_node_status"), "HA node status (1=UP, 0=DOWN)", haNodeLabels, nil),
+		haNodeSyncState:          prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ha_node_sync_state"), "HA node sync state (1=SUCCESS/ENABLED, 0=other)", haNodeLabels, nil),
+		haNodeMasterStateSeconds: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ha_node_master_state_seconds"), "Seconds in current master state", haNodeLabels, nil),
 		// HA (High Availability) metrics - global stats
 		haCurState:          prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ha_cur_state"), "Current HA state (1=UP, 0=DOWN)", baseLabels, nil),
 		haPacketsRxTotal:    prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ha_packets_received_total"), "Total HA packets received", baseLabels, nil),
 		haPacketsTxTotal:    prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ha_packets_transmitted_total"), "Total HA packets transmitted", baseLabels, nil),
 		haSyncFailuresTotal: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ha_sync_failures_total"), "Total HA sync failures", baseLabels, nil),
 		haPropTimeoutsTotal: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "ha_propagation_timeouts_total"), "Total HA propagation timeouts", baseLabels, nil),
+		// Scrape health metrics
+		up:             prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "up"), "Whether the target's API could be scraped (1=at least one module succeeded)", baseLabels, nil),
+		scrapeDuration: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "scrape_duration_seconds"), "Duration of the module scrape in seconds", moduleLabels, nil),
+		scrapeSuccess:  prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "scrape_success"), "Whether the module scrape succeeded", moduleLabels, nil),
+		scrapeErrors:   prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "scrape_errors_total"), "Total module scrape errors by reason", scrapeErrorLabels, nil),
+		moduleEnabled:  prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "module_enabled"), "Whether the module is scraped, with the reason if it is not", scrapeErrorLabels, nil),
+		// Module circuit breaker metrics
+		moduleCircuitState: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "module_circuit_state"), "Circuit breaker state of the module (0=closed, 1=open, 2=half-open)", moduleLabels, nil),
+		// Call budget metrics
+		callBudgetRemaining: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "scrape_call_budget_remaining"), "API requests left in the call budget at the end of the scrape", baseLabels, nil),
+		modulesDeferred:     prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "modules_deferred_total"), "Total low-priority module scrapes deferred because the call budget was spent", moduleLabels, nil),
+		// Snapshot metrics
+		snapshotAge:     prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "snapshot_age_seconds"), "Seconds since the served snapshot was polled", baseLabels, nil),
+		snapshotPartial: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "snapshot_partial"), "Whether any module failed in the poll of the served snapshot", baseLabels, nil),
 	}
 
 	// Create persistent clients based on target type
 	if targetType == "adc" {
-		nsClient, err := netscaler.NewNitroClient(url, username, password, ignoreCert, caFile, logger)
+		if authMode == netscaler.AuthMTLS && tls.CertFile == "" {
+			return nil, errors.New("mtls authentication requires a client certificate")
+		}
+		auth, err := netscaler.NewAuthenticator(authMode, creds)
+		if err != nil {
+			return nil, err
+		}
+		nsClient, err := netscaler.NewNitroClient(url, auth, netscaler.TLSConfig(tls), logger)
 		if err != nil {
 			return nil, err
 		}
 		e.nsClient = nsClient
+		e.base = nsClient.BaseClient
 	} else if targetType == "mps" {
-		mpsClient, err := netscaler.NewMPSClient(url, username, password, ignoreCert, caFile, logger)
+		mpsClient, err := netscaler.NewMPSClient(url, creds, netscaler.TLSConfig(tls), logger)
 		if err != nil {
 			return nil, err
 		}
 		e.mpsClient = mpsClient
+		e.base = mpsClient.BaseClient
 	}
 
 	return e, nil
@@ -747,6 +813,87 @@ func (e *Exporter) buildLabelValues(extraLabels ...string) []string {
 	return values
 }
 
+// SetProxyInstance scrapes the ADM managed instance with the given IP through
+// the ADM API proxy. The exporter's URL and credentials must point at the ADM.
+func (e *Exporter) SetProxyInstance(ip string) {
+	if e.nsClient != nil {
+		e.nsClient.SetProxyInstance(ip)
+	}
+}
+
+// SetRateLimit limits the exporter's Nitro API requests to rate per second on
+// average, with bursts of up to burst requests. A rate of 0 removes the limit.
+func (e *Exporter) SetRateLimit(rate float64, burst int) {
+	if e.base != nil {
+		e.base.SetRateLimit(rate, burst)
+	}
+}
+
+// SetRetries retries API requests failing with a connection error or a
+// transient Nitro error up to retries times, with exponential backoff starting
+// at backoff. 0 disables retrying.
+func (e *Exporter) SetRetries(retries int, backoff time.Duration) {
+	if e.base != nil {
+		e.base.SetRetries(retries, backoff)
+	}
+}
+
+// SetRecording records the exporter's Nitro traffic below dir. An empty dir
+// records nothing.
+func (e *Exporter) SetRecording(dir string) {
+	if e.base != nil {
+		e.base.SetRecording(dir)
+	}
+}
+
+// SetReplay serves the exporter's Nitro requests from the recordings below
+// dir instead of calling the target.
+func (e *Exporter) SetReplay(dir string) {
+	if e.base != nil {
+		e.base.SetReplay(dir)
+	}
+}
+
+// SetPageSize fetches large collections in pages of size entries, using idle
+// parallelism to fetch pages concurrently. 0 fetches collections in one response.
+func (e *Exporter) SetPageSize(size int) {
+	if e.base != nil {
+		e.base.SetPageSize(size)
+	}
+}
+
+// SetCallBudget limits the Nitro API requests of a scrape. Low-priority modules
+// run last and are deferred once the budget is spent. 0 removes the limit.
+func (e *Exporter) SetCallBudget(calls int) {
+	e.callBudget = calls
+}
+
+// metricsTarget returns the target label of the exporter's API metrics.
+func (e *Exporter) metricsTarget() string {
+	if e.base == nil {
+		return ""
+	}
+	return e.base.MetricsTarget()
+}
+
+// Close logs out the exporter's client session and releases idle connections.
+func (e *Exporter) Close() {
+	if e.poller != nil {
+		e.poller.stop()
+	}
+	e.stopCapabilityDetection()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if e.base != nil {
+		if err := e.base.Logout(ctx); err != nil {
+			e.logger.Warn("failed to log out", "url", e.url, "err", err)
+		}
+		e.base.CloseIdleConnections()
+	}
+}
+
 // Describe implements Collector
 func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
 	ch <- e.modelID
@@ -755,155 +902,155 @@ func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
 	ch <- e.pktCPUUsage
 	ch <- e.flashPartitionUsage
 	ch <- e.varPartitionUsage
-	ch <- e.totRxMB
-	ch <- e.totTxMB
-	ch <- e.httpResponses
-	ch <- e.httpRequests
+	e.totRxBytes.describe(ch)
+	e.totTxBytes.describe(ch)
+	e.httpResponses.describe(ch)
+	e.httpRequests.describe(ch)
 	ch <- e.tcpCurrentClientConnections
 	ch <- e.tcpCurrentClientConnectionsEstablished
 	ch <- e.tcpCurrentServerConnections
 	ch <- e.tcpCurrentServerConnectionsEstablished
 
-	e.interfacesRxBytes.Describe(ch)
-	e.interfacesTxBytes.Describe(ch)
-	e.interfacesRxPackets.Describe(ch)
-	e.interfacesTxPackets.Describe(ch)
-	e.interfacesJumboPacketsRx.Describe(ch)
-	e.interfacesJumboPacketsTx.Describe(ch)
-	e.interfacesErrorPacketsRx.Describe(ch)
-
-	e.virtualServersState.Describe(ch)
-	e.virtualServersWaitingRequests.Describe(ch)
-	e.virtualServersHealth.Describe(ch)
-	e.virtualServersInactiveServices.Describe(ch)
-	e.virtualServersActiveServices.Describe(ch)
-	e.virtualServersTotalHits.Describe(ch)
-	e.virtualServersTotalRequests.Describe(ch)
-	e.virtualServersTotalResponses.Describe(ch)
-	e.virtualServersTotalRequestBytes.Describe(ch)
-	e.virtualServersTotalResponseBytes.Describe(ch)
-	e.virtualServersCurrentClientConnections.Describe(ch)
-	e.virtualServersCurrentServerConnections.Describe(ch)
-
-	e.servicesThroughput.Describe(ch)
-	e.servicesAvgTTFB.Describe(ch)
-	e.servicesState.Describe(ch)
-	e.servicesTotalRequests.Describe(ch)
-	e.servicesTotalResponses.Describe(ch)
-	e.servicesTotalRequestBytes.Describe(ch)
-	e.servicesTotalResponseBytes.Describe(ch)
-	e.servicesCurrentClientConns.Describe(ch)
-	e.servicesSurgeCount.Describe(ch)
-	e.servicesCurrentServerConns.Describe(ch)
-	e.servicesServerEstablishedConnections.Describe(ch)
-	e.servicesCurrentReusePool.Describe(ch)
-	e.servicesMaxClients.Describe(ch)
-	e.servicesCurrentLoad.Describe(ch)
-	e.servicesVirtualServerServiceHits.Describe(ch)
-	e.servicesActiveTransactions.Describe(ch)
-
-	e.serviceGroupsState.Describe(ch)
-	e.serviceGroupsAvgTTFB.Describe(ch)
-	e.serviceGroupsTotalRequests.Describe(ch)
-	e.serviceGroupsTotalResponses.Describe(ch)
-	e.serviceGroupsTotalRequestBytes.Describe(ch)
-	e.serviceGroupsTotalResponseBytes.Describe(ch)
-	e.serviceGroupsCurrentClientConnections.Describe(ch)
-	e.serviceGroupsSurgeCount.Describe(ch)
-	e.serviceGroupsCurrentServerConnections.Describe(ch)
-	e.serviceGroupsServerEstablishedConnections.Describe(ch)
-	e.serviceGroupsCurrentReusePool.Describe(ch)
-	e.serviceGroupsMaxClients.Describe(ch)
-
-	e.gslbServicesState.Describe(ch)
-	e.gslbServicesTotalRequests.Describe(ch)
-	e.gslbServicesTotalResponses.Describe(ch)
-	e.gslbServicesTotalRequestBytes.Describe(ch)
-	e.gslbServicesTotalResponseBytes.Describe(ch)
-	e.gslbServicesCurrentClientConns.Describe(ch)
-	e.gslbServicesCurrentServerConns.Describe(ch)
-	e.gslbServicesCurrentLoad.Describe(ch)
-	e.gslbServicesVirtualServerServiceHits.Describe(ch)
-	e.gslbServicesEstablishedConnections.Describe(ch)
-
-	e.gslbVirtualServersState.Describe(ch)
-	e.gslbVirtualServersHealth.Describe(ch)
-	e.gslbVirtualServersInactiveServices.Describe(ch)
-	e.gslbVirtualServersActiveServices.Describe(ch)
-	e.gslbVirtualServersTotalHits.Describe(ch)
-	e.gslbVirtualServersTotalRequests.Describe(ch)
-	e.gslbVirtualServersTotalResponses.Describe(ch)
-	e.gslbVirtualServersTotalRequestBytes.Describe(ch)
-	e.gslbVirtualServersTotalResponseBytes.Describe(ch)
-	e.gslbVirtualServersCurrentClientConnections.Describe(ch)
-	e.gslbVirtualServersCurrentServerConnections.Describe(ch)
-
-	e.csVirtualServersState.Describe(ch)
-	e.csVirtualServersTotalHits.Describe(ch)
-	e.csVirtualServersTotalRequests.Describe(ch)
-	e.csVirtualServersTotalResponses.Describe(ch)
-	e.csVirtualServersTotalRequestBytes.Describe(ch)
-	e.csVirtualServersTotalResponseBytes.Describe(ch)
-	e.csVirtualServersCurrentClientConnections.Describe(ch)
-	e.csVirtualServersCurrentServerConnections.Describe(ch)
-	e.csVirtualServersEstablishedConnections.Describe(ch)
-	e.csVirtualServersTotalPacketsReceived.Describe(ch)
-	e.csVirtualServersTotalPacketsSent.Describe(ch)
-	e.csVirtualServersTotalSpillovers.Describe(ch)
-	e.csVirtualServersDeferredRequests.Describe(ch)
-	e.csVirtualServersNumberInvalidRequestResponse.Describe(ch)
-	e.csVirtualServersNumberInvalidRequestResponseDropped.Describe(ch)
-	e.csVirtualServersTotalVServerDownBackupHits.Describe(ch)
-	e.csVirtualServersCurrentMultipathSessions.Describe(ch)
-	e.csVirtualServersCurrentMultipathSubflows.Describe(ch)
-
-	e.vpnVirtualServersTotalRequests.Describe(ch)
-	e.vpnVirtualServersTotalResponses.Describe(ch)
-	e.vpnVirtualServersTotalRequestBytes.Describe(ch)
-	e.vpnVirtualServersTotalResponseBytes.Describe(ch)
-	e.vpnVirtualServersState.Describe(ch)
-
-	e.aaaAuthSuccess.Describe(ch)
-	e.aaaAuthFail.Describe(ch)
-	e.aaaAuthOnlyHTTPSuccess.Describe(ch)
-	e.aaaAuthOnlyHTTPFail.Describe(ch)
-	e.aaaCurIcaSessions.Describe(ch)
-	e.aaaCurIcaOnlyConn.Describe(ch)
-
-	e.topologyNode.Describe(ch)
-	e.topologyEdge.Describe(ch)
-	e.topologyNodeState.Describe(ch)
-	e.topologyNodeHealth.Describe(ch)
-	e.topologyNodeRequestsTotal.Describe(ch)
-	e.topologyNodeConnections.Describe(ch)
-	e.topologyNodeTTFBMs.Describe(ch)
+	e.interfacesRxBytes.describe(ch)
+	e.interfacesTxBytes.describe(ch)
+	e.interfacesRxPackets.describe(ch)
+	e.interfacesTxPackets.describe(ch)
+	e.interfacesJumboPacketsRx.describe(ch)
+	e.interfacesJumboPacketsTx.describe(ch)
+	e.interfacesErrorPacketsRx.describe(ch)
+
+	ch <- e.virtualServersState
+	ch <- e.virtualServersWaitingRequests
+	ch <- e.virtualServersHealth
+	ch <- e.virtualServersInactiveServices
+	ch <- e.virtualServersActiveServices
+	e.virtualServersTotalHits.describe(ch)
+	e.virtualServersTotalRequests.describe(ch)
+	e.virtualServersTotalResponses.describe(ch)
+	e.virtualServersTotalRequestBytes.describe(ch)
+	e.virtualServersTotalResponseBytes.describe(ch)
+	ch <- e.virtualServersCurrentClientConnections
+	ch <- e.virtualServersCurrentServerConnections
+
+	ch <- e.servicesThroughput
+	ch <- e.servicesAvgTTFB
+	ch <- e.servicesState
+	e.servicesTotalRequests.describe(ch)
+	e.servicesTotalResponses.describe(ch)
+	e.servicesTotalRequestBytes.describe(ch)
+	e.servicesTotalResponseBytes.describe(ch)
+	ch <- e.servicesCurrentClientConns
+	ch <- e.servicesSurgeCount
+	ch <- e.servicesCurrentServerConns
+	ch <- e.servicesServerEstablishedConnections
+	ch <- e.servicesCurrentReusePool
+	ch <- e.servicesMaxClients
+	ch <- e.servicesCurrentLoad
+	e.servicesVirtualServerServiceHits.describe(ch)
+	ch <- e.servicesActiveTransactions
+
+	ch <- e.serviceGroupsState
+	ch <- e.serviceGroupsAvgTTFB
+	e.serviceGroupsTotalRequests.describe(ch)
+	e.serviceGroupsTotalResponses.describe(ch)
+	e.serviceGroupsTotalRequestBytes.describe(ch)
+	e.serviceGroupsTotalResponseBytes.describe(ch)
+	ch <- e.serviceGroupsCurrentClientConnections
+	ch <- e.serviceGroupsSurgeCount
+	ch <- e.serviceGroupsCurrentServerConnections
+	ch <- e.serviceGroupsServerEstablishedConnections
+	ch <- e.serviceGroupsCurrentReusePool
+	ch <- e.serviceGroupsMaxClients
+
+	ch <- e.gslbServicesState
+	e.gslbServicesTotalRequests.describe(ch)
+	e.gslbServicesTotalResponses.describe(ch)
+	e.gslbServicesTotalRequestBytes.describe(ch)
+	e.gslbServicesTotalResponseBytes.describe(ch)
+	ch <- e.gslbServicesCurrentClientConns
+	ch <- e.gslbServicesCurrentServerConns
+	ch <- e.gslbServicesCurrentLoad
+	e.gslbServicesVirtualServerServiceHits.describe(ch)
+	ch <- e.gslbServicesEstablishedConnections
+
+	ch <- e.gslbVirtualServersState
+	ch <- e.gslbVirtualServersHealth
+	ch <- e.gslbVirtualServersInactiveServices
+	ch <- e.gslbVirtualServersActiveServices
+	e.gslbVirtualServersTotalHits.describe(ch)
+	e.gslbVirtualServersTotalRequests.describe(ch)
+	e.gslbVirtualServersTotalResponses.describe(ch)
+	e.gslbVirtualServersTotalRequestBytes.describe(ch)
+	e.gslbVirtualServersTotalResponseBytes.describe(ch)
+	ch <- e.gslbVirtualServersCurrentClientConnections
+	ch <- e.gslbVirtualServersCurrentServerConnections
+
+	ch <- e.csVirtualServersState
+	e.csVirtualServersTotalHits.describe(ch)
+	e.csVirtualServersTotalRequests.describe(ch)
+	e.csVirtualServersTotalResponses.describe(ch)
+	e.csVirtualServersTotalRequestBytes.describe(ch)
+	e.csVirtualServersTotalResponseBytes.describe(ch)
+	ch <- e.csVirtualServersCurrentClientConnections
+	ch <- e.csVirtualServersCurrentServerConnections
+	ch <- e.csVirtualServersEstablishedConnections
+	e.csVirtualServersTotalPacketsReceived.describe(ch)
+	e.csVirtualServersTotalPacketsSent.describe(ch)
+	e.csVirtualServersTotalSpillovers.describe(ch)
+	e.csVirtualServersDeferredRequests.describe(ch)
+	e.csVirtualServersNumberInvalidRequestResponse.describe(ch)
+	e.csVirtualServersNumberInvalidRequestResponseDropped.describe(ch)
+	e.csVirtualServersTotalVServerDownBackupHits.describe(ch)
+	ch <- e.csVirtualServersCurrentMultipathSessions
+	ch <- e.csVirtualServersCurrentMultipathSubflows
+
+	e.vpnVirtualServersTotalRequests.describe(ch)
+	e.vpnVirtualServersTotalResponses.describe(ch)
+	e.vpnVirtualServersTotalRequestBytes.describe(ch)
+	e.vpnVirtualServersTotalResponseBytes.describe(ch)
+	ch <- e.vpnVirtualServersState
+
+	e.aaaAuthSuccess.describe(ch)
+	e.aaaAuthFail.describe(ch)
+	e.aaaAuthOnlyHTTPSuccess.describe(ch)
+	e.aaaAuthOnlyHTTPFail.describe(ch)
+	ch <- e.aaaCurIcaSessions
+	ch <- e.aaaCurIcaOnlyConn
+
+	ch <- e.topologyNode
+	ch <- e.topologyEdge
+	ch <- e.topologyNodeState
+	ch <- e.topologyNodeHealth
+	ch <- e.topologyNodeRequestsTotal
+	ch <- e.topologyNodeConnections
+	ch <- e.topologyNodeTTFBMs
 
 	// Protocol HTTP metrics
-	ch <- e.httpTotalRequests
-	ch <- e.httpTotalResponses
-	ch <- e.httpTotalPosts
-	ch <- e.httpTotalGets
-	ch <- e.httpTotalOthers
-	ch <- e.httpTotalRxRequestBytes
-	ch <- e.httpTotalRxResponseBytes
-	ch <- e.httpTotalTxRequestBytes
-	ch <- e.httpTotal10Requests
-	ch <- e.httpTotal11Requests
-	ch <- e.httpTotal10Responses
-	ch <- e.httpTotal11Responses
-	ch <- e.httpTotalChunkedRequests
-	ch <- e.httpTotalChunkedResponses
-	ch <- e.httpTotalSPDYStreams
-	ch <- e.httpTotalSPDYv2Streams
-	ch <- e.httpTotalSPDYv3Streams
-	ch <- e.httpErrNoReuseMultipart
-	ch <- e.httpErrIncompleteHeaders
-	ch <- e.httpErrIncompleteRequests
-	ch <- e.httpErrIncompleteResponses
-	ch <- e.httpErrServerBusy
-	ch <- e.httpErrLargeContent
-	ch <- e.httpErrLargeChunk
-	ch <- e.httpErrLargeCtlen
+	e.httpTotalRequests.describe(ch)
+	e.httpTotalResponses.describe(ch)
+	e.httpTotalPosts.describe(ch)
+	e.httpTotalGets.describe(ch)
+	e.httpTotalOthers.describe(ch)
+	e.httpTotalRxRequestBytes.describe(ch)
+	e.httpTotalRxResponseBytes.describe(ch)
+	e.httpTotalTxRequestBytes.describe(ch)
+	e.httpTotal10Requests.describe(ch)
+	e.httpTotal11Requests.describe(ch)
+	e.httpTotal10Responses.describe(ch)
+	e.httpTotal11Responses.describe(ch)
+	e.httpTotalChunkedRequests.describe(ch)
+	e.httpTotalChunkedResponses.describe(ch)
+	e.httpTotalSPDYStreams.describe(ch)
+	e.httpTotalSPDYv2Streams.describe(ch)
+	e.httpTotalSPDYv3Streams.describe(ch)
+	e.httpErrNoReuseMultipart.describe(ch)
+	e.httpErrIncompleteHeaders.describe(ch)
+	e.httpErrIncompleteRequests.describe(ch)
+	e.httpErrIncompleteResponses.describe(ch)
+	e.httpErrServerBusy.describe(ch)
+	e.httpErrLargeContent.describe(ch)
+	e.httpErrLargeChunk.describe(ch)
+	e.httpErrLargeCtlen.describe(ch)
 	ch <- e.httpRequestsRate
 	ch <- e.httpResponsesRate
 	ch <- e.httpPostsRate
@@ -927,16 +1074,16 @@ func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
 	ch <- e.httpErrServerBusyRate
 
 	// Protocol TCP metrics
-	ch <- e.tcpTotalRxPackets
-	ch <- e.tcpTotalRxBytes
-	ch <- e.tcpTotalTxBytes
-	ch <- e.tcpTotalTxPackets
-	ch <- e.tcpTotalClientConnOpened
-	ch <- e.tcpTotalServerConnOpened
-	ch <- e.tcpTotalSyn
-	ch <- e.tcpTotalSynProbe
-	ch <- e.tcpTotalServerFin
-	ch <- e.tcpTotalClientFin
+	e.tcpTotalRxPackets.describe(ch)
+	e.tcpTotalRxBytes.describe(ch)
+	e.tcpTotalTxBytes.describe(ch)
+	e.tcpTotalTxPackets.describe(ch)
+	e.tcpTotalClientConnOpened.describe(ch)
+	e.tcpTotalServerConnOpened.describe(ch)
+	e.tcpTotalSyn.describe(ch)
+	e.tcpTotalSynProbe.describe(ch)
+	e.tcpTotalServerFin.describe(ch)
+	e.tcpTotalClientFin.describe(ch)
 	ch <- e.tcpActiveServerConn
 	ch <- e.tcpCurClientConnEstablished
 	ch <- e.tcpCurServerConnEstablished
@@ -945,44 +1092,44 @@ func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
 	ch <- e.tcpTxPacketsRate
 	ch <- e.tcpTxBytesRate
 	ch <- e.tcpClientConnOpenedRate
-	ch <- e.tcpErrBadChecksum
+	e.tcpErrBadChecksum.describe(ch)
 	ch <- e.tcpErrBadChecksumRate
-	ch <- e.tcpErrAnyPortFail
-	ch <- e.tcpErrIPPortFail
-	ch <- e.tcpErrBadStateConn
-	ch <- e.tcpErrRstThreshold
+	e.tcpErrAnyPortFail.describe(ch)
+	e.tcpErrIPPortFail.describe(ch)
+	e.tcpErrBadStateConn.describe(ch)
+	e.tcpErrRstThreshold.describe(ch)
 	ch <- e.tcpSynRate
 	ch <- e.tcpSynProbeRate
 
 	// Protocol IP metrics
-	ch <- e.ipTotalRxPackets
-	ch <- e.ipTotalRxBytes
-	ch <- e.ipTotalTxPackets
-	ch <- e.ipTotalTxBytes
-	ch <- e.ipTotalRxMbits
-	ch <- e.ipTotalTxMbits
-	ch <- e.ipTotalRoutedPackets
-	ch <- e.ipTotalRoutedMbits
-	ch <- e.ipTotalFragments
-	ch <- e.ipTotalSuccReassembly
-	ch <- e.ipTotalAddrLookup
-	ch <- e.ipTotalAddrLookupFail
-	ch <- e.ipTotalUDPFragmentsFwd
-	ch <- e.ipTotalTCPFragmentsFwd
-	ch <- e.ipTotalBadChecksums
-	ch <- e.ipTotalUnsuccReassembly
-	ch <- e.ipTotalTooBig
-	ch <- e.ipTotalDupFragments
-	ch <- e.ipTotalOutOfOrderFrag
-	ch <- e.ipTotalVIPDown
-	ch <- e.ipTotalTTLExpired
-	ch <- e.ipTotalMaxClients
-	ch <- e.ipTotalUnknownSvcs
-	ch <- e.ipTotalInvalidHeaderSz
-	ch <- e.ipTotalInvalidPacketSize
-	ch <- e.ipTotalTruncatedPackets
-	ch <- e.ipNonIPTotalTruncatedPkts
-	ch <- e.ipTotalBadMacAddrs
+	e.ipTotalRxPackets.describe(ch)
+	e.ipTotalRxBytes.describe(ch)
+	e.ipTotalTxPackets.describe(ch)
+	e.ipTotalTxBytes.describe(ch)
+	e.ipTotalRxMbits.describe(ch)
+	e.ipTotalTxMbits.describe(ch)
+	e.ipTotalRoutedPackets.describe(ch)
+	e.ipTotalRoutedMbits.describe(ch)
+	e.ipTotalFragments.describe(ch)
+	e.ipTotalSuccReassembly.describe(ch)
+	e.ipTotalAddrLookup.describe(ch)
+	e.ipTotalAddrLookupFail.describe(ch)
+	e.ipTotalUDPFragmentsFwd.describe(ch)
+	e.ipTotalTCPFragmentsFwd.describe(ch)
+	e.ipTotalBadChecksums.describe(ch)
+	e.ipTotalUnsuccReassembly.describe(ch)
+	e.ipTotalTooBig.describe(ch)
+	e.ipTotalDupFragments.describe(ch)
+	e.ipTotalOutOfOrderFrag.describe(ch)
+	e.ipTotalVIPDown.describe(ch)
+	e.ipTotalTTLExpired.describe(ch)
+	e.ipTotalMaxClients.describe(ch)
+	e.ipTotalUnknownSvcs.describe(ch)
+	e.ipTotalInvalidHeaderSz.describe(ch)
+	e.ipTotalInvalidPacketSize.describe(ch)
+	e.ipTotalTruncatedPackets.describe(ch)
+	e.ipNonIPTotalTruncatedPkts.describe(ch)
+	e.ipTotalBadMacAddrs.describe(ch)
 	ch <- e.ipRxPacketsRate
 	ch <- e.ipRxBytesRate
 	ch <- e.ipTxPacketsRate
@@ -993,13 +1140,13 @@ func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
 	ch <- e.ipRoutedMbitsRate
 
 	// SSL global metrics
-	ch <- e.sslTotalTLSv11Sessions
-	ch <- e.sslTotalSSLv2Sessions
-	ch <- e.sslTotalSessions
-	ch <- e.sslTotalSSLv2Handshakes
-	ch <- e.sslTotalEnc
+	e.sslTotalTLSv11Sessions.describe(ch)
+	e.sslTotalSSLv2Sessions.describe(ch)
+	e.sslTotalSessions.describe(ch)
+	e.sslTotalSSLv2Handshakes.describe(ch)
+	e.sslTotalEnc.describe(ch)
 	ch <- e.sslCryptoUtilization
-	ch <- e.sslTotalNewSessions
+	e.sslTotalNewSessions.describe(ch)
 	ch <- e.sslSessionsRate
 	ch <- e.sslDecRate
 	ch <- e.sslEncRate
@@ -1007,30 +1154,30 @@ func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
 	ch <- e.sslNewSessionsRate
 
 	// SSL cert metrics
-	e.sslCertDaysToExpire.Describe(ch)
+	ch <- e.sslCertDaysToExpire
 
 	// SSL VServer metrics
-	e.sslVServerTotalDecBytes.Describe(ch)
-	e.sslVServerTotalEncBytes.Describe(ch)
-	e.sslVServerTotalHWDecBytes.Describe(ch)
-	e.sslVServerTotalHWEncBytes.Describe(ch)
-	e.sslVServerTotalSessionNew.Describe(ch)
-	e.sslVServerTotalSessionHits.Describe(ch)
-	e.sslVServerTotalClientAuthSuccess.Describe(ch)
-	e.sslVServerTotalClientAuthFailure.Describe(ch)
-	e.sslVServerHealth.Describe(ch)
-	e.sslVServerActiveServices.Describe(ch)
-	e.sslVServerClientAuthSuccessRate.Describe(ch)
-	e.sslVServerClientAuthFailureRate.Describe(ch)
-	e.sslVServerEncBytesRate.Describe(ch)
-	e.sslVServerDecBytesRate.Describe(ch)
-	e.sslVServerHWEncBytesRate.Describe(ch)
-	e.sslVServerHWDecBytesRate.Describe(ch)
-	e.sslVServerSessionNewRate.Describe(ch)
-	e.sslVServerSessionHitsRate.Describe(ch)
+	e.sslVServerTotalDecBytes.describe(ch)
+	e.sslVServerTotalEncBytes.describe(ch)
+	e.sslVServerTotalHWDecBytes.describe(ch)
+	e.sslVServerTotalHWEncBytes.describe(ch)
+	e.sslVServerTotalSessionNew.describe(ch)
+	e.sslVServerTotalSessionHits.describe(ch)
+	e.sslVServerTotalClientAuthSuccess.describe(ch)
+	e.sslVServerTotalClientAuthFailure.describe(ch)
+	ch <- e.sslVServerHealth
+	ch <- e.sslVServerActiveServices
+	ch <- e.sslVServerClientAuthSuccessRate
+	ch <- e.sslVServerClientAuthFailureRate
+	ch <- e.sslVServerEncBytesRate
+	ch <- e.sslVServerDecBytesRate
+	ch <- e.sslVServerHWEncBytesRate
+	ch <- e.sslVServerHWDecBytesRate
+	ch <- e.sslVServerSessionNewRate
+	ch <- e.sslVServerSessionHitsRate
 
 	// CPU core metrics
-	e.cpuCoreUsage.Describe(ch)
+	ch <- e.cpuCoreUsage
 
 	// Bandwidth capacity metrics
 	ch <- e.capacityMaxBandwidth
@@ -1039,23 +1186,35 @@ func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
 	ch <- e.capacityBandwidth
 
 	// MPS Health metrics
-	e.mpsHealthCPUUsage.Describe(ch)
-	e.mpsHealthDiskUsage.Describe(ch)
-	e.mpsHealthDiskFree.Describe(ch)
-	e.mpsHealthDiskTotal.Describe(ch)
-	e.mpsHealthDiskUsed.Describe(ch)
-	e.mpsHealthMemoryUsage.Describe(ch)
-	e.mpsHealthMemoryFree.Describe(ch)
-	e.mpsHealthMemoryTotal.Describe(ch)
+	ch <- e.mpsHealthCPUUsage
+	ch <- e.mpsHealthDiskUsage
+	ch <- e.mpsHealthDiskFree
+	ch <- e.mpsHealthDiskTotal
+	ch <- e.mpsHealthDiskUsed
+	ch <- e.mpsHealthMemoryUsage
+	ch <- e.mpsHealthMemoryFree
+	ch <- e.mpsHealthMemoryTotal
 
 	// HA metrics
-	e.haNodeState.Describe(ch)
-	e.haNodeStatus.Describe(ch)
-	e.haNodeSyncState.Describe(ch)
-	e.haNodeMasterStateSeconds.Describe(ch)
+	ch <- e.haNodeState
+	ch <- e.haNodeStatus
+	ch <- e.haNodeSyncState
+	ch <- e.haNodeMasterStateSeconds
 	ch <- e.haCurState
 	ch <- e.haPacketsRxTotal
 	ch <- e.haPacketsTxTotal
 	ch <- e.haSyncFailuresTotal
 	ch <- e.haPropTimeoutsTotal
+
+	// Scrape health metrics
+	ch <- e.up
+	ch <- e.scrapeDuration
+	ch <- e.scrapeSuccess
+	ch <- e.scrapeErrors
+	ch <- e.moduleEnabled
+	ch <- e.moduleCircuitState
+	ch <- e.callBudgetRemaining
+	ch <- e.modulesDeferred
+	ch <- e.snapshotAge
+	ch <- e.snapshotPartial
 }
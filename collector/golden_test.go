@@ -0,0 +1,183 @@
+package collector
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/elohmeier/netscaler-exporter/config"
+	"github.com/elohmeier/netscaler-exporter/netscaler/nitrotest"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata from the current output")
+
+// volatileMetrics vary between runs and are left out of the golden files.
+var volatileMetrics = map[string]bool{
+	"netscaler_scrape_duration_seconds": true,
+}
+
+// TestGolden scrapes canned Nitro responses and compares the exposition text
+// with testdata/<name>.golden. Run with -update after an intended change of
+// metric names, labels or values and review the diff.
+func TestGolden(t *testing.T) {
+	tests := []struct {
+		name       string
+		fixtures   string
+		targetType string
+		disabled   []string
+	}{
+		{name: "adc", fixtures: "adc.json", targetType: "adc"},
+		{name: "adc_disabled", fixtures: "adc.json", targetType: "adc", disabled: []string{
+			"topology", "interfaces", "gslb_services", "gslb_vservers", "vpn_vservers", "aaa_stats",
+			"protocol_http", "protocol_tcp", "protocol_ip", "ssl_vservers", "system_cpu",
+		}},
+		{name: "topology", fixtures: "adc.json", targetType: "adc", disabled: allModulesExcept("topology")},
+		{name: "mps", fixtures: "mps.json", targetType: "mps"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixtures, err := nitrotest.LoadFixtures(filepath.Join("testdata", tt.fixtures))
+			if err != nil {
+				t.Fatalf("LoadFixtures: %v", err)
+			}
+			srv := nitrotest.NewServer(fixtures)
+			defer srv.Close()
+
+			cfg := &config.Config{Labels: map[string]string{"env": "test"}, DisabledModules: tt.disabled}
+			got := exposition(t, newTestExporterOfType(t, srv.URL, tt.targetType, cfg))
+			golden := filepath.Join("testdata", tt.name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatalf("writing golden file: %v", err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("reading golden file (run with -update to create it): %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("output differs from %s (run with -update to accept):\n%s", golden, lineDiff(string(want), string(got)))
+			}
+		})
+	}
+}
+
+// dashboardLabels are the labels of the compose stack in dashboards/ that
+// share the metrics' prefix.
+var dashboardLabels = map[string]bool{"netscaler_cluster": true}
+
+// TestDashboardMetrics checks that the bundled dashboards query only metrics
+// the exporter publishes by default, as recorded in the golden files.
+func TestDashboardMetrics(t *testing.T) {
+	published := make(map[string]bool)
+	for name := range volatileMetrics {
+		published[name] = true
+	}
+	goldens, err := filepath.Glob(filepath.Join("testdata", "*.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, golden := range goldens {
+		data, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatalf("reading golden file: %v", err)
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if name, ok := strings.CutPrefix(line, "# TYPE "); ok {
+				published[strings.Fields(name)[0]] = true
+			}
+		}
+	}
+
+	dashboards, err := filepath.Glob(filepath.Join("..", "dashboards", "*.jsonnet"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	generated, err := filepath.Glob(filepath.Join("..", "dashboards", "config", "grafana", "provisioning", "dashboards", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dashboards) == 0 || len(generated) == 0 {
+		t.Fatal("no dashboards found")
+	}
+	metricName := regexp.MustCompile(`netscaler_[a-z0-9_]+`)
+	reported := make(map[string]bool)
+	for _, dashboard := range append(dashboards, generated...) {
+		data, err := os.ReadFile(dashboard)
+		if err != nil {
+			t.Fatalf("reading dashboard: %v", err)
+		}
+		for _, name := range metricName.FindAllString(string(data), -1) {
+			if !published[name] && !dashboardLabels[name] && !reported[name] {
+				t.Errorf("%s queries %s, which the exporter does not publish", filepath.Base(dashboard), name)
+				reported[name] = true
+			}
+		}
+	}
+}
+
+// exposition gathers c and renders it in the text exposition format, without
+// the volatile metrics.
+func exposition(t *testing.T, c prometheus.Collector) []byte {
+	t.Helper()
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(c)
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather failed: %v", err)
+	}
+	var buf bytes.Buffer
+	for _, mf := range families {
+		if volatileMetrics[mf.GetName()] {
+			continue
+		}
+		if _, err := expfmt.MetricFamilyToText(&buf, mf); err != nil {
+			t.Fatalf("formatting %s: %v", mf.GetName(), err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func allModulesExcept(module string) []string {
+	var disabled []string
+	for _, m := range config.ModuleNames {
+		if m != module {
+			disabled = append(disabled, m)
+		}
+	}
+	return disabled
+}
+
+// lineDiff lists the lines only in want (-) or only in got (+).
+func lineDiff(want, got string) string {
+	count := make(map[string]int)
+	for _, l := range strings.Split(want, "\n") {
+		count[l]++
+	}
+	for _, l := range strings.Split(got, "\n") {
+		count[l]--
+	}
+	var b strings.Builder
+	for _, l := range strings.Split(want, "\n") {
+		if count[l] > 0 {
+			b.WriteString("- " + l + "\n")
+			count[l]--
+		}
+	}
+	for _, l := range strings.Split(got, "\n") {
+		if count[l] < 0 {
+			b.WriteString("+ " + l + "\n")
+			count[l]++
+		}
+	}
+	return b.String()
+}
@@ -2,6 +2,7 @@ package collector
 
 import (
 	"context"
+	"errors"
 	"strconv"
 	"strings"
 
@@ -10,19 +11,13 @@ import (
 )
 
 // collectHAStats collects HA (High Availability) metrics from both config and stat endpoints
-func (e *Exporter) collectHAStats(ctx context.Context, nsClient *netscaler.NitroClient, ch chan<- prometheus.Metric) {
+func (e *Exporter) collectHAStats(ctx context.Context, nsClient netscaler.Client, ch chan<- prometheus.Metric, set *metricSet) error {
 	baseLabels := e.buildLabelValues()
 
-	// Reset GaugeVec metrics
-	e.haNodeState.Reset()
-	e.haNodeStatus.Reset()
-	e.haNodeSyncState.Reset()
-	e.haNodeMasterStateSeconds.Reset()
-
 	// Fetch HA node config (per-node info)
-	haConfig, err := netscaler.GetHANodeConfig(ctx, nsClient)
-	if err != nil {
-		e.logger.Error("failed to get HA node config", "url", e.url, "err", err)
+	haConfig, configErr := netscaler.GetHANodeConfig(ctx, nsClient)
+	if configErr != nil {
+		e.logger.Error("failed to get HA node config", "url", e.url, "err", configErr)
 	} else {
 		for _, node := range haConfig.HANodes {
 			labels := e.buildLabelValues(node.ID, node.Name, node.IPAddress)
@@ -32,38 +27,32 @@ func (e *Exporter) collectHAStats(ctx context.Context, nsClient *netscaler.Nitro
 			if strings.EqualFold(node.State, "Primary") {
 				state = 1.0
 			}
-			e.haNodeState.WithLabelValues(labels...).Set(state)
+			set.gauge(e.haNodeState, state, labels...)
 
 			// Status: 1=UP, 0=DOWN
 			status := 0.0
 			if strings.EqualFold(node.HAStatus, "UP") {
 				status = 1.0
 			}
-			e.haNodeStatus.WithLabelValues(labels...).Set(status)
+			set.gauge(e.haNodeStatus, status, labels...)
 
 			// Sync state: 1=SUCCESS or ENABLED, 0=other
 			syncState := 0.0
 			if strings.EqualFold(node.HASync, "SUCCESS") || strings.EqualFold(node.HASync, "ENABLED") {
 				syncState = 1.0
 			}
-			e.haNodeSyncState.WithLabelValues(labels...).Set(syncState)
+			set.gauge(e.haNodeSyncState, syncState, labels...)
 
 			// Master state time (seconds)
-			e.haNodeMasterStateSeconds.WithLabelValues(labels...).Set(float64(node.MasterStateTime))
+			set.gauge(e.haNodeMasterStateSeconds, float64(node.MasterStateTime), labels...)
 		}
 	}
 
-	// Collect GaugeVec metrics
-	e.haNodeState.Collect(ch)
-	e.haNodeStatus.Collect(ch)
-	e.haNodeSyncState.Collect(ch)
-	e.haNodeMasterStateSeconds.Collect(ch)
-
 	// Fetch HA node stats (global stats)
 	haStats, err := netscaler.GetHANodeStats(ctx, nsClient)
 	if err != nil {
 		e.logger.Error("failed to get HA node stats", "url", e.url, "err", err)
-		return
+		return errors.Join(configErr, err)
 	}
 
 	// Current state: 1=UP, 0=DOWN
@@ -88,4 +77,6 @@ func (e *Exporter) collectHAStats(ctx context.Context, nsClient *netscaler.Nitro
 	// Propagation timeouts total
 	propTimeouts, _ := strconv.ParseFloat(haStats.HANode.HAErrPropTimeout, 64)
 	ch <- prometheus.MustNewConstMetric(e.haPropTimeoutsTotal, prometheus.CounterValue, propTimeouts, baseLabels...)
+
+	return configErr
 }
@@ -0,0 +1,134 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/elohmeier/netscaler-exporter/netscaler"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// scrapeStatus counts module outcomes during one scrape.
+type scrapeStatus struct {
+	succeeded atomic.Int32
+	failed    atomic.Int32
+}
+
+// labelCounter counts events of an exporter by label values across scrapes.
+// Its counts are emitted as const metrics, like the other scrape metrics, so
+// they belong to the exporter and go away with it.
+type labelCounter struct {
+	mu     sync.Mutex
+	counts map[string]*labelCount
+}
+
+type labelCount struct {
+	labelValues []string
+	value       float64
+}
+
+// inc counts one event for the label values.
+func (c *labelCounter) inc(labelValues ...string) {
+	key := strings.Join(labelValues, "\xff")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts == nil {
+		c.counts = make(map[string]*labelCount)
+	}
+	if c.counts[key] == nil {
+		c.counts[key] = &labelCount{labelValues: labelValues}
+	}
+	c.counts[key].value++
+}
+
+// collect emits the counts as counters of desc, sorted by label values, with
+// the label values passed through buildLabels.
+func (c *labelCounter) collect(ch chan<- prometheus.Metric, desc *prometheus.Desc, buildLabels func(...string) []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	keys := make([]string, 0, len(c.counts))
+	for key := range c.counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		count := c.counts[key]
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, count.value, buildLabels(count.labelValues...)...)
+	}
+}
+
+// observeModule emits the duration and success metrics of a module scrape
+// and counts a failure by its reason.
+func (e *Exporter) observeModule(ch chan<- prometheus.Metric, status *scrapeStatus, module string, duration time.Duration, err error) {
+	labels := e.buildLabelValues(module)
+	success := 1.0
+	if err != nil {
+		success = 0
+		status.failed.Add(1)
+		reason := scrapeErrorReason(err)
+		if reason == "timeout" {
+			e.logger.Warn("module scrape timed out", "url", e.url, "module", module, "duration", duration, "timeout", e.config.ModuleTimeout(module))
+		}
+		e.scrapeErrorCounts.inc(module, reason)
+	} else {
+		status.succeeded.Add(1)
+	}
+	ch <- prometheus.MustNewConstMetric(e.scrapeDuration, prometheus.GaugeValue, duration.Seconds(), labels...)
+	ch <- prometheus.MustNewConstMetric(e.scrapeSuccess, prometheus.GaugeValue, success, labels...)
+}
+
+// collectScrapeHealth emits netscaler_up and the scrape error counters.
+// The target is up unless every module that ran failed.
+func (e *Exporter) collectScrapeHealth(ch chan<- prometheus.Metric, status *scrapeStatus) {
+	up := 1.0
+	if status.failed.Load() > 0 && status.succeeded.Load() == 0 {
+		up = 0
+	}
+	ch <- prometheus.MustNewConstMetric(e.up, prometheus.GaugeValue, up, e.buildLabelValues()...)
+	e.scrapeErrorCounts.collect(ch, e.scrapeErrors, e.buildLabelValues)
+}
+
+// scrapeErrorReason classifies a scrape error for the reason label.
+func scrapeErrorReason(err error) string {
+	var netErr net.Error
+	var urlErr *url.Error
+	var nitroErr *netscaler.NitroError
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.Is(err, netscaler.ErrCallBudgetExhausted):
+		return "budget"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case netscaler.IsAuthFailure(err):
+		return "auth"
+	case netscaler.IsPermissionDenied(err):
+		return "permission"
+	case netscaler.IsUnsupported(err):
+		return "unsupported"
+	case netscaler.IsNotFound(err):
+		return "not_found"
+	case netscaler.IsTransient(err):
+		return "transient"
+	case errors.As(err, &nitroErr):
+		return "http"
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		return "parse"
+	case errors.As(err, &urlErr), errors.As(err, &netErr):
+		return "network"
+	default:
+		return "other"
+	}
+}
@@ -0,0 +1,376 @@
+package collector
+
+import (
+	"errors"
+	"log/slog"
+	"net/url"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elohmeier/netscaler-exporter/config"
+	"github.com/elohmeier/netscaler-exporter/netscaler"
+)
+
+// ErrUnknownModule is returned by Manager.Get when the requested module is not configured.
+var ErrUnknownModule = errors.New("unknown module")
+
+// ErrTargetNotAllowed is returned by Manager.Get when the requested target is
+// neither configured nor matched by the allowed hosts (see SetAllowedHosts).
+var ErrTargetNotAllowed = errors.New("target not allowed")
+
+// ExporterFactory builds a new Exporter for the given resolved target.
+type ExporterFactory func(t config.Target) (*Exporter, error)
+
+// managedExporter is a cached Exporter together with the target it was built
+// from and its last use time.
+type managedExporter struct {
+	exporter *Exporter
+	target   config.Target
+	lastUsed time.Time
+	leases   int  // Get callers that have not released the exporter yet
+	retired  bool // removed from the cache, closed on the release of the last lease
+}
+
+// Manager caches one Exporter per target and module so that Nitro sessions
+// are kept alive between probes instead of logging in on every scrape.
+type Manager struct {
+	defaults config.Target
+	factory  ExporterFactory
+	ttl      time.Duration
+	logger   *slog.Logger
+
+	mu           sync.Mutex
+	modules      map[string]*config.Config
+	targets      map[string]config.Target
+	exporters    map[string]*managedExporter
+	allowedHosts []string
+}
+
+// NewManager creates a Manager serving the given modules and configured targets.
+// Probe targets that are not configured are rejected unless their host is
+// allowed (see SetAllowedHosts), and are then built from defaults.
+// Exporters of such ad-hoc targets that have not been probed for ttl are
+// closed and evicted (0 disables eviction).
+func NewManager(defaults config.Target, modules map[string]*config.Config, targets []config.Target, factory ExporterFactory, ttl time.Duration, logger *slog.Logger) *Manager {
+	m := &Manager{
+		defaults:  defaults,
+		factory:   factory,
+		ttl:       ttl,
+		logger:    logger,
+		exporters: make(map[string]*managedExporter),
+	}
+	m.Reload(modules, targets) // nothing to remove yet
+	return m
+}
+
+// Get returns the cached Exporter for the target and module, creating it if
+// necessary, and a function the caller must call once it no longer uses the
+// exporter. An exporter that is evicted or removed by Reload in the meantime
+// is closed when it is released.
+// The target is either the name of a configured target or a host name/URL.
+// An empty module selects the target's configured module, or the default module.
+func (m *Manager) Get(target, module string) (*Exporter, func(), error) {
+	m.mu.Lock()
+	t, err := m.resolveLocked(target, module)
+	if err != nil {
+		m.mu.Unlock()
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	evicted := m.evictLocked(now)
+
+	key := exporterKey(t)
+	me, ok := m.exporters[key]
+	if !ok {
+		me, err = m.createLocked(key, t, now)
+	}
+	if err == nil {
+		me.lastUsed = now
+		me.leases++
+	}
+	m.mu.Unlock()
+
+	m.CloseExporters(evicted)
+	if err != nil {
+		return nil, nil, err
+	}
+	var once sync.Once
+	return me.exporter, func() { once.Do(func() { m.release(me) }) }, nil
+}
+
+// release ends a lease handed out by Get and closes the exporter if it was
+// retired and this was its last lease.
+func (m *Manager) release(me *managedExporter) {
+	m.mu.Lock()
+	me.leases--
+	closing := me.retired && me.leases == 0
+	m.mu.Unlock()
+
+	if closing {
+		m.CloseExporters([]*Exporter{me.exporter})
+	}
+}
+
+// SetAllowedHosts lets /probe scrape targets that are not configured if their
+// host matches one of patterns (path.Match syntax, e.g. "*.example.com" or
+// "10.0.0.*"). Such targets are scraped with the default credentials.
+// Without patterns only configured targets are served.
+func (m *Manager) SetAllowedHosts(patterns []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.allowedHosts = patterns
+}
+
+// Targets returns the configured (and discovered) targets sorted by name.
+func (m *Manager) Targets() []config.Target {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	targets := make([]config.Target, 0, len(m.targets))
+	for _, t := range m.targets {
+		targets = append(targets, t)
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i].Name < targets[j].Name })
+	return targets
+}
+
+// TargetStatus lists the failing modules of a cached exporter.
+type TargetStatus struct {
+	Target  string         `json:"target"`
+	Module  string         `json:"module"`
+	Modules []ModuleStatus `json:"modules"`
+}
+
+// Status returns the failing modules of the cached exporters sorted by target.
+func (m *Manager) Status() []TargetStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	status := make([]TargetStatus, 0, len(m.exporters))
+	for _, me := range m.exporters {
+		id := me.target.Name
+		if id == "" {
+			id = me.target.URL
+		}
+		status = append(status, TargetStatus{Target: id, Module: me.target.Module, Modules: me.exporter.ModuleStatus()})
+	}
+	sort.Slice(status, func(i, j int) bool {
+		if status[i].Target != status[j].Target {
+			return status[i].Target < status[j].Target
+		}
+		return status[i].Module < status[j].Module
+	})
+	return status
+}
+
+// Reload replaces the modules and configured targets. Cached exporters whose
+// resolved target is unchanged keep their sessions, and exporters for new
+// targets are created. Exporters of removed or changed targets are returned
+// for the caller to close with CloseExporters, so that logging them out does
+// not hold up probes; those still in use by a probe are instead closed when it
+// releases them.
+func (m *Manager) Reload(modules map[string]*config.Config, targets []config.Target) (removed []*Exporter) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.modules = modules
+	m.targets = make(map[string]config.Target, len(targets))
+	for _, t := range targets {
+		m.targets[t.Name] = t
+	}
+
+	// Drop exporters whose target no longer resolves to the same settings
+	for key, me := range m.exporters {
+		id := me.target.Name
+		if id == "" {
+			id = me.target.URL
+		}
+		t, err := m.resolveLocked(id, me.target.Module)
+		if err == nil && exporterKey(t) == key && reflect.DeepEqual(t, me.target) {
+			continue
+		}
+		if err == nil && exporterKey(t) == key {
+			m.logger.Info("target changed, closing exporter", "target", id, "module", me.target.Module)
+		} else {
+			m.logger.Info("target removed, closing exporter", "target", id, "module", me.target.Module)
+		}
+		if e := m.retireLocked(key, me); e != nil {
+			removed = append(removed, e)
+		}
+	}
+
+	// Create exporters for configured targets that are not running yet
+	now := time.Now()
+	for _, t := range targets {
+		key := exporterKey(t)
+		if _, ok := m.exporters[key]; ok {
+			continue
+		}
+		if _, err := m.createLocked(key, t, now); err != nil {
+			m.logger.Error("failed to create exporter", "target", t.Name, "url", t.URL, "err", err)
+		}
+	}
+	return removed
+}
+
+// createLocked builds and caches a new Exporter. The caller must hold m.mu.
+func (m *Manager) createLocked(key string, t config.Target, now time.Time) (*managedExporter, error) {
+	exporter, err := m.factory(t)
+	if err != nil {
+		return nil, err
+	}
+	me := &managedExporter{exporter: exporter, target: t, lastUsed: now}
+	m.exporters[key] = me
+	m.logger.Info("created exporter for target", "target", t.Name, "url", t.URL, "module", t.Module)
+	return me, nil
+}
+
+// exporterKey identifies a cached exporter by module and target name (or URL for ad-hoc targets).
+func exporterKey(t config.Target) string {
+	id := t.Name
+	if id == "" {
+		id = t.URL
+	}
+	return t.Module + "|" + id
+}
+
+// resolveLocked looks up a configured target or builds an ad-hoc one from the defaults.
+// The caller must hold m.mu.
+func (m *Manager) resolveLocked(target, module string) (config.Target, error) {
+	if t, ok := m.targets[target]; ok {
+		if module == "" || module == t.Module {
+			return t, nil
+		}
+		cfg, ok := m.modules[module]
+		if !ok {
+			return config.Target{}, ErrUnknownModule
+		}
+		// Keep the target's own labels, take the collectors from the requested module
+		c := *cfg
+		c.Labels = t.Config.Labels
+		t.Module = module
+		t.Config = &c
+		return t, nil
+	}
+
+	if module == "" {
+		module = config.DefaultModule
+	}
+	cfg, ok := m.modules[module]
+	if !ok {
+		return config.Target{}, ErrUnknownModule
+	}
+	t := m.defaults
+	t.Name = ""
+	t.URL = NormalizeTargetURL(target)
+	if !m.hostAllowedLocked(t.URL) {
+		return config.Target{}, ErrTargetNotAllowed
+	}
+	t.Module = module
+	t.Config = cfg
+	return t, nil
+}
+
+// hostAllowedLocked reports whether the host of the ad-hoc target URL matches
+// an allowed host pattern, with or without its port. The caller must hold m.mu.
+func (m *Manager) hostAllowedLocked(targetURL string) bool {
+	u, err := url.Parse(targetURL)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	for _, pattern := range m.allowedHosts {
+		if ok, _ := path.Match(pattern, u.Hostname()); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, u.Host); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Close closes all cached exporters. Exporters still in use by a probe are
+// closed when it releases them.
+func (m *Manager) Close() {
+	m.mu.Lock()
+	var closing []*Exporter
+	for key, me := range m.exporters {
+		if e := m.retireLocked(key, me); e != nil {
+			closing = append(closing, e)
+		}
+	}
+	m.mu.Unlock()
+
+	m.CloseExporters(closing)
+}
+
+// retireLocked removes me from the cache. It returns the exporter if it is not
+// in use and can be closed right away, or nil if the release of its last lease
+// closes it. The caller must hold m.mu.
+func (m *Manager) retireLocked(key string, me *managedExporter) *Exporter {
+	delete(m.exporters, key)
+	me.retired = true
+	if me.leases > 0 {
+		return nil
+	}
+	return me.exporter
+}
+
+// evictLocked removes exporters of ad-hoc targets that have been idle for
+// longer than the TTL and returns those to close. Configured targets, and
+// their pollers, are kept until a reload removes them. The caller must hold m.mu.
+func (m *Manager) evictLocked(now time.Time) []*Exporter {
+	if m.ttl <= 0 {
+		return nil
+	}
+	var evicted []*Exporter
+	for key, me := range m.exporters {
+		if me.target.Name != "" || me.leases > 0 || now.Sub(me.lastUsed) <= m.ttl {
+			continue
+		}
+		m.logger.Info("evicting idle probe target", "url", me.exporter.url)
+		if e := m.retireLocked(key, me); e != nil {
+			evicted = append(evicted, e)
+		}
+	}
+	return evicted
+}
+
+// CloseExporters closes exporters removed from the cache, e.g. those returned
+// by Reload, and deletes the API metrics of their targets unless another
+// cached exporter scrapes the same target. It must be called without holding
+// m.mu, as closing logs out over the network.
+func (m *Manager) CloseExporters(exporters []*Exporter) {
+	for _, e := range exporters {
+		e.Close()
+
+		target := e.metricsTarget()
+		m.mu.Lock()
+		inUse := false
+		for _, me := range m.exporters {
+			if me.exporter.metricsTarget() == target {
+				inUse = true
+				break
+			}
+		}
+		m.mu.Unlock()
+		if !inUse {
+			netscaler.ForgetTarget(target)
+		}
+	}
+}
+
+// NormalizeTargetURL turns a probe target into a URL, defaulting to https
+// when only a host name (optionally with port) is given.
+func NormalizeTargetURL(target string) string {
+	target = strings.TrimSpace(target)
+	if !strings.Contains(target, "://") {
+		target = "https://" + target
+	}
+	return strings.TrimRight(target, "/")
+}
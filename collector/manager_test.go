@@ -0,0 +1,278 @@
+package collector
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/elohmeier/netscaler-exporter/config"
+	"github.com/elohmeier/netscaler-exporter/netscaler"
+	"github.com/elohmeier/netscaler-exporter/netscaler/nitrotest"
+)
+
+// newTestManager returns a Manager whose default module scrapes only ns_stats
+// and whose exporters log in to srv. created counts the exporters built.
+func newTestManager(t *testing.T, srv *nitrotest.Server, targets []config.Target, ttl time.Duration) (m *Manager, created func() int) {
+	t.Helper()
+	srv.SetCredentials("user", "pass")
+
+	cfg := &config.Config{Labels: map[string]string{}, DisabledModules: allModulesExcept("ns_stats")}
+	modules := map[string]*config.Config{config.DefaultModule: cfg}
+	defaults := config.Target{Type: "adc", Username: "user", Password: "pass", Module: config.DefaultModule, Config: cfg}
+	for i := range targets {
+		targets[i].Type = "adc"
+		targets[i].Username, targets[i].Password = "user", "pass"
+		targets[i].Module = config.DefaultModule
+		targets[i].Config = cfg
+	}
+
+	var mu sync.Mutex
+	n := 0
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	factory := func(t config.Target) (*Exporter, error) {
+		mu.Lock()
+		n++
+		mu.Unlock()
+		return NewExporter(t.Config, t.URL, t.Type, t.AuthMode, netscaler.StaticCredentials{Username: t.Username, Password: t.Password}, t.TLS, 4, logger)
+	}
+	m = NewManager(defaults, modules, targets, factory, ttl, logger)
+	t.Cleanup(m.Close)
+	return m, func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return n
+	}
+}
+
+// probe gathers e like a /probe request.
+func probe(t *testing.T, e *Exporter) error {
+	t.Helper()
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(e)
+	_, err := reg.Gather()
+	return err
+}
+
+// TestManagerAdHocTargets checks that only configured targets and hosts
+// matching the allowed patterns are served.
+func TestManagerAdHocTargets(t *testing.T) {
+	srv := newFakeNitro(t)
+	m, _ := newTestManager(t, srv, []config.Target{{Name: "adc1", URL: srv.URL}}, 0)
+
+	if _, _, err := m.Get("attacker.example", ""); !errors.Is(err, ErrTargetNotAllowed) {
+		t.Errorf("unlisted host: err = %v, want ErrTargetNotAllowed", err)
+	}
+	if _, _, err := m.Get(srv.URL, ""); !errors.Is(err, ErrTargetNotAllowed) {
+		t.Errorf("unlisted host of a configured target: err = %v, want ErrTargetNotAllowed", err)
+	}
+	if srv.Count("config/login") != 0 {
+		t.Errorf("logins = %d, want none for rejected targets", srv.Count("config/login"))
+	}
+
+	_, release, err := m.Get("adc1", "")
+	if err != nil {
+		t.Fatalf("configured target: %v", err)
+	}
+	release()
+	if _, _, err := m.Get("adc1", "unknown"); !errors.Is(err, ErrUnknownModule) {
+		t.Errorf("unknown module: err = %v, want ErrUnknownModule", err)
+	}
+
+	m.SetAllowedHosts([]string{"*.example.com", "127.0.0.1"})
+	for _, target := range []string{"adc2.example.com", srv.URL} {
+		_, release, err := m.Get(target, "")
+		if err != nil {
+			t.Errorf("allowed host %s: %v", target, err)
+			continue
+		}
+		release()
+	}
+	if _, _, err := m.Get("example.com.attacker.example", ""); !errors.Is(err, ErrTargetNotAllowed) {
+		t.Errorf("host not matching the patterns: err = %v, want ErrTargetNotAllowed", err)
+	}
+}
+
+// TestManagerLease checks that an exporter removed by a reload or evicted is
+// only logged out once the probe using it has released it.
+func TestManagerLease(t *testing.T) {
+	srv := newFakeNitro(t)
+	targets := []config.Target{{Name: "adc1", URL: srv.URL}}
+	m, _ := newTestManager(t, srv, targets, time.Millisecond)
+	m.SetAllowedHosts([]string{"127.0.0.1"})
+	target := targets[0]
+
+	e, release, err := m.Get("adc1", "")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if err := probe(t, e); err != nil {
+		t.Fatalf("gather failed: %v", err)
+	}
+	if removed := m.Reload(m.modules, nil); len(removed) != 0 {
+		t.Fatalf("reload returned %d exporters to close while in use, want 0", len(removed))
+	}
+	if n := srv.Count("config/logout"); n != 0 {
+		t.Fatalf("logouts = %d while the exporter is in use, want 0", n)
+	}
+	if err := probe(t, e); err != nil {
+		t.Fatalf("gather after reload failed: %v", err)
+	}
+	release()
+	release()
+	if n := srv.Count("config/logout"); n != 1 {
+		t.Fatalf("logouts = %d after release, want 1", n)
+	}
+
+	// Ad-hoc targets are evicted once idle, configured targets are not
+	m.Reload(m.modules, []config.Target{target})
+	for _, target := range []string{"adc1", srv.URL} {
+		e, release, err := m.Get(target, "")
+		if err != nil {
+			t.Fatalf("Get %s: %v", target, err)
+		}
+		if err := probe(t, e); err != nil {
+			t.Fatalf("gather failed: %v", err)
+		}
+		release()
+	}
+	time.Sleep(5 * time.Millisecond)
+	_, release, err = m.Get("adc1", "")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	release()
+
+	m.mu.Lock()
+	var keys []string
+	for key := range m.exporters {
+		keys = append(keys, key)
+	}
+	m.mu.Unlock()
+	if len(keys) != 1 || keys[0] != config.DefaultModule+"|adc1" {
+		t.Errorf("cached exporters = %v, want only the configured target", keys)
+	}
+	if n := srv.Count("config/logout"); n != 2 {
+		t.Errorf("logouts = %d, want 2 after the eviction", n)
+	}
+}
+
+// TestManagerConcurrency probes while targets are reloaded and evicted. Run
+// with -race. An exporter closed while in use would log in again, so every
+// exporter must have logged in once and out once in the end.
+func TestManagerConcurrency(t *testing.T) {
+	srv := newFakeNitro(t)
+	targets := []config.Target{{Name: "adc1", URL: srv.URL}, {Name: "adc2", URL: srv.URL}}
+	m, created := newTestManager(t, srv, targets, time.Millisecond)
+	m.SetAllowedHosts([]string{"127.0.0.1"})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 10 {
+				target := []string{"adc1", "adc2", srv.URL}[i%3]
+				e, release, err := m.Get(target, "")
+				if errors.Is(err, ErrTargetNotAllowed) {
+					continue // removed by the reload
+				}
+				if err != nil {
+					errs <- err
+					return
+				}
+				if err := probe(t, e); err != nil {
+					errs <- err
+				}
+				release()
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := range 20 {
+			m.CloseExporters(m.Reload(m.modules, targets[:i%3]))
+		}
+	}()
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("probe failed: %v", err)
+	}
+
+	m.Close()
+	if logins, logouts, n := srv.Count("config/login"), srv.Count("config/logout"), created(); logins > n || logouts != logins {
+		t.Errorf("%d exporters logged in %d times and out %d times, want at most once each and as often", n, logins, logouts)
+	}
+}
+
+// TestManagerForgetsMetrics checks that the API metrics of a target are
+// deleted once its last exporter is evicted or removed.
+func TestManagerForgetsMetrics(t *testing.T) {
+	srv := newFakeNitro(t)
+	// The configured target reaches srv under another host than ad-hoc probes
+	configured := strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)
+	m, _ := newTestManager(t, srv, []config.Target{{Name: "adc1", URL: configured}}, time.Millisecond)
+	m.SetAllowedHosts([]string{"127.0.0.1"})
+
+	series := func() map[string]int {
+		t.Helper()
+		reg := prometheus.NewPedanticRegistry()
+		if err := netscaler.RegisterMetrics(reg); err != nil {
+			t.Fatalf("RegisterMetrics: %v", err)
+		}
+		families, err := reg.Gather()
+		if err != nil {
+			t.Fatalf("gather failed: %v", err)
+		}
+		byTarget := make(map[string]int)
+		for _, mf := range families {
+			for _, metric := range mf.GetMetric() {
+				for _, lp := range metric.GetLabel() {
+					if lp.GetName() == "target" {
+						byTarget[lp.GetValue()]++
+					}
+				}
+			}
+		}
+		return byTarget
+	}
+	adHoc := strings.TrimPrefix(srv.URL, "http://")
+	configuredHost := strings.TrimPrefix(configured, "http://")
+
+	for _, target := range []string{"adc1", srv.URL} {
+		e, release, err := m.Get(target, "")
+		if err != nil {
+			t.Fatalf("Get %s: %v", target, err)
+		}
+		if err := probe(t, e); err != nil {
+			t.Fatalf("gather failed: %v", err)
+		}
+		release()
+	}
+	if s := series(); s[adHoc] == 0 || s[configuredHost] == 0 {
+		t.Fatalf("series per target = %v, want some for both targets", s)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	_, release, err := m.Get("adc1", "") // evicts the ad-hoc target
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	release()
+	if s := series(); s[adHoc] != 0 || s[configuredHost] == 0 {
+		t.Errorf("series per target after eviction = %v, want none of %s only", s, adHoc)
+	}
+
+	m.CloseExporters(m.Reload(m.modules, nil))
+	if s := series(); s[configuredHost] != 0 {
+		t.Errorf("series per target after removal = %v, want none of %s", s, configuredHost)
+	}
+}
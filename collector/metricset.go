@@ -0,0 +1,55 @@
+package collector
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metricSet collects the labelled metrics of a single scrape. Setting the same
+// metric and label values twice keeps the last value, as a GaugeVec would, but
+// a set belongs to one scrape only, so overlapping scrapes never share state.
+type metricSet struct {
+	mu      sync.Mutex
+	metrics map[string]prometheus.Metric
+	order   []string
+}
+
+func newMetricSet() *metricSet {
+	return &metricSet{metrics: make(map[string]prometheus.Metric)}
+}
+
+// gauge sets a gauge value for the given label values.
+func (s *metricSet) gauge(desc *prometheus.Desc, value float64, labelValues ...string) {
+	s.add(desc, prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, labelValues...), labelValues)
+}
+
+// counter sets a counter value, and its legacy gauge if enabled, for the
+// given label values.
+func (s *metricSet) counter(c counterDesc, value float64, labelValues ...string) {
+	s.add(c.desc, prometheus.MustNewConstMetric(c.desc, prometheus.CounterValue, value, labelValues...), labelValues)
+	if c.legacy != nil {
+		s.add(c.legacy, prometheus.MustNewConstMetric(c.legacy, prometheus.GaugeValue, c.legacyValue(value), labelValues...), labelValues)
+	}
+}
+
+func (s *metricSet) add(desc *prometheus.Desc, m prometheus.Metric, labelValues []string) {
+	key := desc.String() + "\xff" + strings.Join(labelValues, "\xff")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.metrics[key]; !ok {
+		s.order = append(s.order, key)
+	}
+	s.metrics[key] = m
+}
+
+// collect sends all metrics of the set.
+func (s *metricSet) collect(ch chan<- prometheus.Metric) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range s.order {
+		ch <- s.metrics[key]
+	}
+}
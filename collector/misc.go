@@ -7,108 +7,95 @@ import (
 )
 
 // Interface collectors
-func (e *Exporter) collectInterfacesRxBytes(ns netscaler.NSAPIResponse) {
-	e.interfacesRxBytes.Reset()
+func (e *Exporter) collectInterfacesRxBytes(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, iface := range ns.InterfaceStats {
 		val, _ := strconv.ParseFloat(iface.TotalReceivedBytes, 64)
 		labels := e.buildLabelValues(iface.ID, iface.Alias)
-		e.interfacesRxBytes.WithLabelValues(labels...).Set(val)
+		set.counter(e.interfacesRxBytes, val, labels...)
 	}
 }
 
-func (e *Exporter) collectInterfacesTxBytes(ns netscaler.NSAPIResponse) {
-	e.interfacesTxBytes.Reset()
+func (e *Exporter) collectInterfacesTxBytes(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, iface := range ns.InterfaceStats {
 		val, _ := strconv.ParseFloat(iface.TotalTransmitBytes, 64)
 		labels := e.buildLabelValues(iface.ID, iface.Alias)
-		e.interfacesTxBytes.WithLabelValues(labels...).Set(val)
+		set.counter(e.interfacesTxBytes, val, labels...)
 	}
 }
 
-func (e *Exporter) collectInterfacesRxPackets(ns netscaler.NSAPIResponse) {
-	e.interfacesRxPackets.Reset()
+func (e *Exporter) collectInterfacesRxPackets(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, iface := range ns.InterfaceStats {
 		val, _ := strconv.ParseFloat(iface.TotalReceivedPackets, 64)
 		labels := e.buildLabelValues(iface.ID, iface.Alias)
-		e.interfacesRxPackets.WithLabelValues(labels...).Set(val)
+		set.counter(e.interfacesRxPackets, val, labels...)
 	}
 }
 
-func (e *Exporter) collectInterfacesTxPackets(ns netscaler.NSAPIResponse) {
-	e.interfacesTxPackets.Reset()
+func (e *Exporter) collectInterfacesTxPackets(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, iface := range ns.InterfaceStats {
 		val, _ := strconv.ParseFloat(iface.TotalTransmitPackets, 64)
 		labels := e.buildLabelValues(iface.ID, iface.Alias)
-		e.interfacesTxPackets.WithLabelValues(labels...).Set(val)
+		set.counter(e.interfacesTxPackets, val, labels...)
 	}
 }
 
-func (e *Exporter) collectInterfacesJumboPacketsRx(ns netscaler.NSAPIResponse) {
-	e.interfacesJumboPacketsRx.Reset()
+func (e *Exporter) collectInterfacesJumboPacketsRx(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, iface := range ns.InterfaceStats {
 		val, _ := strconv.ParseFloat(iface.JumboPacketsReceived, 64)
 		labels := e.buildLabelValues(iface.ID, iface.Alias)
-		e.interfacesJumboPacketsRx.WithLabelValues(labels...).Set(val)
+		set.counter(e.interfacesJumboPacketsRx, val, labels...)
 	}
 }
 
-func (e *Exporter) collectInterfacesJumboPacketsTx(ns netscaler.NSAPIResponse) {
-	e.interfacesJumboPacketsTx.Reset()
+func (e *Exporter) collectInterfacesJumboPacketsTx(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, iface := range ns.InterfaceStats {
 		val, _ := strconv.ParseFloat(iface.JumboPacketsTransmitted, 64)
 		labels := e.buildLabelValues(iface.ID, iface.Alias)
-		e.interfacesJumboPacketsTx.WithLabelValues(labels...).Set(val)
+		set.counter(e.interfacesJumboPacketsTx, val, labels...)
 	}
 }
 
-func (e *Exporter) collectInterfacesErrorPacketsRx(ns netscaler.NSAPIResponse) {
-	e.interfacesErrorPacketsRx.Reset()
+func (e *Exporter) collectInterfacesErrorPacketsRx(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, iface := range ns.InterfaceStats {
 		val, _ := strconv.ParseFloat(iface.ErrorPacketsReceived, 64)
 		labels := e.buildLabelValues(iface.ID, iface.Alias)
-		e.interfacesErrorPacketsRx.WithLabelValues(labels...).Set(val)
+		set.counter(e.interfacesErrorPacketsRx, val, labels...)
 	}
 }
 
 // AAA collectors
-func (e *Exporter) collectAaaAuthSuccess(ns netscaler.NSAPIResponse) {
-	e.aaaAuthSuccess.Reset()
+func (e *Exporter) collectAaaAuthSuccess(set *metricSet, ns netscaler.NSAPIResponse) {
 	val, _ := strconv.ParseFloat(ns.AAAStats.AuthSuccess, 64)
 	labels := e.buildLabelValues()
-	e.aaaAuthSuccess.WithLabelValues(labels...).Set(val)
+	set.counter(e.aaaAuthSuccess, val, labels...)
 }
 
-func (e *Exporter) collectAaaAuthFail(ns netscaler.NSAPIResponse) {
-	e.aaaAuthFail.Reset()
+func (e *Exporter) collectAaaAuthFail(set *metricSet, ns netscaler.NSAPIResponse) {
 	val, _ := strconv.ParseFloat(ns.AAAStats.AuthFail, 64)
 	labels := e.buildLabelValues()
-	e.aaaAuthFail.WithLabelValues(labels...).Set(val)
+	set.counter(e.aaaAuthFail, val, labels...)
 }
 
-func (e *Exporter) collectAaaAuthOnlyHTTPSuccess(ns netscaler.NSAPIResponse) {
-	e.aaaAuthOnlyHTTPSuccess.Reset()
+func (e *Exporter) collectAaaAuthOnlyHTTPSuccess(set *metricSet, ns netscaler.NSAPIResponse) {
 	val, _ := strconv.ParseFloat(ns.AAAStats.AuthOnlyHTTPSuccess, 64)
 	labels := e.buildLabelValues()
-	e.aaaAuthOnlyHTTPSuccess.WithLabelValues(labels...).Set(val)
+	set.counter(e.aaaAuthOnlyHTTPSuccess, val, labels...)
 }
 
-func (e *Exporter) collectAaaAuthOnlyHTTPFail(ns netscaler.NSAPIResponse) {
-	e.aaaAuthOnlyHTTPFail.Reset()
+func (e *Exporter) collectAaaAuthOnlyHTTPFail(set *metricSet, ns netscaler.NSAPIResponse) {
 	val, _ := strconv.ParseFloat(ns.AAAStats.AuthOnlyHTTPFail, 64)
 	labels := e.buildLabelValues()
-	e.aaaAuthOnlyHTTPFail.WithLabelValues(labels...).Set(val)
+	set.counter(e.aaaAuthOnlyHTTPFail, val, labels...)
 }
 
-func (e *Exporter) collectAaaCurIcaSessions(ns netscaler.NSAPIResponse) {
-	e.aaaCurIcaSessions.Reset()
+func (e *Exporter) collectAaaCurIcaSessions(set *metricSet, ns netscaler.NSAPIResponse) {
 	val, _ := strconv.ParseFloat(ns.AAAStats.CurrentIcaSessions, 64)
 	labels := e.buildLabelValues()
-	e.aaaCurIcaSessions.WithLabelValues(labels...).Set(val)
+	set.gauge(e.aaaCurIcaSessions, val, labels...)
 }
 
-func (e *Exporter) collectAaaCurIcaOnlyConn(ns netscaler.NSAPIResponse) {
-	e.aaaCurIcaOnlyConn.Reset()
+func (e *Exporter) collectAaaCurIcaOnlyConn(set *metricSet, ns netscaler.NSAPIResponse) {
 	val, _ := strconv.ParseFloat(ns.AAAStats.CurrentIcaOnlyConnections, 64)
 	labels := e.buildLabelValues()
-	e.aaaCurIcaOnlyConn.WithLabelValues(labels...).Set(val)
+	set.gauge(e.aaaCurIcaOnlyConn, val, labels...)
 }
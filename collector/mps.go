@@ -7,49 +7,41 @@ import (
 )
 
 // collectMPSHealth collects MPS health metrics (CPU, disk, memory usage).
-func (e *Exporter) collectMPSHealth(mps netscaler.MPSAPIResponse) {
-	e.mpsHealthCPUUsage.Reset()
-	e.mpsHealthDiskUsage.Reset()
-	e.mpsHealthDiskFree.Reset()
-	e.mpsHealthDiskTotal.Reset()
-	e.mpsHealthDiskUsed.Reset()
-	e.mpsHealthMemoryUsage.Reset()
-	e.mpsHealthMemoryFree.Reset()
-	e.mpsHealthMemoryTotal.Reset()
+func (e *Exporter) collectMPSHealth(set *metricSet, mps netscaler.MPSAPIResponse) {
 
 	for _, health := range mps.MPSHealth {
 		labels := e.buildLabelValues(health.NodeType)
 
 		if cpuUsage, err := strconv.ParseFloat(health.CPUUsage, 64); err == nil {
-			e.mpsHealthCPUUsage.WithLabelValues(labels...).Set(cpuUsage)
+			set.gauge(e.mpsHealthCPUUsage, cpuUsage, labels...)
 		}
 
 		if diskUsage, err := strconv.ParseFloat(health.DiskUsage, 64); err == nil {
-			e.mpsHealthDiskUsage.WithLabelValues(labels...).Set(diskUsage)
+			set.gauge(e.mpsHealthDiskUsage, diskUsage, labels...)
 		}
 
 		if diskFree, err := strconv.ParseFloat(health.DiskFree, 64); err == nil {
-			e.mpsHealthDiskFree.WithLabelValues(labels...).Set(diskFree)
+			set.gauge(e.mpsHealthDiskFree, diskFree, labels...)
 		}
 
 		if diskTotal, err := strconv.ParseFloat(health.DiskTotal, 64); err == nil {
-			e.mpsHealthDiskTotal.WithLabelValues(labels...).Set(diskTotal)
+			set.gauge(e.mpsHealthDiskTotal, diskTotal, labels...)
 		}
 
 		if diskUsed, err := strconv.ParseFloat(health.DiskUsed, 64); err == nil {
-			e.mpsHealthDiskUsed.WithLabelValues(labels...).Set(diskUsed)
+			set.gauge(e.mpsHealthDiskUsed, diskUsed, labels...)
 		}
 
 		if memoryUsage, err := strconv.ParseFloat(health.MemoryUsage, 64); err == nil {
-			e.mpsHealthMemoryUsage.WithLabelValues(labels...).Set(memoryUsage)
+			set.gauge(e.mpsHealthMemoryUsage, memoryUsage, labels...)
 		}
 
 		if memoryFree, err := strconv.ParseFloat(health.MemoryFree, 64); err == nil {
-			e.mpsHealthMemoryFree.WithLabelValues(labels...).Set(memoryFree)
+			set.gauge(e.mpsHealthMemoryFree, memoryFree, labels...)
 		}
 
 		if memoryTotal, err := strconv.ParseFloat(health.MemoryTotal, 64); err == nil {
-			e.mpsHealthMemoryTotal.WithLabelValues(labels...).Set(memoryTotal)
+			set.gauge(e.mpsHealthMemoryTotal, memoryTotal, labels...)
 		}
 	}
 }
@@ -0,0 +1,191 @@
+package collector
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// pollJitter is the fraction of the poll interval by which each wait is randomly
+// lengthened or shortened, so that targets sharing an interval drift apart.
+const pollJitter = 0.1
+
+// poller scrapes an exporter's target in the background and keeps the last
+// complete result as a snapshot that Collect serves.
+type poller struct {
+	e        *Exporter
+	interval time.Duration
+	cancel   context.CancelFunc
+	done     chan struct{}
+	ready    chan struct{} // closed after the first poll
+
+	start     chan struct{} // closed to run the first poll right away
+	startOnce sync.Once
+
+	mu        sync.RWMutex
+	metrics   []prometheus.Metric
+	timestamp time.Time
+	partial   bool
+}
+
+// StartPolling scrapes the target every interval (with jitter) in the background.
+// Collect then serves the last snapshot instead of scraping on every request.
+// It must be called before the exporter is registered.
+func (e *Exporter) StartPolling(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &poller{
+		e:        e,
+		interval: interval,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+		ready:    make(chan struct{}),
+		start:    make(chan struct{}),
+	}
+	e.poller = p
+	go p.run(ctx)
+}
+
+// run polls until ctx is cancelled. The first poll starts after a random
+// offset within the interval, so that targets started together (e.g. by a
+// reload) do not poll in lockstep, or as soon as a request is waiting for it.
+func (p *poller) run(ctx context.Context) {
+	defer close(p.done)
+	timer := time.NewTimer(p.firstWait())
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return
+	case <-p.start:
+	case <-timer.C:
+	}
+
+	for {
+		p.poll(ctx)
+
+		timer.Reset(p.nextWait())
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// firstWait returns the random offset of the first poll within the interval.
+func (p *poller) firstWait() time.Duration {
+	return rand.N(p.interval)
+}
+
+// nextWait returns the interval, randomly lengthened or shortened by pollJitter.
+func (p *poller) nextWait() time.Duration {
+	return p.interval + time.Duration((rand.Float64()*2-1)*pollJitter*float64(p.interval))
+}
+
+// poll runs one full scrape and swaps in the result.
+// A poll cut short by stop is discarded.
+func (p *poller) poll(ctx context.Context) {
+	ch := make(chan prometheus.Metric)
+	var metrics []prometheus.Metric
+	collected := make(chan struct{})
+	go func() {
+		defer close(collected)
+		for m := range ch {
+			// Freeze the value: counters keep changing during the next poll
+			frozen, err := freezeMetric(m)
+			if err != nil {
+				p.e.logger.Warn("failed to snapshot metric", "url", p.e.url, "metric", m.Desc().String(), "err", err)
+				continue
+			}
+			metrics = append(metrics, frozen)
+		}
+	}()
+
+	partial := p.e.scrape(ctx, ch)
+	close(ch)
+	<-collected
+	if ctx.Err() != nil {
+		return
+	}
+
+	p.mu.Lock()
+	p.metrics = metrics
+	p.timestamp = time.Now()
+	p.partial = partial
+	p.mu.Unlock()
+
+	select {
+	case <-p.ready:
+	default:
+		close(p.ready)
+	}
+}
+
+// collect sends the last snapshot together with its age and partial flag.
+// Before the first poll has finished it starts it if still pending and waits
+// for it, at most until ctx is done.
+func (p *poller) collect(ctx context.Context, ch chan<- prometheus.Metric) {
+	p.startOnce.Do(func() { close(p.start) })
+	select {
+	case <-p.ready:
+	case <-p.done:
+		return
+	case <-ctx.Done():
+		return
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for _, m := range p.metrics {
+		ch <- m
+	}
+
+	baseLabels := p.e.buildLabelValues()
+	partial := 0.0
+	if p.partial {
+		partial = 1
+	}
+	ch <- prometheus.MustNewConstMetric(p.e.snapshotAge, prometheus.GaugeValue, time.Since(p.timestamp).Seconds(), baseLabels...)
+	ch <- prometheus.MustNewConstMetric(p.e.snapshotPartial, prometheus.GaugeValue, partial, baseLabels...)
+}
+
+// stop ends polling and waits for a running poll to finish.
+func (p *poller) stop() {
+	p.cancel()
+	<-p.done
+}
+
+// frozenMetric is a metric whose value was captured at snapshot time.
+type frozenMetric struct {
+	desc *prometheus.Desc
+	pb   *dto.Metric
+}
+
+func freezeMetric(m prometheus.Metric) (prometheus.Metric, error) {
+	pb := &dto.Metric{}
+	if err := m.Write(pb); err != nil {
+		return nil, err
+	}
+	return &frozenMetric{desc: m.Desc(), pb: pb}, nil
+}
+
+// Desc implements prometheus.Metric.
+func (m *frozenMetric) Desc() *prometheus.Desc {
+	return m.desc
+}
+
+// Write implements prometheus.Metric.
+func (m *frozenMetric) Write(out *dto.Metric) error {
+	out.Label = m.pb.Label
+	out.Gauge = m.pb.Gauge
+	out.Counter = m.pb.Counter
+	out.Summary = m.pb.Summary
+	out.Untyped = m.pb.Untyped
+	out.Histogram = m.pb.Histogram
+	out.TimestampMs = m.pb.TimestampMs
+	return nil
+}
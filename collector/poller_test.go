@@ -0,0 +1,129 @@
+package collector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/elohmeier/netscaler-exporter/netscaler/nitrotest"
+)
+
+func hasMetric(families []*dto.MetricFamily, name string) bool {
+	for _, mf := range families {
+		if mf.GetName() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// TestPollerWaits checks that the first poll starts within the interval and
+// that the waits between polls stay within the jitter.
+func TestPollerWaits(t *testing.T) {
+	const interval = time.Minute
+	p := &poller{interval: interval}
+	firsts := make(map[time.Duration]bool)
+	for range 100 {
+		first, next := p.firstWait(), p.nextWait()
+		if first < 0 || first >= interval {
+			t.Fatalf("first wait %s, want within [0, %s)", first, interval)
+		}
+		if lo, hi := interval*9/10, interval*11/10; next < lo || next > hi {
+			t.Fatalf("wait %s, want within [%s, %s]", next, lo, hi)
+		}
+		firsts[first] = true
+	}
+	if len(firsts) < 2 {
+		t.Error("the first wait is not random")
+	}
+}
+
+// TestPollerInterval checks that the target is polled repeatedly, with at
+// least the interval less its jitter between polls.
+func TestPollerInterval(t *testing.T) {
+	srv := newFakeNitro(t)
+	e := newTestExporter(t, srv.URL, nil)
+	const interval = 50 * time.Millisecond
+	start := time.Now()
+	e.StartPolling(interval)
+
+	for deadline := time.Now().Add(5 * time.Second); srv.Count("stat/ns") < 3; {
+		if time.Now().After(deadline) {
+			t.Fatalf("polled %d times in 5s, want 3", srv.Count("stat/ns"))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if elapsed, min := time.Since(start), 2*interval*9/10; elapsed < min {
+		t.Errorf("3 polls took %s, want at least %s", elapsed, min)
+	}
+}
+
+// TestPollerFirstRequest checks that a request before the first poll starts
+// it instead of waiting for the random offset, and is served from it.
+func TestPollerFirstRequest(t *testing.T) {
+	srv := newFakeNitro(t)
+	e := newTestExporter(t, srv.URL, nil)
+	e.StartPolling(time.Hour)
+
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(e)
+	start := time.Now()
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("first request took %s", elapsed)
+	}
+	if !hasMetric(families, "netscaler_snapshot_age_seconds") {
+		t.Error("first request was not served from a snapshot")
+	}
+	if n := srv.Count("stat/ns"); n != 1 {
+		t.Errorf("polled %d times, want 1", n)
+	}
+}
+
+// TestPollerStop checks that stopping the poller returns promptly, both
+// while waiting for the first poll and during a slow poll, and that requests
+// after it get no snapshot.
+func TestPollerStop(t *testing.T) {
+	t.Run("before first poll", func(t *testing.T) {
+		srv := newFakeNitro(t)
+		e := newTestExporter(t, srv.URL, nil)
+		e.StartPolling(time.Hour)
+		e.Close()
+		if n := len(srv.Requests()); n != 0 {
+			t.Errorf("%d requests sent, want none", n)
+		}
+	})
+
+	t.Run("during poll", func(t *testing.T) {
+		srv := newFakeNitro(t)
+		srv.AddFault(nitrotest.Fault{Resource: "stat/ns", Latency: 5 * time.Second})
+		e := newTestExporter(t, srv.URL, nil)
+		e.StartPolling(time.Millisecond)
+		for deadline := time.Now().Add(5 * time.Second); srv.Count("stat/ns") == 0; {
+			if time.Now().After(deadline) {
+				t.Fatal("no poll started")
+			}
+			time.Sleep(time.Millisecond)
+		}
+
+		start := time.Now()
+		e.Close()
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("stop took %s, want the running poll cancelled", elapsed)
+		}
+		reg := prometheus.NewPedanticRegistry()
+		reg.MustRegister(e)
+		families, err := reg.Gather()
+		if err != nil {
+			t.Fatalf("gather failed: %v", err)
+		}
+		if hasMetric(families, "netscaler_snapshot_age_seconds") {
+			t.Error("served a snapshot of the cancelled poll")
+		}
+	})
+}
@@ -10,42 +10,42 @@ import (
 )
 
 // collectProtocolHTTPStats collects protocol HTTP statistics
-func (e *Exporter) collectProtocolHTTPStats(ctx context.Context, nsClient *netscaler.NitroClient, ch chan<- prometheus.Metric) {
+func (e *Exporter) collectProtocolHTTPStats(ctx context.Context, nsClient netscaler.Client, ch chan<- prometheus.Metric) error {
 	stats, err := netscaler.GetProtocolHTTPStats(ctx, nsClient, "")
 	if err != nil {
 		e.logger.Error("failed to get protocol HTTP stats", "url", e.url, "err", err)
-		return
+		return err
 	}
 
 	baseLabels := e.buildLabelValues()
 	http := stats.ProtocolHTTPStats
 
 	// Counters
-	e.sendMetric(ch, e.httpTotalRequests, http.TotalRequests, baseLabels)
-	e.sendMetric(ch, e.httpTotalResponses, http.TotalResponses, baseLabels)
-	e.sendMetric(ch, e.httpTotalPosts, http.TotalPosts, baseLabels)
-	e.sendMetric(ch, e.httpTotalGets, http.TotalGets, baseLabels)
-	e.sendMetric(ch, e.httpTotalOthers, http.TotalOthers, baseLabels)
-	e.sendMetric(ch, e.httpTotalRxRequestBytes, http.TotalRxRequestBytes, baseLabels)
-	e.sendMetric(ch, e.httpTotalRxResponseBytes, http.TotalRxResponseBytes, baseLabels)
-	e.sendMetric(ch, e.httpTotalTxRequestBytes, http.TotalTxRequestBytes, baseLabels)
-	e.sendMetric(ch, e.httpTotal10Requests, http.Total10Requests, baseLabels)
-	e.sendMetric(ch, e.httpTotal11Requests, http.Total11Requests, baseLabels)
-	e.sendMetric(ch, e.httpTotal10Responses, http.Total10Responses, baseLabels)
-	e.sendMetric(ch, e.httpTotal11Responses, http.Total11Responses, baseLabels)
-	e.sendMetric(ch, e.httpTotalChunkedRequests, http.TotalChunkedRequests, baseLabels)
-	e.sendMetric(ch, e.httpTotalChunkedResponses, http.TotalChunkedResponses, baseLabels)
-	e.sendMetric(ch, e.httpTotalSPDYStreams, http.TotalSPDYStreams, baseLabels)
-	e.sendMetric(ch, e.httpTotalSPDYv2Streams, http.TotalSPDYv2Streams, baseLabels)
-	e.sendMetric(ch, e.httpTotalSPDYv3Streams, http.TotalSPDYv3Streams, baseLabels)
-	e.sendMetric(ch, e.httpErrNoReuseMultipart, http.ErrNoReuseMultipart, baseLabels)
-	e.sendMetric(ch, e.httpErrIncompleteHeaders, http.ErrIncompleteHeaders, baseLabels)
-	e.sendMetric(ch, e.httpErrIncompleteRequests, http.ErrIncompleteRequests, baseLabels)
-	e.sendMetric(ch, e.httpErrIncompleteResponses, http.ErrIncompleteResponses, baseLabels)
-	e.sendMetric(ch, e.httpErrServerBusy, http.ErrServerBusy, baseLabels)
-	e.sendMetric(ch, e.httpErrLargeContent, http.ErrLargeContent, baseLabels)
-	e.sendMetric(ch, e.httpErrLargeChunk, http.ErrLargeChunk, baseLabels)
-	e.sendMetric(ch, e.httpErrLargeCtlen, http.ErrLargeCtlen, baseLabels)
+	e.sendCounter(ch, e.httpTotalRequests, http.TotalRequests, baseLabels)
+	e.sendCounter(ch, e.httpTotalResponses, http.TotalResponses, baseLabels)
+	e.sendCounter(ch, e.httpTotalPosts, http.TotalPosts, baseLabels)
+	e.sendCounter(ch, e.httpTotalGets, http.TotalGets, baseLabels)
+	e.sendCounter(ch, e.httpTotalOthers, http.TotalOthers, baseLabels)
+	e.sendCounter(ch, e.httpTotalRxRequestBytes, http.TotalRxRequestBytes, baseLabels)
+	e.sendCounter(ch, e.httpTotalRxResponseBytes, http.TotalRxResponseBytes, baseLabels)
+	e.sendCounter(ch, e.httpTotalTxRequestBytes, http.TotalTxRequestBytes, baseLabels)
+	e.sendCounter(ch, e.httpTotal10Requests, http.Total10Requests, baseLabels)
+	e.sendCounter(ch, e.httpTotal11Requests, http.Total11Requests, baseLabels)
+	e.sendCounter(ch, e.httpTotal10Responses, http.Total10Responses, baseLabels)
+	e.sendCounter(ch, e.httpTotal11Responses, http.Total11Responses, baseLabels)
+	e.sendCounter(ch, e.httpTotalChunkedRequests, http.TotalChunkedRequests, baseLabels)
+	e.sendCounter(ch, e.httpTotalChunkedResponses, http.TotalChunkedResponses, baseLabels)
+	e.sendCounter(ch, e.httpTotalSPDYStreams, http.TotalSPDYStreams, baseLabels)
+	e.sendCounter(ch, e.httpTotalSPDYv2Streams, http.TotalSPDYv2Streams, baseLabels)
+	e.sendCounter(ch, e.httpTotalSPDYv3Streams, http.TotalSPDYv3Streams, baseLabels)
+	e.sendCounter(ch, e.httpErrNoReuseMultipart, http.ErrNoReuseMultipart, baseLabels)
+	e.sendCounter(ch, e.httpErrIncompleteHeaders, http.ErrIncompleteHeaders, baseLabels)
+	e.sendCounter(ch, e.httpErrIncompleteRequests, http.ErrIncompleteRequests, baseLabels)
+	e.sendCounter(ch, e.httpErrIncompleteResponses, http.ErrIncompleteResponses, baseLabels)
+	e.sendCounter(ch, e.httpErrServerBusy, http.ErrServerBusy, baseLabels)
+	e.sendCounter(ch, e.httpErrLargeContent, http.ErrLargeContent, baseLabels)
+	e.sendCounter(ch, e.httpErrLargeChunk, http.ErrLargeChunk, baseLabels)
+	e.sendCounter(ch, e.httpErrLargeCtlen, http.ErrLargeCtlen, baseLabels)
 
 	// Gauges (rates)
 	e.sendMetric(ch, e.httpRequestsRate, http.RequestsRate, baseLabels)
@@ -69,30 +69,32 @@ func (e *Exporter) collectProtocolHTTPStats(ctx context.Context, nsClient *netsc
 	e.sendMetric(ch, e.httpErrIncompleteRequestsRate, http.ErrIncompleteRequestsRate, baseLabels)
 	e.sendMetric(ch, e.httpErrIncompleteResponsesRate, http.ErrIncompleteResponsesRate, baseLabels)
 	e.sendMetric(ch, e.httpErrServerBusyRate, http.ErrServerBusyRate, baseLabels)
+
+	return nil
 }
 
 // collectProtocolTCPStats collects protocol TCP statistics
-func (e *Exporter) collectProtocolTCPStats(ctx context.Context, nsClient *netscaler.NitroClient, ch chan<- prometheus.Metric) {
+func (e *Exporter) collectProtocolTCPStats(ctx context.Context, nsClient netscaler.Client, ch chan<- prometheus.Metric) error {
 	stats, err := netscaler.GetProtocolTCPStats(ctx, nsClient, "")
 	if err != nil {
 		e.logger.Error("failed to get protocol TCP stats", "url", e.url, "err", err)
-		return
+		return err
 	}
 
 	baseLabels := e.buildLabelValues()
 	tcp := stats.ProtocolTCPStats
 
 	// Counters
-	e.sendMetric(ch, e.tcpTotalRxPackets, tcp.TotalRxPackets, baseLabels)
-	e.sendMetric(ch, e.tcpTotalRxBytes, tcp.TotalRxBytes, baseLabels)
-	e.sendMetric(ch, e.tcpTotalTxBytes, tcp.TotalTxBytes, baseLabels)
-	e.sendMetric(ch, e.tcpTotalTxPackets, tcp.TotalTxPackets, baseLabels)
-	e.sendMetric(ch, e.tcpTotalClientConnOpened, tcp.TotalClientConnOpened, baseLabels)
-	e.sendMetric(ch, e.tcpTotalServerConnOpened, tcp.TotalServerConnOpened, baseLabels)
-	e.sendMetric(ch, e.tcpTotalSyn, tcp.TotalSyn, baseLabels)
-	e.sendMetric(ch, e.tcpTotalSynProbe, tcp.TotalSynProbe, baseLabels)
-	e.sendMetric(ch, e.tcpTotalServerFin, tcp.TotalServerFin, baseLabels)
-	e.sendMetric(ch, e.tcpTotalClientFin, tcp.TotalClientFin, baseLabels)
+	e.sendCounter(ch, e.tcpTotalRxPackets, tcp.TotalRxPackets, baseLabels)
+	e.sendCounter(ch, e.tcpTotalRxBytes, tcp.TotalRxBytes, baseLabels)
+	e.sendCounter(ch, e.tcpTotalTxBytes, tcp.TotalTxBytes, baseLabels)
+	e.sendCounter(ch, e.tcpTotalTxPackets, tcp.TotalTxPackets, baseLabels)
+	e.sendCounter(ch, e.tcpTotalClientConnOpened, tcp.TotalClientConnOpened, baseLabels)
+	e.sendCounter(ch, e.tcpTotalServerConnOpened, tcp.TotalServerConnOpened, baseLabels)
+	e.sendCounter(ch, e.tcpTotalSyn, tcp.TotalSyn, baseLabels)
+	e.sendCounter(ch, e.tcpTotalSynProbe, tcp.TotalSynProbe, baseLabels)
+	e.sendCounter(ch, e.tcpTotalServerFin, tcp.TotalServerFin, baseLabels)
+	e.sendCounter(ch, e.tcpTotalClientFin, tcp.TotalClientFin, baseLabels)
 
 	// Gauges
 	e.sendMetric(ch, e.tcpActiveServerConn, tcp.ActiveServerConn, baseLabels)
@@ -103,56 +105,58 @@ func (e *Exporter) collectProtocolTCPStats(ctx context.Context, nsClient *netsca
 	e.sendMetric(ch, e.tcpTxPacketsRate, tcp.TxPacketsRate, baseLabels)
 	e.sendMetric(ch, e.tcpTxBytesRate, tcp.TxBytesRate, baseLabels)
 	e.sendMetric(ch, e.tcpClientConnOpenedRate, tcp.ClientConnOpenedRate, baseLabels)
-	e.sendMetric(ch, e.tcpErrBadChecksum, tcp.ErrBadChecksum, baseLabels)
+	e.sendCounter(ch, e.tcpErrBadChecksum, tcp.ErrBadChecksum, baseLabels)
 	e.sendMetric(ch, e.tcpErrBadChecksumRate, tcp.ErrBadChecksumRate, baseLabels)
-	e.sendMetric(ch, e.tcpErrAnyPortFail, tcp.ErrAnyPortFail, baseLabels)
-	e.sendMetric(ch, e.tcpErrIPPortFail, tcp.ErrIPPortFail, baseLabels)
-	e.sendMetric(ch, e.tcpErrBadStateConn, tcp.ErrBadStateConn, baseLabels)
-	e.sendMetric(ch, e.tcpErrRstThreshold, tcp.ErrRstThreshold, baseLabels)
+	e.sendCounter(ch, e.tcpErrAnyPortFail, tcp.ErrAnyPortFail, baseLabels)
+	e.sendCounter(ch, e.tcpErrIPPortFail, tcp.ErrIPPortFail, baseLabels)
+	e.sendCounter(ch, e.tcpErrBadStateConn, tcp.ErrBadStateConn, baseLabels)
+	e.sendCounter(ch, e.tcpErrRstThreshold, tcp.ErrRstThreshold, baseLabels)
 	e.sendMetric(ch, e.tcpSynRate, tcp.SynRate, baseLabels)
 	e.sendMetric(ch, e.tcpSynProbeRate, tcp.SynProbeRate, baseLabels)
+
+	return nil
 }
 
 // collectProtocolIPStats collects protocol IP statistics
-func (e *Exporter) collectProtocolIPStats(ctx context.Context, nsClient *netscaler.NitroClient, ch chan<- prometheus.Metric) {
+func (e *Exporter) collectProtocolIPStats(ctx context.Context, nsClient netscaler.Client, ch chan<- prometheus.Metric) error {
 	stats, err := netscaler.GetProtocolIPStats(ctx, nsClient, "")
 	if err != nil {
 		e.logger.Error("failed to get protocol IP stats", "url", e.url, "err", err)
-		return
+		return err
 	}
 
 	baseLabels := e.buildLabelValues()
 	ip := stats.ProtocolIPStats
 
 	// Counters
-	e.sendMetric(ch, e.ipTotalRxPackets, ip.TotalRxPackets, baseLabels)
-	e.sendMetric(ch, e.ipTotalRxBytes, ip.TotalRxBytes, baseLabels)
-	e.sendMetric(ch, e.ipTotalTxPackets, ip.TotalTxPackets, baseLabels)
-	e.sendMetric(ch, e.ipTotalTxBytes, ip.TotalTxBytes, baseLabels)
-	e.sendMetric(ch, e.ipTotalRxMbits, ip.TotalRxMbits, baseLabels)
-	e.sendMetric(ch, e.ipTotalTxMbits, ip.TotalTxMbits, baseLabels)
-	e.sendMetric(ch, e.ipTotalRoutedPackets, ip.TotalRoutedPackets, baseLabels)
-	e.sendMetric(ch, e.ipTotalRoutedMbits, ip.TotalRoutedMbits, baseLabels)
-	e.sendMetric(ch, e.ipTotalFragments, ip.TotalFragments, baseLabels)
-	e.sendMetric(ch, e.ipTotalSuccReassembly, ip.TotalSuccReassembly, baseLabels)
-	e.sendMetric(ch, e.ipTotalAddrLookup, ip.TotalAddrLookup, baseLabels)
-	e.sendMetric(ch, e.ipTotalAddrLookupFail, ip.TotalAddrLookupFail, baseLabels)
-	e.sendMetric(ch, e.ipTotalUDPFragmentsFwd, ip.TotalUDPFragmentsFwd, baseLabels)
-	e.sendMetric(ch, e.ipTotalTCPFragmentsFwd, ip.TotalTCPFragmentsFwd, baseLabels)
-	e.sendMetric(ch, e.ipTotalBadChecksums, ip.TotalBadChecksums, baseLabels)
-	e.sendMetric(ch, e.ipTotalUnsuccReassembly, ip.TotalUnsuccReassembly, baseLabels)
-	e.sendMetric(ch, e.ipTotalTooBig, ip.TotalTooBig, baseLabels)
-	e.sendMetric(ch, e.ipTotalDupFragments, ip.TotalDupFragments, baseLabels)
-	e.sendMetric(ch, e.ipTotalOutOfOrderFrag, ip.TotalOutOfOrderFrag, baseLabels)
-	e.sendMetric(ch, e.ipTotalVIPDown, ip.TotalVIPDown, baseLabels)
-	e.sendMetric(ch, e.ipTotalTTLExpired, ip.TotalTTLExpired, baseLabels)
-	e.sendMetric(ch, e.ipTotalMaxClients, ip.TotalMaxClients, baseLabels)
-	e.sendMetric(ch, e.ipTotalUnknownSvcs, ip.TotalUnknownSvcs, baseLabels)
-	e.sendMetric(ch, e.ipTotalInvalidHeaderSz, ip.TotalInvalidHeaderSz, baseLabels)
-	e.sendMetric(ch, e.ipTotalInvalidPacketSize, ip.TotalInvalidPacketSize, baseLabels)
-	e.sendMetric(ch, e.ipTotalTruncatedPackets, ip.TotalTruncatedPackets, baseLabels)
-	e.sendMetric(ch, e.ipNonIPTotalTruncatedPkts, ip.NonIPTotalTruncatedPkts, baseLabels)
-	e.sendMetric(ch, e.ipTotalBadMacAddrs, ip.TotalBadMacAddrs, baseLabels)
+	e.sendCounter(ch, e.ipTotalRxPackets, ip.TotalRxPackets, baseLabels)
+	e.sendCounter(ch, e.ipTotalRxBytes, ip.TotalRxBytes, baseLabels)
+	e.sendCounter(ch, e.ipTotalTxPackets, ip.TotalTxPackets, baseLabels)
+	e.sendCounter(ch, e.ipTotalTxBytes, ip.TotalTxBytes, baseLabels)
+	e.sendCounter(ch, e.ipTotalRxMbits, ip.TotalRxMbits, baseLabels)
+	e.sendCounter(ch, e.ipTotalTxMbits, ip.TotalTxMbits, baseLabels)
+	e.sendCounter(ch, e.ipTotalRoutedPackets, ip.TotalRoutedPackets, baseLabels)
+	e.sendCounter(ch, e.ipTotalRoutedMbits, ip.TotalRoutedMbits, baseLabels)
+	e.sendCounter(ch, e.ipTotalFragments, ip.TotalFragments, baseLabels)
+	e.sendCounter(ch, e.ipTotalSuccReassembly, ip.TotalSuccReassembly, baseLabels)
+	e.sendCounter(ch, e.ipTotalAddrLookup, ip.TotalAddrLookup, baseLabels)
+	e.sendCounter(ch, e.ipTotalAddrLookupFail, ip.TotalAddrLookupFail, baseLabels)
+	e.sendCounter(ch, e.ipTotalUDPFragmentsFwd, ip.TotalUDPFragmentsFwd, baseLabels)
+	e.sendCounter(ch, e.ipTotalTCPFragmentsFwd, ip.TotalTCPFragmentsFwd, baseLabels)
+	e.sendCounter(ch, e.ipTotalBadChecksums, ip.TotalBadChecksums, baseLabels)
+	e.sendCounter(ch, e.ipTotalUnsuccReassembly, ip.TotalUnsuccReassembly, baseLabels)
+	e.sendCounter(ch, e.ipTotalTooBig, ip.TotalTooBig, baseLabels)
+	e.sendCounter(ch, e.ipTotalDupFragments, ip.TotalDupFragments, baseLabels)
+	e.sendCounter(ch, e.ipTotalOutOfOrderFrag, ip.TotalOutOfOrderFrag, baseLabels)
+	e.sendCounter(ch, e.ipTotalVIPDown, ip.TotalVIPDown, baseLabels)
+	e.sendCounter(ch, e.ipTotalTTLExpired, ip.TotalTTLExpired, baseLabels)
+	e.sendCounter(ch, e.ipTotalMaxClients, ip.TotalMaxClients, baseLabels)
+	e.sendCounter(ch, e.ipTotalUnknownSvcs, ip.TotalUnknownSvcs, baseLabels)
+	e.sendCounter(ch, e.ipTotalInvalidHeaderSz, ip.TotalInvalidHeaderSz, baseLabels)
+	e.sendCounter(ch, e.ipTotalInvalidPacketSize, ip.TotalInvalidPacketSize, baseLabels)
+	e.sendCounter(ch, e.ipTotalTruncatedPackets, ip.TotalTruncatedPackets, baseLabels)
+	e.sendCounter(ch, e.ipNonIPTotalTruncatedPkts, ip.NonIPTotalTruncatedPkts, baseLabels)
+	e.sendCounter(ch, e.ipTotalBadMacAddrs, ip.TotalBadMacAddrs, baseLabels)
 
 	// Gauges (rates)
 	e.sendMetric(ch, e.ipRxPacketsRate, ip.RxPacketsRate, baseLabels)
@@ -163,6 +167,8 @@ func (e *Exporter) collectProtocolIPStats(ctx context.Context, nsClient *netscal
 	e.sendMetric(ch, e.ipTxMbitsRate, ip.TxMbitsRate, baseLabels)
 	e.sendMetric(ch, e.ipRoutedPacketsRate, ip.RoutedPacketsRate, baseLabels)
 	e.sendMetric(ch, e.ipRoutedMbitsRate, ip.RoutedMbitsRate, baseLabels)
+
+	return nil
 }
 
 // sendMetric is a helper to parse and send a metric value.
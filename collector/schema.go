@@ -0,0 +1,88 @@
+package collector
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MetricSchemaVersion is the version of the exported metric names and types.
+// Version 2 exposes the cumulative Nitro counters as counters with _total
+// names; version 1 published them as gauges.
+const MetricSchemaVersion = 2
+
+// bytesPerMegabit converts the traffic totals of the ns stats (totrxmbits and
+// tottxmbits), which Nitro counts in megabits, to bytes. Schema 1 published
+// them unconverted as total_received_mb and total_transmit_mb.
+const bytesPerMegabit = 1e6 / 8
+
+// counterDesc describes a cumulative Nitro counter. legacy is the gauge the
+// counter was published as in metric schema 1; it is nil if legacy metric
+// names are disabled or the counter kept its name. legacyUnit is the size of
+// the gauge's unit in the counter's unit, or 0 if both share the unit.
+type counterDesc struct {
+	desc       *prometheus.Desc
+	legacy     *prometheus.Desc
+	legacyUnit float64
+}
+
+// newCounterDesc returns the descriptor of a counter named name. legacyName is
+// its schema 1 name, or empty if the name did not change.
+func newCounterDesc(name, legacyName, help string, labels []string, legacy bool) counterDesc {
+	c := counterDesc{
+		desc: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", name), help, labels, nil),
+	}
+	if legacy && legacyName != "" {
+		c.legacy = prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", legacyName), help+" (deprecated, use "+metricsNamespace+"_"+name+")", labels, nil)
+	}
+	return c
+}
+
+// newConvertedCounterDesc is newCounterDesc for a counter whose schema 1 gauge
+// was published in another unit: one unit of the gauge, described by
+// legacyHelp, is legacyUnit of the counter's unit.
+func newConvertedCounterDesc(name, legacyName, help, legacyHelp string, legacyUnit float64, labels []string, legacy bool) counterDesc {
+	c := newCounterDesc(name, "", help, labels, legacy)
+	if legacy {
+		c.legacy = prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", legacyName), legacyHelp+" (deprecated, use "+metricsNamespace+"_"+name+")", labels, nil)
+		c.legacyUnit = legacyUnit
+	}
+	return c
+}
+
+// legacyValue converts a counter value to the unit of its legacy gauge.
+func (c counterDesc) legacyValue(value float64) float64 {
+	if c.legacyUnit == 0 {
+		return value
+	}
+	return value / c.legacyUnit
+}
+
+// describe sends the counter's descriptors.
+func (c counterDesc) describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+	if c.legacy != nil {
+		ch <- c.legacy
+	}
+}
+
+// collect sends the counter and, if enabled, its legacy gauge.
+func (c counterDesc) collect(ch chan<- prometheus.Metric, value float64, labelValues ...string) {
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.CounterValue, value, labelValues...)
+	if c.legacy != nil {
+		ch <- prometheus.MustNewConstMetric(c.legacy, prometheus.GaugeValue, c.legacyValue(value), labelValues...)
+	}
+}
+
+// sendCounter is a helper to send a counter value with any numeric type.
+func (e *Exporter) sendCounter(ch chan<- prometheus.Metric, c counterDesc, value any, labels []string) {
+	val, _ := strconv.ParseFloat(fmt.Sprint(value), 64)
+	c.collect(ch, val, labels...)
+}
+
+// setCounterVal is a helper to set a counter value with any numeric type.
+func setCounterVal(set *metricSet, c counterDesc, labels []string, value any) {
+	val, _ := strconv.ParseFloat(fmt.Sprint(value), 64)
+	set.counter(c, val, labels...)
+}
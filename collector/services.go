@@ -7,343 +7,305 @@ import (
 )
 
 // Service collectors
-func (e *Exporter) collectServicesThroughput(ns netscaler.NSAPIResponse) {
-	e.servicesThroughput.Reset()
+func (e *Exporter) collectServicesThroughput(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, service := range ns.ServiceStats {
 		val, _ := strconv.ParseFloat(service.Throughput, 64)
 		labels := e.buildLabelValues(service.Name)
-		e.servicesThroughput.WithLabelValues(labels...).Set(val)
+		set.gauge(e.servicesThroughput, val, labels...)
 	}
 }
 
-func (e *Exporter) collectServicesAvgTTFB(ns netscaler.NSAPIResponse) {
-	e.servicesAvgTTFB.Reset()
+func (e *Exporter) collectServicesAvgTTFB(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, service := range ns.ServiceStats {
 		val, _ := strconv.ParseFloat(service.AvgTimeToFirstByte, 64)
 		labels := e.buildLabelValues(service.Name)
-		e.servicesAvgTTFB.WithLabelValues(labels...).Set(val)
+		set.gauge(e.servicesAvgTTFB, val, labels...)
 	}
 }
 
-func (e *Exporter) collectServicesState(ns netscaler.NSAPIResponse) {
-	e.servicesState.Reset()
+func (e *Exporter) collectServicesState(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, service := range ns.ServiceStats {
 		state := 0.0
 		if service.State == "UP" {
 			state = 1.0
 		}
 		labels := e.buildLabelValues(service.Name)
-		e.servicesState.WithLabelValues(labels...).Set(state)
+		set.gauge(e.servicesState, state, labels...)
 	}
 }
 
-func (e *Exporter) collectServicesTotalRequests(ns netscaler.NSAPIResponse) {
-	e.servicesTotalRequests.Reset()
+func (e *Exporter) collectServicesTotalRequests(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, service := range ns.ServiceStats {
 		val, _ := strconv.ParseFloat(service.TotalRequests, 64)
 		labels := e.buildLabelValues(service.Name)
-		e.servicesTotalRequests.WithLabelValues(labels...).Set(val)
+		set.counter(e.servicesTotalRequests, val, labels...)
 	}
 }
 
-func (e *Exporter) collectServicesTotalResponses(ns netscaler.NSAPIResponse) {
-	e.servicesTotalResponses.Reset()
+func (e *Exporter) collectServicesTotalResponses(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, service := range ns.ServiceStats {
 		val, _ := strconv.ParseFloat(service.TotalResponses, 64)
 		labels := e.buildLabelValues(service.Name)
-		e.servicesTotalResponses.WithLabelValues(labels...).Set(val)
+		set.counter(e.servicesTotalResponses, val, labels...)
 	}
 }
 
-func (e *Exporter) collectServicesTotalRequestBytes(ns netscaler.NSAPIResponse) {
-	e.servicesTotalRequestBytes.Reset()
+func (e *Exporter) collectServicesTotalRequestBytes(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, service := range ns.ServiceStats {
 		val, _ := strconv.ParseFloat(service.TotalRequestBytes, 64)
 		labels := e.buildLabelValues(service.Name)
-		e.servicesTotalRequestBytes.WithLabelValues(labels...).Set(val)
+		set.counter(e.servicesTotalRequestBytes, val, labels...)
 	}
 }
 
-func (e *Exporter) collectServicesTotalResponseBytes(ns netscaler.NSAPIResponse) {
-	e.servicesTotalResponseBytes.Reset()
+func (e *Exporter) collectServicesTotalResponseBytes(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, service := range ns.ServiceStats {
 		val, _ := strconv.ParseFloat(service.TotalResponseBytes, 64)
 		labels := e.buildLabelValues(service.Name)
-		e.servicesTotalResponseBytes.WithLabelValues(labels...).Set(val)
+		set.counter(e.servicesTotalResponseBytes, val, labels...)
 	}
 }
 
-func (e *Exporter) collectServicesCurrentClientConns(ns netscaler.NSAPIResponse) {
-	e.servicesCurrentClientConns.Reset()
+func (e *Exporter) collectServicesCurrentClientConns(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, service := range ns.ServiceStats {
 		val, _ := strconv.ParseFloat(service.CurrentClientConnections, 64)
 		labels := e.buildLabelValues(service.Name)
-		e.servicesCurrentClientConns.WithLabelValues(labels...).Set(val)
+		set.gauge(e.servicesCurrentClientConns, val, labels...)
 	}
 }
 
-func (e *Exporter) collectServicesSurgeCount(ns netscaler.NSAPIResponse) {
-	e.servicesSurgeCount.Reset()
+func (e *Exporter) collectServicesSurgeCount(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, service := range ns.ServiceStats {
 		val, _ := strconv.ParseFloat(service.SurgeCount, 64)
 		labels := e.buildLabelValues(service.Name)
-		e.servicesSurgeCount.WithLabelValues(labels...).Set(val)
+		set.gauge(e.servicesSurgeCount, val, labels...)
 	}
 }
 
-func (e *Exporter) collectServicesCurrentServerConns(ns netscaler.NSAPIResponse) {
-	e.servicesCurrentServerConns.Reset()
+func (e *Exporter) collectServicesCurrentServerConns(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, service := range ns.ServiceStats {
 		val, _ := strconv.ParseFloat(service.CurrentServerConnections, 64)
 		labels := e.buildLabelValues(service.Name)
-		e.servicesCurrentServerConns.WithLabelValues(labels...).Set(val)
+		set.gauge(e.servicesCurrentServerConns, val, labels...)
 	}
 }
 
-func (e *Exporter) collectServicesServerEstablishedConnections(ns netscaler.NSAPIResponse) {
-	e.servicesServerEstablishedConnections.Reset()
+func (e *Exporter) collectServicesServerEstablishedConnections(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, service := range ns.ServiceStats {
 		val, _ := strconv.ParseFloat(service.ServerEstablishedConnections, 64)
 		labels := e.buildLabelValues(service.Name)
-		e.servicesServerEstablishedConnections.WithLabelValues(labels...).Set(val)
+		set.gauge(e.servicesServerEstablishedConnections, val, labels...)
 	}
 }
 
-func (e *Exporter) collectServicesCurrentReusePool(ns netscaler.NSAPIResponse) {
-	e.servicesCurrentReusePool.Reset()
+func (e *Exporter) collectServicesCurrentReusePool(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, service := range ns.ServiceStats {
 		val, _ := strconv.ParseFloat(service.CurrentReusePool, 64)
 		labels := e.buildLabelValues(service.Name)
-		e.servicesCurrentReusePool.WithLabelValues(labels...).Set(val)
+		set.gauge(e.servicesCurrentReusePool, val, labels...)
 	}
 }
 
-func (e *Exporter) collectServicesMaxClients(ns netscaler.NSAPIResponse) {
-	e.servicesMaxClients.Reset()
+func (e *Exporter) collectServicesMaxClients(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, service := range ns.ServiceStats {
 		val, _ := strconv.ParseFloat(service.MaxClients, 64)
 		labels := e.buildLabelValues(service.Name)
-		e.servicesMaxClients.WithLabelValues(labels...).Set(val)
+		set.gauge(e.servicesMaxClients, val, labels...)
 	}
 }
 
-func (e *Exporter) collectServicesCurrentLoad(ns netscaler.NSAPIResponse) {
-	e.servicesCurrentLoad.Reset()
+func (e *Exporter) collectServicesCurrentLoad(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, service := range ns.ServiceStats {
 		val, _ := strconv.ParseFloat(service.CurrentLoad, 64)
 		labels := e.buildLabelValues(service.Name)
-		e.servicesCurrentLoad.WithLabelValues(labels...).Set(val)
+		set.gauge(e.servicesCurrentLoad, val, labels...)
 	}
 }
 
-func (e *Exporter) collectServicesVirtualServerServiceHits(ns netscaler.NSAPIResponse) {
-	e.servicesVirtualServerServiceHits.Reset()
+func (e *Exporter) collectServicesVirtualServerServiceHits(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, service := range ns.ServiceStats {
 		val, _ := strconv.ParseFloat(service.ServiceHits, 64)
 		labels := e.buildLabelValues(service.Name)
-		e.servicesVirtualServerServiceHits.WithLabelValues(labels...).Set(val)
+		set.counter(e.servicesVirtualServerServiceHits, val, labels...)
 	}
 }
 
-func (e *Exporter) collectServicesActiveTransactions(ns netscaler.NSAPIResponse) {
-	e.servicesActiveTransactions.Reset()
+func (e *Exporter) collectServicesActiveTransactions(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, service := range ns.ServiceStats {
 		val, _ := strconv.ParseFloat(service.ActiveTransactions, 64)
 		labels := e.buildLabelValues(service.Name)
-		e.servicesActiveTransactions.WithLabelValues(labels...).Set(val)
+		set.gauge(e.servicesActiveTransactions, val, labels...)
 	}
 }
 
 // GSLB Service collectors
-func (e *Exporter) collectGSLBServicesState(ns netscaler.NSAPIResponse) {
-	e.gslbServicesState.Reset()
+func (e *Exporter) collectGSLBServicesState(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, service := range ns.GSLBServiceStats {
 		state := 0.0
 		if service.State == "UP" {
 			state = 1.0
 		}
 		labels := e.buildLabelValues(service.Name)
-		e.gslbServicesState.WithLabelValues(labels...).Set(state)
+		set.gauge(e.gslbServicesState, state, labels...)
 	}
 }
 
-func (e *Exporter) collectGSLBServicesTotalRequests(ns netscaler.NSAPIResponse) {
-	e.gslbServicesTotalRequests.Reset()
+func (e *Exporter) collectGSLBServicesTotalRequests(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, service := range ns.GSLBServiceStats {
 		val, _ := strconv.ParseFloat(service.TotalRequests, 64)
 		labels := e.buildLabelValues(service.Name)
-		e.gslbServicesTotalRequests.WithLabelValues(labels...).Set(val)
+		set.counter(e.gslbServicesTotalRequests, val, labels...)
 	}
 }
 
-func (e *Exporter) collectGSLBServicesTotalResponses(ns netscaler.NSAPIResponse) {
-	e.gslbServicesTotalResponses.Reset()
+func (e *Exporter) collectGSLBServicesTotalResponses(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, service := range ns.GSLBServiceStats {
 		val, _ := strconv.ParseFloat(service.TotalResponses, 64)
 		labels := e.buildLabelValues(service.Name)
-		e.gslbServicesTotalResponses.WithLabelValues(labels...).Set(val)
+		set.counter(e.gslbServicesTotalResponses, val, labels...)
 	}
 }
 
-func (e *Exporter) collectGSLBServicesTotalRequestBytes(ns netscaler.NSAPIResponse) {
-	e.gslbServicesTotalRequestBytes.Reset()
+func (e *Exporter) collectGSLBServicesTotalRequestBytes(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, service := range ns.GSLBServiceStats {
 		val, _ := strconv.ParseFloat(service.TotalRequestBytes, 64)
 		labels := e.buildLabelValues(service.Name)
-		e.gslbServicesTotalRequestBytes.WithLabelValues(labels...).Set(val)
+		set.counter(e.gslbServicesTotalRequestBytes, val, labels...)
 	}
 }
 
-func (e *Exporter) collectGSLBServicesTotalResponseBytes(ns netscaler.NSAPIResponse) {
-	e.gslbServicesTotalResponseBytes.Reset()
+func (e *Exporter) collectGSLBServicesTotalResponseBytes(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, service := range ns.GSLBServiceStats {
 		val, _ := strconv.ParseFloat(service.TotalResponseBytes, 64)
 		labels := e.buildLabelValues(service.Name)
-		e.gslbServicesTotalResponseBytes.WithLabelValues(labels...).Set(val)
+		set.counter(e.gslbServicesTotalResponseBytes, val, labels...)
 	}
 }
 
-func (e *Exporter) collectGSLBServicesCurrentClientConns(ns netscaler.NSAPIResponse) {
-	e.gslbServicesCurrentClientConns.Reset()
+func (e *Exporter) collectGSLBServicesCurrentClientConns(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, service := range ns.GSLBServiceStats {
 		val, _ := strconv.ParseFloat(service.CurrentClientConnections, 64)
 		labels := e.buildLabelValues(service.Name)
-		e.gslbServicesCurrentClientConns.WithLabelValues(labels...).Set(val)
+		set.gauge(e.gslbServicesCurrentClientConns, val, labels...)
 	}
 }
 
-func (e *Exporter) collectGSLBServicesCurrentServerConns(ns netscaler.NSAPIResponse) {
-	e.gslbServicesCurrentServerConns.Reset()
+func (e *Exporter) collectGSLBServicesCurrentServerConns(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, service := range ns.GSLBServiceStats {
 		val, _ := strconv.ParseFloat(service.CurrentServerConnections, 64)
 		labels := e.buildLabelValues(service.Name)
-		e.gslbServicesCurrentServerConns.WithLabelValues(labels...).Set(val)
+		set.gauge(e.gslbServicesCurrentServerConns, val, labels...)
 	}
 }
 
-func (e *Exporter) collectGSLBServicesEstablishedConnections(ns netscaler.NSAPIResponse) {
-	e.gslbServicesEstablishedConnections.Reset()
+func (e *Exporter) collectGSLBServicesEstablishedConnections(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, service := range ns.GSLBServiceStats {
 		val, _ := strconv.ParseFloat(service.EstablishedConnections, 64)
 		labels := e.buildLabelValues(service.Name)
-		e.gslbServicesEstablishedConnections.WithLabelValues(labels...).Set(val)
+		set.gauge(e.gslbServicesEstablishedConnections, val, labels...)
 	}
 }
 
-func (e *Exporter) collectGSLBServicesCurrentLoad(ns netscaler.NSAPIResponse) {
-	e.gslbServicesCurrentLoad.Reset()
+func (e *Exporter) collectGSLBServicesCurrentLoad(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, service := range ns.GSLBServiceStats {
 		val, _ := strconv.ParseFloat(service.CurrentLoad, 64)
 		labels := e.buildLabelValues(service.Name)
-		e.gslbServicesCurrentLoad.WithLabelValues(labels...).Set(val)
+		set.gauge(e.gslbServicesCurrentLoad, val, labels...)
 	}
 }
 
-func (e *Exporter) collectGSLBServicesVirtualServerServiceHits(ns netscaler.NSAPIResponse) {
-	e.gslbServicesVirtualServerServiceHits.Reset()
+func (e *Exporter) collectGSLBServicesVirtualServerServiceHits(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, service := range ns.GSLBServiceStats {
 		val, _ := strconv.ParseFloat(service.ServiceHits, 64)
 		labels := e.buildLabelValues(service.Name)
-		e.gslbServicesVirtualServerServiceHits.WithLabelValues(labels...).Set(val)
+		set.counter(e.gslbServicesVirtualServerServiceHits, val, labels...)
 	}
 }
 
 // Service Group collectors
-func (e *Exporter) collectServiceGroupsState(sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
-	e.serviceGroupsState.Reset()
+func (e *Exporter) collectServiceGroupsState(set *metricSet, sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
 	state := 0.0
 	if sg.State == "UP" {
 		state = 1.0
 	}
 	port := strconv.Itoa(sg.PrimaryPort)
 	labels := e.buildLabelValues(sgName, servername, port)
-	e.serviceGroupsState.WithLabelValues(labels...).Set(state)
+	set.gauge(e.serviceGroupsState, state, labels...)
 }
 
-func (e *Exporter) collectServiceGroupsAvgTTFB(sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
-	e.serviceGroupsAvgTTFB.Reset()
+func (e *Exporter) collectServiceGroupsAvgTTFB(set *metricSet, sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
 	port := strconv.Itoa(sg.PrimaryPort)
 	val, _ := strconv.ParseFloat(sg.AvgTimeToFirstByte, 64)
 	labels := e.buildLabelValues(sgName, servername, port)
-	e.serviceGroupsAvgTTFB.WithLabelValues(labels...).Set(val)
+	set.gauge(e.serviceGroupsAvgTTFB, val, labels...)
 }
 
-func (e *Exporter) collectServiceGroupsTotalRequests(sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
-	e.serviceGroupsTotalRequests.Reset()
+func (e *Exporter) collectServiceGroupsTotalRequests(set *metricSet, sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
 	port := strconv.Itoa(sg.PrimaryPort)
 	val, _ := strconv.ParseFloat(sg.TotalRequests, 64)
 	labels := e.buildLabelValues(sgName, servername, port)
-	e.serviceGroupsTotalRequests.WithLabelValues(labels...).Set(val)
+	set.counter(e.serviceGroupsTotalRequests, val, labels...)
 }
 
-func (e *Exporter) collectServiceGroupsTotalResponses(sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
-	e.serviceGroupsTotalResponses.Reset()
+func (e *Exporter) collectServiceGroupsTotalResponses(set *metricSet, sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
 	port := strconv.Itoa(sg.PrimaryPort)
 	val, _ := strconv.ParseFloat(sg.TotalResponses, 64)
 	labels := e.buildLabelValues(sgName, servername, port)
-	e.serviceGroupsTotalResponses.WithLabelValues(labels...).Set(val)
+	set.counter(e.serviceGroupsTotalResponses, val, labels...)
 }
 
-func (e *Exporter) collectServiceGroupsTotalRequestBytes(sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
-	e.serviceGroupsTotalRequestBytes.Reset()
+func (e *Exporter) collectServiceGroupsTotalRequestBytes(set *metricSet, sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
 	port := strconv.Itoa(sg.PrimaryPort)
 	val, _ := strconv.ParseFloat(sg.TotalRequestBytes, 64)
 	labels := e.buildLabelValues(sgName, servername, port)
-	e.serviceGroupsTotalRequestBytes.WithLabelValues(labels...).Set(val)
+	set.counter(e.serviceGroupsTotalRequestBytes, val, labels...)
 }
 
-func (e *Exporter) collectServiceGroupsTotalResponseBytes(sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
-	e.serviceGroupsTotalResponseBytes.Reset()
+func (e *Exporter) collectServiceGroupsTotalResponseBytes(set *metricSet, sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
 	port := strconv.Itoa(sg.PrimaryPort)
 	val, _ := strconv.ParseFloat(sg.TotalResponseBytes, 64)
 	labels := e.buildLabelValues(sgName, servername, port)
-	e.serviceGroupsTotalResponseBytes.WithLabelValues(labels...).Set(val)
+	set.counter(e.serviceGroupsTotalResponseBytes, val, labels...)
 }
 
-func (e *Exporter) collectServiceGroupsCurrentClientConnections(sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
-	e.serviceGroupsCurrentClientConnections.Reset()
+func (e *Exporter) collectServiceGroupsCurrentClientConnections(set *metricSet, sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
 	port := strconv.Itoa(sg.PrimaryPort)
 	val, _ := strconv.ParseFloat(sg.CurrentClientConnections, 64)
 	labels := e.buildLabelValues(sgName, servername, port)
-	e.serviceGroupsCurrentClientConnections.WithLabelValues(labels...).Set(val)
+	set.gauge(e.serviceGroupsCurrentClientConnections, val, labels...)
 }
 
-func (e *Exporter) collectServiceGroupsSurgeCount(sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
-	e.serviceGroupsSurgeCount.Reset()
+func (e *Exporter) collectServiceGroupsSurgeCount(set *metricSet, sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
 	port := strconv.Itoa(sg.PrimaryPort)
 	val, _ := strconv.ParseFloat(sg.SurgeCount, 64)
 	labels := e.buildLabelValues(sgName, servername, port)
-	e.serviceGroupsSurgeCount.WithLabelValues(labels...).Set(val)
+	set.gauge(e.serviceGroupsSurgeCount, val, labels...)
 }
 
-func (e *Exporter) collectServiceGroupsCurrentServerConnections(sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
-	e.serviceGroupsCurrentServerConnections.Reset()
+func (e *Exporter) collectServiceGroupsCurrentServerConnections(set *metricSet, sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
 	port := strconv.Itoa(sg.PrimaryPort)
 	val, _ := strconv.ParseFloat(sg.CurrentServerConnections, 64)
 	labels := e.buildLabelValues(sgName, servername, port)
-	e.serviceGroupsCurrentServerConnections.WithLabelValues(labels...).Set(val)
+	set.gauge(e.serviceGroupsCurrentServerConnections, val, labels...)
 }
 
-func (e *Exporter) collectServiceGroupsServerEstablishedConnections(sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
-	e.serviceGroupsServerEstablishedConnections.Reset()
+func (e *Exporter) collectServiceGroupsServerEstablishedConnections(set *metricSet, sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
 	port := strconv.Itoa(sg.PrimaryPort)
 	val, _ := strconv.ParseFloat(sg.ServerEstablishedConnections, 64)
 	labels := e.buildLabelValues(sgName, servername, port)
-	e.serviceGroupsServerEstablishedConnections.WithLabelValues(labels...).Set(val)
+	set.gauge(e.serviceGroupsServerEstablishedConnections, val, labels...)
 }
 
-func (e *Exporter) collectServiceGroupsCurrentReusePool(sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
-	e.serviceGroupsCurrentReusePool.Reset()
+func (e *Exporter) collectServiceGroupsCurrentReusePool(set *metricSet, sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
 	port := strconv.Itoa(sg.PrimaryPort)
 	val, _ := strconv.ParseFloat(sg.CurrentReusePool, 64)
 	labels := e.buildLabelValues(sgName, servername, port)
-	e.serviceGroupsCurrentReusePool.WithLabelValues(labels...).Set(val)
+	set.gauge(e.serviceGroupsCurrentReusePool, val, labels...)
 }
 
-func (e *Exporter) collectServiceGroupsMaxClients(sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
-	e.serviceGroupsMaxClients.Reset()
+func (e *Exporter) collectServiceGroupsMaxClients(set *metricSet, sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
 	port := strconv.Itoa(sg.PrimaryPort)
 	val, _ := strconv.ParseFloat(sg.MaxClients, 64)
 	labels := e.buildLabelValues(sgName, servername, port)
-	e.serviceGroupsMaxClients.WithLabelValues(labels...).Set(val)
+	set.gauge(e.serviceGroupsMaxClients, val, labels...)
 }
@@ -10,24 +10,24 @@ import (
 )
 
 // collectSSLStats collects SSL global statistics
-func (e *Exporter) collectSSLStats(ctx context.Context, nsClient *netscaler.NitroClient, ch chan<- prometheus.Metric) {
+func (e *Exporter) collectSSLStats(ctx context.Context, nsClient netscaler.Client, ch chan<- prometheus.Metric) error {
 	stats, err := netscaler.GetSSLStats(ctx, nsClient, "")
 	if err != nil {
 		e.logger.Error("failed to get SSL stats", "url", e.url, "err", err)
-		return
+		return err
 	}
 
 	baseLabels := e.buildLabelValues()
 	ssl := stats.SSLStats
 
 	// Counters
-	e.sendMetric(ch, e.sslTotalTLSv11Sessions, ssl.TotalTLSv11Sessions, baseLabels)
-	e.sendMetric(ch, e.sslTotalSSLv2Sessions, ssl.TotalSSLv2Sessions, baseLabels)
-	e.sendMetric(ch, e.sslTotalSessions, ssl.TotalSessions, baseLabels)
-	e.sendMetric(ch, e.sslTotalSSLv2Handshakes, ssl.TotalSSLv2Handshakes, baseLabels)
-	e.sendMetric(ch, e.sslTotalEnc, ssl.TotalEnc, baseLabels)
+	e.sendCounter(ch, e.sslTotalTLSv11Sessions, ssl.TotalTLSv11Sessions, baseLabels)
+	e.sendCounter(ch, e.sslTotalSSLv2Sessions, ssl.TotalSSLv2Sessions, baseLabels)
+	e.sendCounter(ch, e.sslTotalSessions, ssl.TotalSessions, baseLabels)
+	e.sendCounter(ch, e.sslTotalSSLv2Handshakes, ssl.TotalSSLv2Handshakes, baseLabels)
+	e.sendCounter(ch, e.sslTotalEnc, ssl.TotalEnc, baseLabels)
 	e.sendMetric(ch, e.sslCryptoUtilization, ssl.CryptoUtilizationStat, baseLabels)
-	e.sendMetric(ch, e.sslTotalNewSessions, ssl.TotalNewSessions, baseLabels)
+	e.sendCounter(ch, e.sslTotalNewSessions, ssl.TotalNewSessions, baseLabels)
 
 	// Gauges
 	e.sendMetric(ch, e.sslSessionsRate, ssl.SessionsRate, baseLabels)
@@ -35,120 +35,84 @@ func (e *Exporter) collectSSLStats(ctx context.Context, nsClient *netscaler.Nitr
 	e.sendMetric(ch, e.sslEncRate, ssl.EncRate, baseLabels)
 	e.sendMetric(ch, e.sslSSLv2HandshakesRate, ssl.SSLv2HandshakesRate, baseLabels)
 	e.sendMetric(ch, e.sslNewSessionsRate, ssl.NewSessionsRate, baseLabels)
+
+	return nil
 }
 
 // collectSSLCertKeys collects SSL certificate expiration metrics
-func (e *Exporter) collectSSLCertKeys(ctx context.Context, nsClient *netscaler.NitroClient, ch chan<- prometheus.Metric) {
+func (e *Exporter) collectSSLCertKeys(ctx context.Context, nsClient netscaler.Client, set *metricSet) error {
 	stats, err := netscaler.GetSSLCertKeys(ctx, nsClient, "")
 	if err != nil {
 		e.logger.Error("failed to get SSL cert keys", "url", e.url, "err", err)
-		return
+		return err
 	}
 
-	e.sslCertDaysToExpire.Reset()
 	for _, cert := range stats.SSLCertKeys {
 		val, _ := strconv.ParseFloat(fmt.Sprint(cert.DaysToExpiration), 64)
 		labels := e.buildLabelValues(cert.CertKey)
-		e.sslCertDaysToExpire.WithLabelValues(labels...).Set(val)
+		set.gauge(e.sslCertDaysToExpire, val, labels...)
 	}
-	e.sslCertDaysToExpire.Collect(ch)
+
+	return nil
 }
 
 // collectSSLVServerStats collects SSL virtual server statistics
-func (e *Exporter) collectSSLVServerStats(ctx context.Context, nsClient *netscaler.NitroClient, ch chan<- prometheus.Metric) {
+func (e *Exporter) collectSSLVServerStats(ctx context.Context, nsClient netscaler.Client, set *metricSet) error {
 	stats, err := netscaler.GetSSLVServerStats(ctx, nsClient, "")
 	if err != nil {
 		e.logger.Error("failed to get SSL vserver stats", "url", e.url, "err", err)
-		return
+		return err
 	}
 
-	// Reset all gauges
-	e.sslVServerTotalDecBytes.Reset()
-	e.sslVServerTotalEncBytes.Reset()
-	e.sslVServerTotalHWDecBytes.Reset()
-	e.sslVServerTotalHWEncBytes.Reset()
-	e.sslVServerTotalSessionNew.Reset()
-	e.sslVServerTotalSessionHits.Reset()
-	e.sslVServerTotalClientAuthSuccess.Reset()
-	e.sslVServerTotalClientAuthFailure.Reset()
-	e.sslVServerHealth.Reset()
-	e.sslVServerActiveServices.Reset()
-	e.sslVServerClientAuthSuccessRate.Reset()
-	e.sslVServerClientAuthFailureRate.Reset()
-	e.sslVServerEncBytesRate.Reset()
-	e.sslVServerDecBytesRate.Reset()
-	e.sslVServerHWEncBytesRate.Reset()
-	e.sslVServerHWDecBytesRate.Reset()
-	e.sslVServerSessionNewRate.Reset()
-	e.sslVServerSessionHitsRate.Reset()
-
 	for _, vs := range stats.SSLVServerStats {
 		labels := e.buildLabelValues(vs.VServerName, vs.Type, vs.PrimaryIPAddress)
 
-		setGaugeVal(e.sslVServerTotalDecBytes, labels, vs.TotalDecBytes)
-		setGaugeVal(e.sslVServerTotalEncBytes, labels, vs.TotalEncBytes)
-		setGaugeVal(e.sslVServerTotalHWDecBytes, labels, vs.TotalHWDecBytes)
-		setGaugeVal(e.sslVServerTotalHWEncBytes, labels, vs.TotalHWEncBytes)
-		setGaugeVal(e.sslVServerTotalSessionNew, labels, vs.TotalSessionNew)
-		setGaugeVal(e.sslVServerTotalSessionHits, labels, vs.TotalSessionHits)
-		setGaugeVal(e.sslVServerTotalClientAuthSuccess, labels, vs.TotalClientAuthSuccess)
-		setGaugeVal(e.sslVServerTotalClientAuthFailure, labels, vs.TotalClientAuthFailure)
-		setGaugeVal(e.sslVServerHealth, labels, vs.Health)
-		setGaugeVal(e.sslVServerActiveServices, labels, vs.ActiveServices)
-		setGaugeVal(e.sslVServerClientAuthSuccessRate, labels, vs.ClientAuthSuccessRate)
-		setGaugeVal(e.sslVServerClientAuthFailureRate, labels, vs.ClientAuthFailureRate)
-		setGaugeVal(e.sslVServerEncBytesRate, labels, vs.EncBytesRate)
-		setGaugeVal(e.sslVServerDecBytesRate, labels, vs.DecBytesRate)
-		setGaugeVal(e.sslVServerHWEncBytesRate, labels, vs.HWEncBytesRate)
-		setGaugeVal(e.sslVServerHWDecBytesRate, labels, vs.HWDecBytesRate)
-		setGaugeVal(e.sslVServerSessionNewRate, labels, vs.SessionNewRate)
-		setGaugeVal(e.sslVServerSessionHitsRate, labels, vs.SessionHitsRate)
+		setCounterVal(set, e.sslVServerTotalDecBytes, labels, vs.TotalDecBytes)
+		setCounterVal(set, e.sslVServerTotalEncBytes, labels, vs.TotalEncBytes)
+		setCounterVal(set, e.sslVServerTotalHWDecBytes, labels, vs.TotalHWDecBytes)
+		setCounterVal(set, e.sslVServerTotalHWEncBytes, labels, vs.TotalHWEncBytes)
+		setCounterVal(set, e.sslVServerTotalSessionNew, labels, vs.TotalSessionNew)
+		setCounterVal(set, e.sslVServerTotalSessionHits, labels, vs.TotalSessionHits)
+		setCounterVal(set, e.sslVServerTotalClientAuthSuccess, labels, vs.TotalClientAuthSuccess)
+		setCounterVal(set, e.sslVServerTotalClientAuthFailure, labels, vs.TotalClientAuthFailure)
+		setGaugeVal(set, e.sslVServerHealth, labels, vs.Health)
+		setGaugeVal(set, e.sslVServerActiveServices, labels, vs.ActiveServices)
+		setGaugeVal(set, e.sslVServerClientAuthSuccessRate, labels, vs.ClientAuthSuccessRate)
+		setGaugeVal(set, e.sslVServerClientAuthFailureRate, labels, vs.ClientAuthFailureRate)
+		setGaugeVal(set, e.sslVServerEncBytesRate, labels, vs.EncBytesRate)
+		setGaugeVal(set, e.sslVServerDecBytesRate, labels, vs.DecBytesRate)
+		setGaugeVal(set, e.sslVServerHWEncBytesRate, labels, vs.HWEncBytesRate)
+		setGaugeVal(set, e.sslVServerHWDecBytesRate, labels, vs.HWDecBytesRate)
+		setGaugeVal(set, e.sslVServerSessionNewRate, labels, vs.SessionNewRate)
+		setGaugeVal(set, e.sslVServerSessionHitsRate, labels, vs.SessionHitsRate)
 	}
 
-	// Collect all
-	e.sslVServerTotalDecBytes.Collect(ch)
-	e.sslVServerTotalEncBytes.Collect(ch)
-	e.sslVServerTotalHWDecBytes.Collect(ch)
-	e.sslVServerTotalHWEncBytes.Collect(ch)
-	e.sslVServerTotalSessionNew.Collect(ch)
-	e.sslVServerTotalSessionHits.Collect(ch)
-	e.sslVServerTotalClientAuthSuccess.Collect(ch)
-	e.sslVServerTotalClientAuthFailure.Collect(ch)
-	e.sslVServerHealth.Collect(ch)
-	e.sslVServerActiveServices.Collect(ch)
-	e.sslVServerClientAuthSuccessRate.Collect(ch)
-	e.sslVServerClientAuthFailureRate.Collect(ch)
-	e.sslVServerEncBytesRate.Collect(ch)
-	e.sslVServerDecBytesRate.Collect(ch)
-	e.sslVServerHWEncBytesRate.Collect(ch)
-	e.sslVServerHWDecBytesRate.Collect(ch)
-	e.sslVServerSessionNewRate.Collect(ch)
-	e.sslVServerSessionHitsRate.Collect(ch)
+	return nil
 }
 
 // collectSystemCPUStats collects per-core CPU statistics
-func (e *Exporter) collectSystemCPUStats(ctx context.Context, nsClient *netscaler.NitroClient, ch chan<- prometheus.Metric) {
+func (e *Exporter) collectSystemCPUStats(ctx context.Context, nsClient netscaler.Client, set *metricSet) error {
 	stats, err := netscaler.GetSystemCPUStats(ctx, nsClient, "")
 	if err != nil {
 		e.logger.Error("failed to get system CPU stats", "url", e.url, "err", err)
-		return
+		return err
 	}
 
-	e.cpuCoreUsage.Reset()
 	for _, cpu := range stats.SystemCPUStats {
 		val, _ := strconv.ParseFloat(cpu.PerCPUUsage, 64)
 		labels := e.buildLabelValues(cpu.ID)
-		e.cpuCoreUsage.WithLabelValues(labels...).Set(val)
+		set.gauge(e.cpuCoreUsage, val, labels...)
 	}
-	e.cpuCoreUsage.Collect(ch)
+
+	return nil
 }
 
 // collectNSCapacityStats collects bandwidth capacity statistics
-func (e *Exporter) collectNSCapacityStats(ctx context.Context, nsClient *netscaler.NitroClient, ch chan<- prometheus.Metric) {
+func (e *Exporter) collectNSCapacityStats(ctx context.Context, nsClient netscaler.Client, ch chan<- prometheus.Metric) error {
 	stats, err := netscaler.GetNSCapacityStats(ctx, nsClient, "")
 	if err != nil {
 		e.logger.Error("failed to get bandwidth capacity stats", "url", e.url, "err", err)
-		return
+		return err
 	}
 
 	baseLabels := e.buildLabelValues()
@@ -158,10 +122,12 @@ func (e *Exporter) collectNSCapacityStats(ctx context.Context, nsClient *netscal
 	e.sendMetric(ch, e.capacityMinBandwidth, cap.MinBandwidth, baseLabels)
 	e.sendMetric(ch, e.capacityActualBandwidth, cap.ActualBandwidth, baseLabels)
 	e.sendMetric(ch, e.capacityBandwidth, cap.Bandwidth, baseLabels)
+
+	return nil
 }
 
 // setGaugeVal is a helper to set a gauge value
-func setGaugeVal(g *prometheus.GaugeVec, labels []string, value any) {
+func setGaugeVal(set *metricSet, desc *prometheus.Desc, labels []string, value any) {
 	val, _ := strconv.ParseFloat(fmt.Sprint(value), 64)
-	g.WithLabelValues(labels...).Set(val)
+	set.gauge(desc, val, labels...)
 }
@@ -2,6 +2,7 @@ package collector
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"strconv"
@@ -9,8 +10,6 @@ import (
 	"sync"
 
 	"github.com/elohmeier/netscaler-exporter/netscaler"
-
-	"github.com/prometheus/client_golang/prometheus"
 )
 
 // CSToLBMapping represents a resolved CS vserver → LB vserver relationship.
@@ -21,14 +20,10 @@ type CSToLBMapping struct {
 	PolicyName  string // For policy-based routing
 }
 
-func (e *Exporter) collectTopologyMetrics(ctx context.Context, nsClient *netscaler.NitroClient, ch chan<- prometheus.Metric) {
-	e.topologyNode.Reset()
-	e.topologyEdge.Reset()
-	e.topologyNodeState.Reset()
-	e.topologyNodeHealth.Reset()
-	e.topologyNodeRequestsTotal.Reset()
-	e.topologyNodeConnections.Reset()
-	e.topologyNodeTTFBMs.Reset()
+// collectTopologyMetrics builds the topology graph and returns the chain
+// membership by node ID, which service_groups uses to label its nodes.
+// It returns an error if any of the vserver or service stats could not be fetched.
+func (e *Exporter) collectTopologyMetrics(ctx context.Context, nsClient netscaler.Client, set *metricSet) (map[string]string, error) {
 
 	// Fetch all bindings in parallel using bulk APIs
 	var allSvcBindings []netscaler.LBVServerServiceBinding
@@ -140,12 +135,12 @@ func (e *Exporter) collectTopologyMetrics(ctx context.Context, nsClient *netscal
 	}
 
 	// Build chain membership map
-	e.chainMembership = e.buildChainMembership(csBindingsByVS, svcBindingsByVS, sgBindingsByVS)
+	chainMembership := e.buildChainMembership(csBindingsByVS, svcBindingsByVS, sgBindingsByVS)
 
 	// Collect LB Virtual Server nodes
-	lbVServers, err := netscaler.GetVirtualServerStats(ctx, nsClient, "")
-	if err != nil {
-		e.logger.Error("error getting LB vserver stats for topology", "url", e.url, "err", err)
+	lbVServers, lbErr := netscaler.GetVirtualServerStats(ctx, nsClient, "")
+	if lbErr != nil {
+		e.logger.Error("error getting LB vserver stats for topology", "url", e.url, "err", lbErr)
 	} else {
 		for _, vs := range lbVServers.VirtualServerStats {
 			nodeID := "lbvserver:" + vs.Name
@@ -157,7 +152,7 @@ func (e *Exporter) collectTopologyMetrics(ctx context.Context, nsClient *netscal
 				value = 1.0
 				color = "green"
 			}
-			chain := e.chainMembership[nodeID]
+			chain := chainMembership[nodeID]
 
 			// subtitle shows health and connections
 			subtitle := fmt.Sprintf("Health: %s%%, Conns: %s", vs.Health, vs.CurrentClientConnections)
@@ -166,28 +161,28 @@ func (e *Exporter) collectTopologyMetrics(ctx context.Context, nsClient *netscal
 			labels := e.buildLabelValues(nodeID, vs.Name, subtitle, "lbvserver", state, chain,
 				vs.Health, vs.CurrentClientConnections, color,
 				vs.Health, vs.CurrentClientConnections, vs.TotalRequests, "")
-			e.topologyNode.WithLabelValues(labels...).Set(value)
+			set.gauge(e.topologyNode, value, labels...)
 
 			// Emit topology node stats
 			statsLabels := e.buildLabelValues(nodeID, "lbvserver", chain)
-			e.topologyNodeState.WithLabelValues(statsLabels...).Set(value)
+			set.gauge(e.topologyNodeState, value, statsLabels...)
 
 			if health, err := strconv.ParseFloat(vs.Health, 64); err == nil {
-				e.topologyNodeHealth.WithLabelValues(statsLabels...).Set(health)
+				set.gauge(e.topologyNodeHealth, health, statsLabels...)
 			}
 			if requests, err := strconv.ParseFloat(vs.TotalRequests, 64); err == nil {
-				e.topologyNodeRequestsTotal.WithLabelValues(statsLabels...).Set(requests)
+				set.gauge(e.topologyNodeRequestsTotal, requests, statsLabels...)
 			}
 			if conns, err := strconv.ParseFloat(vs.CurrentClientConnections, 64); err == nil {
-				e.topologyNodeConnections.WithLabelValues(statsLabels...).Set(conns)
+				set.gauge(e.topologyNodeConnections, conns, statsLabels...)
 			}
 		}
 	}
 
 	// Collect CS Virtual Server nodes
-	csVServers, err := netscaler.GetCSVirtualServerStats(ctx, nsClient, "")
-	if err != nil {
-		e.logger.Error("error getting CS vserver stats for topology", "url", e.url, "err", err)
+	csVServers, csErr := netscaler.GetCSVirtualServerStats(ctx, nsClient, "")
+	if csErr != nil {
+		e.logger.Error("error getting CS vserver stats for topology", "url", e.url, "err", csErr)
 	} else {
 		for _, vs := range csVServers.CSVirtualServerStats {
 			nodeID := "csvserver:" + vs.Name
@@ -199,7 +194,7 @@ func (e *Exporter) collectTopologyMetrics(ctx context.Context, nsClient *netscal
 				value = 1.0
 				color = "green"
 			}
-			chain := e.chainMembership[nodeID]
+			chain := chainMembership[nodeID]
 
 			// subtitle shows connections
 			subtitle := fmt.Sprintf("Conns: %s", vs.CurrentClientConnections)
@@ -208,25 +203,25 @@ func (e *Exporter) collectTopologyMetrics(ctx context.Context, nsClient *netscal
 			labels := e.buildLabelValues(nodeID, vs.Name, subtitle, "csvserver", state, chain,
 				vs.CurrentClientConnections, vs.TotalHits, color,
 				"", vs.CurrentClientConnections, vs.TotalHits, "")
-			e.topologyNode.WithLabelValues(labels...).Set(value)
+			set.gauge(e.topologyNode, value, labels...)
 
 			// Emit topology node stats (CS vservers use total_hits as main stat)
 			statsLabels := e.buildLabelValues(nodeID, "csvserver", chain)
-			e.topologyNodeState.WithLabelValues(statsLabels...).Set(value)
+			set.gauge(e.topologyNodeState, value, statsLabels...)
 
 			if hits, err := strconv.ParseFloat(vs.TotalHits, 64); err == nil {
-				e.topologyNodeRequestsTotal.WithLabelValues(statsLabels...).Set(hits)
+				set.gauge(e.topologyNodeRequestsTotal, hits, statsLabels...)
 			}
 			if conns, err := strconv.ParseFloat(vs.CurrentClientConnections, 64); err == nil {
-				e.topologyNodeConnections.WithLabelValues(statsLabels...).Set(conns)
+				set.gauge(e.topologyNodeConnections, conns, statsLabels...)
 			}
 		}
 	}
 
 	// Collect Service nodes
-	services, err := netscaler.GetServiceStats(ctx, nsClient, "")
-	if err != nil {
-		e.logger.Error("error getting service stats for topology", "url", e.url, "err", err)
+	services, svcErr := netscaler.GetServiceStats(ctx, nsClient, "")
+	if svcErr != nil {
+		e.logger.Error("error getting service stats for topology", "url", e.url, "err", svcErr)
 	} else {
 		for _, svc := range services.ServiceStats {
 			nodeID := "service:" + svc.Name
@@ -238,13 +233,13 @@ func (e *Exporter) collectTopologyMetrics(ctx context.Context, nsClient *netscal
 				value = 1.0
 				color = "green"
 			}
-			chain := e.chainMembership[nodeID]
+			chain := chainMembership[nodeID]
 
 			// Services: limited stats available
 			labels := e.buildLabelValues(nodeID, svc.Name, "", "service", state, chain,
 				"", "", color,
 				"", "", "", "")
-			e.topologyNode.WithLabelValues(labels...).Set(value)
+			set.gauge(e.topologyNode, value, labels...)
 		}
 	}
 
@@ -255,7 +250,7 @@ func (e *Exporter) collectTopologyMetrics(ctx context.Context, nsClient *netscal
 	if len(lbVServers.VirtualServerStats) > 0 {
 		for _, vs := range lbVServers.VirtualServerStats {
 			sourceID := "lbvserver:" + vs.Name
-			sourceChain := e.chainMembership[sourceID]
+			sourceChain := chainMembership[sourceID]
 
 			// Service bindings from lookup map
 			for _, b := range svcBindingsByVS[vs.Name] {
@@ -267,7 +262,7 @@ func (e *Exporter) collectTopologyMetrics(ctx context.Context, nsClient *netscal
 				}
 				mainstat := "weight: " + weight
 				labels := e.buildLabelValues(edgeID, sourceID, targetID, weight, "", sourceChain, mainstat, "")
-				e.topologyEdge.WithLabelValues(labels...).Set(1)
+				set.gauge(e.topologyEdge, 1, labels...)
 			}
 
 			// Service group bindings from lookup map
@@ -280,7 +275,7 @@ func (e *Exporter) collectTopologyMetrics(ctx context.Context, nsClient *netscal
 				}
 				mainstat := "weight: " + weight
 				labels := e.buildLabelValues(edgeID, sourceID, targetID, weight, "", sourceChain, mainstat, "")
-				e.topologyEdge.WithLabelValues(labels...).Set(1)
+				set.gauge(e.topologyEdge, 1, labels...)
 			}
 		}
 	}
@@ -289,7 +284,7 @@ func (e *Exporter) collectTopologyMetrics(ctx context.Context, nsClient *netscal
 	if len(csVServers.CSVirtualServerStats) > 0 {
 		for _, vs := range csVServers.CSVirtualServerStats {
 			sourceID := "csvserver:" + vs.Name
-			sourceChain := e.chainMembership[sourceID]
+			sourceChain := chainMembership[sourceID]
 
 			for _, m := range csBindingsByVS[vs.Name] {
 				edgeID := "csvserver:" + m.CSVServer + "->lbvserver:" + m.LBVServer
@@ -300,12 +295,13 @@ func (e *Exporter) collectTopologyMetrics(ctx context.Context, nsClient *netscal
 				}
 				mainstat := "priority: " + priority
 				labels := e.buildLabelValues(edgeID, sourceID, targetID, "", priority, sourceChain, mainstat, "")
-				e.topologyEdge.WithLabelValues(labels...).Set(1)
+				set.gauge(e.topologyEdge, 1, labels...)
 			}
 		}
 	}
 
-	// Note: Collect() is called later in scrapeADC after service_groups has added its nodes/edges
+	// Note: the set is flushed later in scrapeADC after service_groups has added its nodes/edges
+	return chainMembership, errors.Join(lbErr, csErr, svcErr)
 }
 
 // resolveCSToLBMappings resolves all CS vserver → LB vserver relationships from multiple sources:
@@ -7,431 +7,385 @@ import (
 )
 
 // LB Virtual Server collectors
-func (e *Exporter) collectVirtualServerState(ns netscaler.NSAPIResponse) {
-	e.virtualServersState.Reset()
+func (e *Exporter) collectVirtualServerState(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.VirtualServerStats {
 		state := 0.0
 		if vs.State == "UP" {
 			state = 1.0
 		}
 		labels := e.buildLabelValues(vs.Name)
-		e.virtualServersState.WithLabelValues(labels...).Set(state)
+		set.gauge(e.virtualServersState, state, labels...)
 	}
 }
 
-func (e *Exporter) collectVirtualServerWaitingRequests(ns netscaler.NSAPIResponse) {
-	e.virtualServersWaitingRequests.Reset()
+func (e *Exporter) collectVirtualServerWaitingRequests(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.VirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.WaitingRequests, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.virtualServersWaitingRequests.WithLabelValues(labels...).Set(val)
+		set.gauge(e.virtualServersWaitingRequests, val, labels...)
 	}
 }
 
-func (e *Exporter) collectVirtualServerHealth(ns netscaler.NSAPIResponse) {
-	e.virtualServersHealth.Reset()
+func (e *Exporter) collectVirtualServerHealth(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.VirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.Health, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.virtualServersHealth.WithLabelValues(labels...).Set(val)
+		set.gauge(e.virtualServersHealth, val, labels...)
 	}
 }
 
-func (e *Exporter) collectVirtualServerInactiveServices(ns netscaler.NSAPIResponse) {
-	e.virtualServersInactiveServices.Reset()
+func (e *Exporter) collectVirtualServerInactiveServices(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.VirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.InactiveServices, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.virtualServersInactiveServices.WithLabelValues(labels...).Set(val)
+		set.gauge(e.virtualServersInactiveServices, val, labels...)
 	}
 }
 
-func (e *Exporter) collectVirtualServerActiveServices(ns netscaler.NSAPIResponse) {
-	e.virtualServersActiveServices.Reset()
+func (e *Exporter) collectVirtualServerActiveServices(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.VirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.ActiveServices, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.virtualServersActiveServices.WithLabelValues(labels...).Set(val)
+		set.gauge(e.virtualServersActiveServices, val, labels...)
 	}
 }
 
-func (e *Exporter) collectVirtualServerTotalHits(ns netscaler.NSAPIResponse) {
-	e.virtualServersTotalHits.Reset()
+func (e *Exporter) collectVirtualServerTotalHits(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.VirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.TotalHits, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.virtualServersTotalHits.WithLabelValues(labels...).Set(val)
+		set.counter(e.virtualServersTotalHits, val, labels...)
 	}
 }
 
-func (e *Exporter) collectVirtualServerTotalRequests(ns netscaler.NSAPIResponse) {
-	e.virtualServersTotalRequests.Reset()
+func (e *Exporter) collectVirtualServerTotalRequests(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.VirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.TotalRequests, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.virtualServersTotalRequests.WithLabelValues(labels...).Set(val)
+		set.counter(e.virtualServersTotalRequests, val, labels...)
 	}
 }
 
-func (e *Exporter) collectVirtualServerTotalResponses(ns netscaler.NSAPIResponse) {
-	e.virtualServersTotalResponses.Reset()
+func (e *Exporter) collectVirtualServerTotalResponses(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.VirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.TotalResponses, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.virtualServersTotalResponses.WithLabelValues(labels...).Set(val)
+		set.counter(e.virtualServersTotalResponses, val, labels...)
 	}
 }
 
-func (e *Exporter) collectVirtualServerTotalRequestBytes(ns netscaler.NSAPIResponse) {
-	e.virtualServersTotalRequestBytes.Reset()
+func (e *Exporter) collectVirtualServerTotalRequestBytes(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.VirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.TotalRequestBytes, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.virtualServersTotalRequestBytes.WithLabelValues(labels...).Set(val)
+		set.counter(e.virtualServersTotalRequestBytes, val, labels...)
 	}
 }
 
-func (e *Exporter) collectVirtualServerTotalResponseBytes(ns netscaler.NSAPIResponse) {
-	e.virtualServersTotalResponseBytes.Reset()
+func (e *Exporter) collectVirtualServerTotalResponseBytes(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.VirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.TotalResponseBytes, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.virtualServersTotalResponseBytes.WithLabelValues(labels...).Set(val)
+		set.counter(e.virtualServersTotalResponseBytes, val, labels...)
 	}
 }
 
-func (e *Exporter) collectVirtualServerCurrentClientConnections(ns netscaler.NSAPIResponse) {
-	e.virtualServersCurrentClientConnections.Reset()
+func (e *Exporter) collectVirtualServerCurrentClientConnections(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.VirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.CurrentClientConnections, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.virtualServersCurrentClientConnections.WithLabelValues(labels...).Set(val)
+		set.gauge(e.virtualServersCurrentClientConnections, val, labels...)
 	}
 }
 
-func (e *Exporter) collectVirtualServerCurrentServerConnections(ns netscaler.NSAPIResponse) {
-	e.virtualServersCurrentServerConnections.Reset()
+func (e *Exporter) collectVirtualServerCurrentServerConnections(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.VirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.CurrentServerConnections, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.virtualServersCurrentServerConnections.WithLabelValues(labels...).Set(val)
+		set.gauge(e.virtualServersCurrentServerConnections, val, labels...)
 	}
 }
 
 // GSLB Virtual Server collectors
-func (e *Exporter) collectGSLBVirtualServerState(ns netscaler.NSAPIResponse) {
-	e.gslbVirtualServersState.Reset()
+func (e *Exporter) collectGSLBVirtualServerState(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.GSLBVirtualServerStats {
 		state := 0.0
 		if vs.State == "UP" {
 			state = 1.0
 		}
 		labels := e.buildLabelValues(vs.Name)
-		e.gslbVirtualServersState.WithLabelValues(labels...).Set(state)
+		set.gauge(e.gslbVirtualServersState, state, labels...)
 	}
 }
 
-func (e *Exporter) collectGSLBVirtualServerHealth(ns netscaler.NSAPIResponse) {
-	e.gslbVirtualServersHealth.Reset()
+func (e *Exporter) collectGSLBVirtualServerHealth(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.GSLBVirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.Health, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.gslbVirtualServersHealth.WithLabelValues(labels...).Set(val)
+		set.gauge(e.gslbVirtualServersHealth, val, labels...)
 	}
 }
 
-func (e *Exporter) collectGSLBVirtualServerInactiveServices(ns netscaler.NSAPIResponse) {
-	e.gslbVirtualServersInactiveServices.Reset()
+func (e *Exporter) collectGSLBVirtualServerInactiveServices(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.GSLBVirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.InactiveServices, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.gslbVirtualServersInactiveServices.WithLabelValues(labels...).Set(val)
+		set.gauge(e.gslbVirtualServersInactiveServices, val, labels...)
 	}
 }
 
-func (e *Exporter) collectGSLBVirtualServerActiveServices(ns netscaler.NSAPIResponse) {
-	e.gslbVirtualServersActiveServices.Reset()
+func (e *Exporter) collectGSLBVirtualServerActiveServices(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.GSLBVirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.ActiveServices, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.gslbVirtualServersActiveServices.WithLabelValues(labels...).Set(val)
+		set.gauge(e.gslbVirtualServersActiveServices, val, labels...)
 	}
 }
 
-func (e *Exporter) collectGSLBVirtualServerTotalHits(ns netscaler.NSAPIResponse) {
-	e.gslbVirtualServersTotalHits.Reset()
+func (e *Exporter) collectGSLBVirtualServerTotalHits(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.GSLBVirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.TotalHits, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.gslbVirtualServersTotalHits.WithLabelValues(labels...).Set(val)
+		set.counter(e.gslbVirtualServersTotalHits, val, labels...)
 	}
 }
 
-func (e *Exporter) collectGSLBVirtualServerTotalRequests(ns netscaler.NSAPIResponse) {
-	e.gslbVirtualServersTotalRequests.Reset()
+func (e *Exporter) collectGSLBVirtualServerTotalRequests(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.GSLBVirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.TotalRequests, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.gslbVirtualServersTotalRequests.WithLabelValues(labels...).Set(val)
+		set.counter(e.gslbVirtualServersTotalRequests, val, labels...)
 	}
 }
 
-func (e *Exporter) collectGSLBVirtualServerTotalResponses(ns netscaler.NSAPIResponse) {
-	e.gslbVirtualServersTotalResponses.Reset()
+func (e *Exporter) collectGSLBVirtualServerTotalResponses(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.GSLBVirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.TotalResponses, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.gslbVirtualServersTotalResponses.WithLabelValues(labels...).Set(val)
+		set.counter(e.gslbVirtualServersTotalResponses, val, labels...)
 	}
 }
 
-func (e *Exporter) collectGSLBVirtualServerTotalRequestBytes(ns netscaler.NSAPIResponse) {
-	e.gslbVirtualServersTotalRequestBytes.Reset()
+func (e *Exporter) collectGSLBVirtualServerTotalRequestBytes(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.GSLBVirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.TotalRequestBytes, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.gslbVirtualServersTotalRequestBytes.WithLabelValues(labels...).Set(val)
+		set.counter(e.gslbVirtualServersTotalRequestBytes, val, labels...)
 	}
 }
 
-func (e *Exporter) collectGSLBVirtualServerTotalResponseBytes(ns netscaler.NSAPIResponse) {
-	e.gslbVirtualServersTotalResponseBytes.Reset()
+func (e *Exporter) collectGSLBVirtualServerTotalResponseBytes(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.GSLBVirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.TotalResponseBytes, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.gslbVirtualServersTotalResponseBytes.WithLabelValues(labels...).Set(val)
+		set.counter(e.gslbVirtualServersTotalResponseBytes, val, labels...)
 	}
 }
 
-func (e *Exporter) collectGSLBVirtualServerCurrentClientConnections(ns netscaler.NSAPIResponse) {
-	e.gslbVirtualServersCurrentClientConnections.Reset()
+func (e *Exporter) collectGSLBVirtualServerCurrentClientConnections(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.GSLBVirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.CurrentClientConnections, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.gslbVirtualServersCurrentClientConnections.WithLabelValues(labels...).Set(val)
+		set.gauge(e.gslbVirtualServersCurrentClientConnections, val, labels...)
 	}
 }
 
-func (e *Exporter) collectGSLBVirtualServerCurrentServerConnections(ns netscaler.NSAPIResponse) {
-	e.gslbVirtualServersCurrentServerConnections.Reset()
+func (e *Exporter) collectGSLBVirtualServerCurrentServerConnections(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.GSLBVirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.CurrentServerConnections, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.gslbVirtualServersCurrentServerConnections.WithLabelValues(labels...).Set(val)
+		set.gauge(e.gslbVirtualServersCurrentServerConnections, val, labels...)
 	}
 }
 
 // CS Virtual Server collectors
-func (e *Exporter) collectCSVirtualServerState(ns netscaler.NSAPIResponse) {
-	e.csVirtualServersState.Reset()
+func (e *Exporter) collectCSVirtualServerState(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.CSVirtualServerStats {
 		state := 0.0
 		if vs.State == "UP" {
 			state = 1.0
 		}
 		labels := e.buildLabelValues(vs.Name)
-		e.csVirtualServersState.WithLabelValues(labels...).Set(state)
+		set.gauge(e.csVirtualServersState, state, labels...)
 	}
 }
 
-func (e *Exporter) collectCSVirtualServerTotalHits(ns netscaler.NSAPIResponse) {
-	e.csVirtualServersTotalHits.Reset()
+func (e *Exporter) collectCSVirtualServerTotalHits(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.CSVirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.TotalHits, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.csVirtualServersTotalHits.WithLabelValues(labels...).Set(val)
+		set.counter(e.csVirtualServersTotalHits, val, labels...)
 	}
 }
 
-func (e *Exporter) collectCSVirtualServerTotalRequests(ns netscaler.NSAPIResponse) {
-	e.csVirtualServersTotalRequests.Reset()
+func (e *Exporter) collectCSVirtualServerTotalRequests(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.CSVirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.TotalRequests, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.csVirtualServersTotalRequests.WithLabelValues(labels...).Set(val)
+		set.counter(e.csVirtualServersTotalRequests, val, labels...)
 	}
 }
 
-func (e *Exporter) collectCSVirtualServerTotalResponses(ns netscaler.NSAPIResponse) {
-	e.csVirtualServersTotalResponses.Reset()
+func (e *Exporter) collectCSVirtualServerTotalResponses(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.CSVirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.TotalResponses, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.csVirtualServersTotalResponses.WithLabelValues(labels...).Set(val)
+		set.counter(e.csVirtualServersTotalResponses, val, labels...)
 	}
 }
 
-func (e *Exporter) collectCSVirtualServerTotalRequestBytes(ns netscaler.NSAPIResponse) {
-	e.csVirtualServersTotalRequestBytes.Reset()
+func (e *Exporter) collectCSVirtualServerTotalRequestBytes(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.CSVirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.TotalRequestBytes, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.csVirtualServersTotalRequestBytes.WithLabelValues(labels...).Set(val)
+		set.counter(e.csVirtualServersTotalRequestBytes, val, labels...)
 	}
 }
 
-func (e *Exporter) collectCSVirtualServerTotalResponseBytes(ns netscaler.NSAPIResponse) {
-	e.csVirtualServersTotalResponseBytes.Reset()
+func (e *Exporter) collectCSVirtualServerTotalResponseBytes(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.CSVirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.TotalResponseBytes, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.csVirtualServersTotalResponseBytes.WithLabelValues(labels...).Set(val)
+		set.counter(e.csVirtualServersTotalResponseBytes, val, labels...)
 	}
 }
 
-func (e *Exporter) collectCSVirtualServerCurrentClientConnections(ns netscaler.NSAPIResponse) {
-	e.csVirtualServersCurrentClientConnections.Reset()
+func (e *Exporter) collectCSVirtualServerCurrentClientConnections(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.CSVirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.CurrentClientConnections, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.csVirtualServersCurrentClientConnections.WithLabelValues(labels...).Set(val)
+		set.gauge(e.csVirtualServersCurrentClientConnections, val, labels...)
 	}
 }
 
-func (e *Exporter) collectCSVirtualServerCurrentServerConnections(ns netscaler.NSAPIResponse) {
-	e.csVirtualServersCurrentServerConnections.Reset()
+func (e *Exporter) collectCSVirtualServerCurrentServerConnections(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.CSVirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.CurrentServerConnections, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.csVirtualServersCurrentServerConnections.WithLabelValues(labels...).Set(val)
+		set.gauge(e.csVirtualServersCurrentServerConnections, val, labels...)
 	}
 }
 
-func (e *Exporter) collectCSVirtualServerEstablishedConnections(ns netscaler.NSAPIResponse) {
-	e.csVirtualServersEstablishedConnections.Reset()
+func (e *Exporter) collectCSVirtualServerEstablishedConnections(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.CSVirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.EstablishedConnections, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.csVirtualServersEstablishedConnections.WithLabelValues(labels...).Set(val)
+		set.gauge(e.csVirtualServersEstablishedConnections, val, labels...)
 	}
 }
 
-func (e *Exporter) collectCSVirtualServerTotalPacketsReceived(ns netscaler.NSAPIResponse) {
-	e.csVirtualServersTotalPacketsReceived.Reset()
+func (e *Exporter) collectCSVirtualServerTotalPacketsReceived(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.CSVirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.TotalPacketsReceived, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.csVirtualServersTotalPacketsReceived.WithLabelValues(labels...).Set(val)
+		set.counter(e.csVirtualServersTotalPacketsReceived, val, labels...)
 	}
 }
 
-func (e *Exporter) collectCSVirtualServerTotalPacketsSent(ns netscaler.NSAPIResponse) {
-	e.csVirtualServersTotalPacketsSent.Reset()
+func (e *Exporter) collectCSVirtualServerTotalPacketsSent(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.CSVirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.TotalPacketsSent, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.csVirtualServersTotalPacketsSent.WithLabelValues(labels...).Set(val)
+		set.counter(e.csVirtualServersTotalPacketsSent, val, labels...)
 	}
 }
 
-func (e *Exporter) collectCSVirtualServerTotalSpillovers(ns netscaler.NSAPIResponse) {
-	e.csVirtualServersTotalSpillovers.Reset()
+func (e *Exporter) collectCSVirtualServerTotalSpillovers(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.CSVirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.TotalSpillovers, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.csVirtualServersTotalSpillovers.WithLabelValues(labels...).Set(val)
+		set.counter(e.csVirtualServersTotalSpillovers, val, labels...)
 	}
 }
 
-func (e *Exporter) collectCSVirtualServerDeferredRequests(ns netscaler.NSAPIResponse) {
-	e.csVirtualServersDeferredRequests.Reset()
+func (e *Exporter) collectCSVirtualServerDeferredRequests(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.CSVirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.DeferredRequests, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.csVirtualServersDeferredRequests.WithLabelValues(labels...).Set(val)
+		set.counter(e.csVirtualServersDeferredRequests, val, labels...)
 	}
 }
 
-func (e *Exporter) collectCSVirtualServerNumberInvalidRequestResponse(ns netscaler.NSAPIResponse) {
-	e.csVirtualServersNumberInvalidRequestResponse.Reset()
+func (e *Exporter) collectCSVirtualServerNumberInvalidRequestResponse(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.CSVirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.InvalidRequestResponse, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.csVirtualServersNumberInvalidRequestResponse.WithLabelValues(labels...).Set(val)
+		set.counter(e.csVirtualServersNumberInvalidRequestResponse, val, labels...)
 	}
 }
 
-func (e *Exporter) collectCSVirtualServerNumberInvalidRequestResponseDropped(ns netscaler.NSAPIResponse) {
-	e.csVirtualServersNumberInvalidRequestResponseDropped.Reset()
+func (e *Exporter) collectCSVirtualServerNumberInvalidRequestResponseDropped(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.CSVirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.InvalidRequestResponseDropped, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.csVirtualServersNumberInvalidRequestResponseDropped.WithLabelValues(labels...).Set(val)
+		set.counter(e.csVirtualServersNumberInvalidRequestResponseDropped, val, labels...)
 	}
 }
 
-func (e *Exporter) collectCSVirtualServerTotalVServerDownBackupHits(ns netscaler.NSAPIResponse) {
-	e.csVirtualServersTotalVServerDownBackupHits.Reset()
+func (e *Exporter) collectCSVirtualServerTotalVServerDownBackupHits(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.CSVirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.TotalVServerDownBackupHits, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.csVirtualServersTotalVServerDownBackupHits.WithLabelValues(labels...).Set(val)
+		set.counter(e.csVirtualServersTotalVServerDownBackupHits, val, labels...)
 	}
 }
 
-func (e *Exporter) collectCSVirtualServerCurrentMultipathSessions(ns netscaler.NSAPIResponse) {
-	e.csVirtualServersCurrentMultipathSessions.Reset()
+func (e *Exporter) collectCSVirtualServerCurrentMultipathSessions(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.CSVirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.CurrentMultipathSessions, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.csVirtualServersCurrentMultipathSessions.WithLabelValues(labels...).Set(val)
+		set.gauge(e.csVirtualServersCurrentMultipathSessions, val, labels...)
 	}
 }
 
-func (e *Exporter) collectCSVirtualServerCurrentMultipathSubflows(ns netscaler.NSAPIResponse) {
-	e.csVirtualServersCurrentMultipathSubflows.Reset()
+func (e *Exporter) collectCSVirtualServerCurrentMultipathSubflows(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.CSVirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.CurrentMultipathSubflows, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.csVirtualServersCurrentMultipathSubflows.WithLabelValues(labels...).Set(val)
+		set.gauge(e.csVirtualServersCurrentMultipathSubflows, val, labels...)
 	}
 }
 
 // VPN Virtual Server collectors
-func (e *Exporter) collectVPNVirtualServerTotalRequests(ns netscaler.NSAPIResponse) {
-	e.vpnVirtualServersTotalRequests.Reset()
+func (e *Exporter) collectVPNVirtualServerTotalRequests(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.VPNVirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.TotalRequests, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.vpnVirtualServersTotalRequests.WithLabelValues(labels...).Set(val)
+		set.counter(e.vpnVirtualServersTotalRequests, val, labels...)
 	}
 }
 
-func (e *Exporter) collectVPNVirtualServerTotalResponses(ns netscaler.NSAPIResponse) {
-	e.vpnVirtualServersTotalResponses.Reset()
+func (e *Exporter) collectVPNVirtualServerTotalResponses(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.VPNVirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.TotalResponses, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.vpnVirtualServersTotalResponses.WithLabelValues(labels...).Set(val)
+		set.counter(e.vpnVirtualServersTotalResponses, val, labels...)
 	}
 }
 
-func (e *Exporter) collectVPNVirtualServerTotalRequestBytes(ns netscaler.NSAPIResponse) {
-	e.vpnVirtualServersTotalRequestBytes.Reset()
+func (e *Exporter) collectVPNVirtualServerTotalRequestBytes(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.VPNVirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.TotalRequestBytes, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.vpnVirtualServersTotalRequestBytes.WithLabelValues(labels...).Set(val)
+		set.counter(e.vpnVirtualServersTotalRequestBytes, val, labels...)
 	}
 }
 
-func (e *Exporter) collectVPNVirtualServerTotalResponseBytes(ns netscaler.NSAPIResponse) {
-	e.vpnVirtualServersTotalResponseBytes.Reset()
+func (e *Exporter) collectVPNVirtualServerTotalResponseBytes(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.VPNVirtualServerStats {
 		val, _ := strconv.ParseFloat(vs.TotalResponseBytes, 64)
 		labels := e.buildLabelValues(vs.Name)
-		e.vpnVirtualServersTotalResponseBytes.WithLabelValues(labels...).Set(val)
+		set.counter(e.vpnVirtualServersTotalResponseBytes, val, labels...)
 	}
 }
 
-func (e *Exporter) collectVPNVirtualServerState(ns netscaler.NSAPIResponse) {
-	e.vpnVirtualServersState.Reset()
+func (e *Exporter) collectVPNVirtualServerState(set *metricSet, ns netscaler.NSAPIResponse) {
 	for _, vs := range ns.VPNVirtualServerStats {
 		state := 0.0
 		if vs.State == "UP" {
 			state = 1.0
 		}
 		labels := e.buildLabelValues(vs.Name)
-		e.vpnVirtualServersState.WithLabelValues(labels...).Set(state)
+		set.gauge(e.vpnVirtualServersState, state, labels...)
 	}
 }
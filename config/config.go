@@ -4,12 +4,36 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"time"
 )
 
+// DefaultModule is the name of the probe module built from CLI flags and environment variables.
+const DefaultModule = "default"
+
 // Config holds the exporter configuration.
 type Config struct {
 	Labels          map[string]string
 	DisabledModules []string
+
+	// LegacyMetricNames additionally publishes cumulative counters under
+	// their metric schema 1 gauge names.
+	LegacyMetricNames bool
+
+	// Timeout bounds each module's scrape; Timeouts overrides it per module.
+	// Zero means the module is only bounded by the scrape deadline.
+	Timeout  time.Duration
+	Timeouts map[string]time.Duration
+
+	// CapabilityRefresh is how often the appliance's license, features and
+	// HA setup are re-read to skip unsupported modules. Zero disables detection.
+	CapabilityRefresh time.Duration
+
+	// BreakerFailures is the number of consecutive failures after which a
+	// module is suspended for BreakerBackoff, doubled on every failed retry up
+	// to BreakerMaxBackoff. Zero disables the circuit breaker.
+	BreakerFailures   int
+	BreakerBackoff    time.Duration
+	BreakerMaxBackoff time.Duration
 }
 
 // IsModuleDisabled returns true if the given module name is in the disabled list.
@@ -22,6 +46,14 @@ func (c *Config) IsModuleDisabled(name string) bool {
 	return false
 }
 
+// ModuleTimeout returns the scrape timeout of the given module, or 0 if none is set.
+func (c *Config) ModuleTimeout(name string) time.Duration {
+	if t, ok := c.Timeouts[name]; ok {
+		return t
+	}
+	return c.Timeout
+}
+
 // LabelKeys returns the sorted list of label keys.
 func (c *Config) LabelKeys() []string {
 	keys := make([]string, 0, len(c.Labels))
@@ -40,6 +72,14 @@ func GetCredentials() (username, password string) {
 	return username, password
 }
 
+// GetCredentialFiles reads the paths of files holding the credentials from
+// environment variables. The files take precedence over GetCredentials.
+func GetCredentialFiles() (usernameFile, passwordFile string) {
+	usernameFile = os.Getenv("NETSCALER_USERNAME_FILE")
+	passwordFile = os.Getenv("NETSCALER_PASSWORD_FILE")
+	return usernameFile, passwordFile
+}
+
 // GetIgnoreCert reads the ignore cert setting from environment variable.
 func GetIgnoreCert() bool {
 	val := strings.ToLower(os.Getenv("NETSCALER_IGNORE_CERT"))
@@ -51,6 +91,17 @@ func GetCAFile() string {
 	return os.Getenv("NETSCALER_CA_FILE")
 }
 
+// GetClientCert reads the client certificate and key file paths for mutual
+// TLS from environment variables.
+func GetClientCert() (certFile, keyFile string) {
+	return os.Getenv("NETSCALER_CLIENT_CERT_FILE"), os.Getenv("NETSCALER_CLIENT_KEY_FILE")
+}
+
+// GetAuthMode reads the authentication mode from environment variable.
+func GetAuthMode() string {
+	return os.Getenv("NETSCALER_AUTH_MODE")
+}
+
 // GetURL reads the URL from environment variable.
 func GetURL() string {
 	return os.Getenv("NETSCALER_URL")
@@ -98,17 +149,22 @@ func ParseLabels(labelsStr string) map[string]string {
 
 // ParseDisabledModules parses a comma-separated list of module names.
 func ParseDisabledModules(modulesStr string) []string {
-	if modulesStr == "" {
+	return ParseList(modulesStr)
+}
+
+// ParseList parses a comma-separated list, dropping empty entries.
+func ParseList(listStr string) []string {
+	if listStr == "" {
 		return nil
 	}
 
-	parts := strings.Split(modulesStr, ",")
-	modules := make([]string, 0, len(parts))
+	parts := strings.Split(listStr, ",")
+	items := make([]string, 0, len(parts))
 	for _, part := range parts {
 		part = strings.TrimSpace(part)
 		if part != "" {
-			modules = append(modules, part)
+			items = append(items, part)
 		}
 	}
-	return modules
+	return items
 }
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CredentialCheckInterval is how often FileCredentials re-read their files.
+const CredentialCheckInterval = 10 * time.Second
+
+// FileCredentials are a username and password, each given either directly or
+// as a file holding it, e.g. a mounted Kubernetes secret. The files are re-read
+// at most every CredentialCheckInterval, so rotated credentials are picked up
+// without a restart. It satisfies netscaler.Credentials.
+type FileCredentials struct {
+	username     string
+	password     string
+	usernameFile string
+	passwordFile string
+
+	mu      sync.Mutex
+	checked time.Time
+	user    string
+	pass    string
+	err     error
+}
+
+// NewFileCredentials returns credentials read from usernameFile and
+// passwordFile where set, and username and password otherwise.
+func NewFileCredentials(username, password, usernameFile, passwordFile string) *FileCredentials {
+	return &FileCredentials{
+		username:     username,
+		password:     password,
+		usernameFile: usernameFile,
+		passwordFile: passwordFile,
+	}
+}
+
+// Get returns the current username and password, re-reading the files if
+// they were last read more than CredentialCheckInterval ago.
+func (c *FileCredentials) Get() (string, string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.checked.IsZero() || time.Since(c.checked) >= CredentialCheckInterval {
+		c.readLocked()
+	}
+	return c.user, c.pass, c.err
+}
+
+// Reload re-reads the files right away.
+func (c *FileCredentials) Reload() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.readLocked()
+	return c.err
+}
+
+// readLocked reads the files into the current credentials. After an error,
+// Get fails until the files can be read again. The caller must hold c.mu.
+func (c *FileCredentials) readLocked() {
+	c.checked = time.Now()
+
+	user, err := readSecret(c.username, c.usernameFile)
+	if err != nil {
+		c.err = fmt.Errorf("failed to read username file: %w", err)
+		return
+	}
+	pass, err := readSecret(c.password, c.passwordFile)
+	if err != nil {
+		c.err = fmt.Errorf("failed to read password file: %w", err)
+		return
+	}
+	c.user, c.pass, c.err = user, pass, nil
+}
+
+// readSecret returns the content of path without trailing newlines, or value
+// if path is empty.
+func readSecret(value, path string) (string, error) {
+	if path == "" {
+		return value, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeSecret(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("writing secret: %v", err)
+	}
+}
+
+// TestFileCredentials checks that credentials come from the files where set,
+// without trailing newlines, and from the values otherwise.
+func TestFileCredentials(t *testing.T) {
+	dir := t.TempDir()
+	userFile, passFile := filepath.Join(dir, "username"), filepath.Join(dir, "password")
+	writeSecret(t, userFile, "nsroot\n")
+	writeSecret(t, passFile, "s3cret \r\n\n")
+
+	tests := []struct {
+		name               string
+		creds              *FileCredentials
+		wantUser, wantPass string
+	}{
+		{name: "values", creds: NewFileCredentials("nsroot", "secret", "", ""), wantUser: "nsroot", wantPass: "secret"},
+		{name: "files", creds: NewFileCredentials("", "", userFile, passFile), wantUser: "nsroot", wantPass: "s3cret "},
+		{name: "password file", creds: NewFileCredentials("reader", "ignored", "", passFile), wantUser: "reader", wantPass: "s3cret "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, pass, err := tt.creds.Get()
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			if user != tt.wantUser || pass != tt.wantPass {
+				t.Errorf("Get = %q, %q, want %q, %q", user, pass, tt.wantUser, tt.wantPass)
+			}
+		})
+	}
+}
+
+// TestFileCredentialsRotation checks that rotated files are picked up by
+// Reload right away and by Get after CredentialCheckInterval only.
+func TestFileCredentialsRotation(t *testing.T) {
+	passFile := filepath.Join(t.TempDir(), "password")
+	writeSecret(t, passFile, "old\n")
+	creds := NewFileCredentials("nsroot", "", "", passFile)
+	if _, pass, err := creds.Get(); err != nil || pass != "old" {
+		t.Fatalf("Get = %q, %v, want old", pass, err)
+	}
+
+	writeSecret(t, passFile, "new\n")
+	if _, pass, _ := creds.Get(); pass != "old" {
+		t.Errorf("Get = %q before the check interval, want the cached old", pass)
+	}
+	if err := creds.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if _, pass, _ := creds.Get(); pass != "new" {
+		t.Errorf("Get = %q after Reload, want new", pass)
+	}
+
+	writeSecret(t, passFile, "newer\n")
+	creds.mu.Lock()
+	creds.checked = time.Now().Add(-CredentialCheckInterval)
+	creds.mu.Unlock()
+	if _, pass, _ := creds.Get(); pass != "newer" {
+		t.Errorf("Get = %q after the check interval, want newer", pass)
+	}
+}
+
+// TestFileCredentialsMissingFile checks that Get fails while a file is
+// missing and recovers once it is back.
+func TestFileCredentialsMissingFile(t *testing.T) {
+	passFile := filepath.Join(t.TempDir(), "password")
+	creds := NewFileCredentials("nsroot", "", "", passFile)
+	if _, _, err := creds.Get(); err == nil {
+		t.Fatal("Get succeeded without the password file")
+	}
+	if err := creds.Reload(); err == nil {
+		t.Error("Reload succeeded without the password file")
+	}
+
+	writeSecret(t, passFile, "secret")
+	if err := creds.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if user, pass, err := creds.Get(); err != nil || user != "nsroot" || pass != "secret" {
+		t.Errorf("Get = %q, %q, %v, want nsroot, secret", user, pass, err)
+	}
+
+	if err := os.Remove(passFile); err != nil {
+		t.Fatal(err)
+	}
+	if err := creds.Reload(); err == nil {
+		t.Error("Reload succeeded after the password file was removed")
+	}
+	if _, _, err := creds.Get(); err == nil {
+		t.Error("Get kept returning the old credentials after the file was removed")
+	}
+}
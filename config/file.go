@@ -0,0 +1,623 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ModuleNames lists all collector modules that can be enabled or disabled.
+var ModuleNames = []string{
+	"topology",
+	"ns_stats",
+	"ns_license",
+	"interfaces",
+	"virtual_servers",
+	"services",
+	"gslb_services",
+	"gslb_vservers",
+	"cs_vservers",
+	"vpn_vservers",
+	"aaa_stats",
+	"service_groups",
+	"protocol_http",
+	"protocol_tcp",
+	"protocol_ip",
+	"ssl_stats",
+	"ssl_certs",
+	"ssl_vservers",
+	"system_cpu",
+	"ns_capacity",
+	"ha_stats",
+}
+
+// IsKnownModule returns true if name is a valid collector module name.
+func IsKnownModule(name string) bool {
+	for _, m := range ModuleNames {
+		if m == name {
+			return true
+		}
+	}
+	return false
+}
+
+// File is the YAML document loaded with -config.file.
+type File struct {
+	Auths     map[string]AuthConfig   `yaml:"auths"`
+	Modules   map[string]ModuleConfig `yaml:"modules"`
+	Targets   []TargetConfig          `yaml:"targets"`
+	Discovery DiscoveryConfig         `yaml:"discovery"`
+}
+
+// AuthConfig is a reusable set of Nitro API credentials.
+// Mode selects how the credentials are presented to ADC targets: "session"
+// (default) logs in and keeps a session, "header" sends them with every
+// request and "mtls" uses only the client certificate from the target's tls.
+// UsernameFile and PasswordFile read the credentials from files instead, which
+// are re-read when they change.
+type AuthConfig struct {
+	Username     string `yaml:"username"`
+	Password     string `yaml:"password"`
+	UsernameFile string `yaml:"username_file"`
+	PasswordFile string `yaml:"password_file"`
+	Mode         string `yaml:"mode"`
+}
+
+// ModuleConfig is a module profile listing which collectors run.
+// An empty collector list runs all collectors.
+type ModuleConfig struct {
+	Collectors []string `yaml:"collectors"`
+
+	// Timeout bounds each collector's scrape; Timeouts overrides it per collector.
+	// Unset, the -module-timeout flag applies.
+	Timeout  *time.Duration           `yaml:"timeout"`
+	Timeouts map[string]time.Duration `yaml:"timeouts"`
+}
+
+// TLSConfig holds TLS settings for connecting to a target.
+// CertFile and KeyFile hold a client certificate for mutual TLS.
+type TLSConfig struct {
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+	CAFile             string `yaml:"ca_file"`
+	CertFile           string `yaml:"cert_file"`
+	KeyFile            string `yaml:"key_file"`
+}
+
+// ReplayConfig serves a target from the recordings of -record-dir instead of
+// calling it.
+type ReplayConfig struct {
+	Dir  string `yaml:"dir"`
+	Type string `yaml:"type"` // type of the recorded target: "adc" (default) or "mps"
+}
+
+// TargetConfig is a named target as written in the configuration file.
+// A target of type "replay" is served from the recordings in Replay.
+type TargetConfig struct {
+	Name   string            `yaml:"name"`
+	URL    string            `yaml:"url"`
+	Type   string            `yaml:"type"`
+	Auth   string            `yaml:"auth"`
+	Module string            `yaml:"module"`
+	TLS    *TLSConfig        `yaml:"tls"`
+	Labels map[string]string `yaml:"labels"`
+
+	// PollInterval enables background polling for this target (see -poll-interval)
+	PollInterval *time.Duration `yaml:"poll_interval"`
+
+	// RateLimit, RateBurst and CallBudget throttle the target's API requests
+	// (see -rate-limit, -rate-burst and -call-budget)
+	RateLimit  *float64 `yaml:"rate_limit"`
+	RateBurst  *int     `yaml:"rate_burst"`
+	CallBudget *int     `yaml:"call_budget"`
+
+	// PageSize fetches large collections in pages (see -page-size)
+	PageSize *int `yaml:"page_size"`
+
+	Replay *ReplayConfig `yaml:"replay"`
+}
+
+// DiscoveryConfig lists the sources that generate targets automatically.
+type DiscoveryConfig struct {
+	ADM []ADMDiscoveryConfig `yaml:"adm"`
+}
+
+// ADMDiscoveryConfig generates ADC targets from the instances managed by a Citrix ADM.
+// Without device_auth, the instances are scraped through the ADM API proxy using
+// the instance profile held by ADM. With type "replay", the ADM and its
+// instances are served from the recordings in Replay.
+type ADMDiscoveryConfig struct {
+	Name            string            `yaml:"name"`
+	URL             string            `yaml:"url"`
+	Type            string            `yaml:"type"`
+	Replay          *ReplayConfig     `yaml:"replay"`
+	Auth            string            `yaml:"auth"`
+	TLS             *TLSConfig        `yaml:"tls"`
+	RefreshInterval time.Duration     `yaml:"refresh_interval"`
+	DeviceTypes     []string          `yaml:"device_types"`
+	DeviceAuth      string            `yaml:"device_auth"`
+	DeviceScheme    string            `yaml:"device_scheme"`
+	DeviceTLS       *TLSConfig        `yaml:"device_tls"`
+	Module          string            `yaml:"module"`
+	Labels          map[string]string `yaml:"labels"`
+}
+
+// DefaultRefreshInterval is how often ADM is asked for its instances when
+// refresh_interval is not set.
+const DefaultRefreshInterval = 5 * time.Minute
+
+// ADMDiscovery is a fully resolved ADM discovery source.
+type ADMDiscovery struct {
+	Name            string
+	ADM             Target // the ADM itself (type mps)
+	Device          Target // template for discovered instances, without URL
+	Proxy           bool   // scrape instances through the ADM API proxy
+	DeviceScheme    string
+	DeviceTypes     []string
+	RefreshInterval time.Duration
+}
+
+// Target is a fully resolved target with everything needed to build an exporter.
+type Target struct {
+	Name     string
+	URL      string
+	Type     string // "adc", "mps" or "replay"
+	AuthMode string // "session" (default), "header" or "mtls"
+	Username string
+	Password string
+	TLS      TLSConfig
+	Module   string
+	Config   *Config
+
+	// UsernameFile and PasswordFile hold the credentials instead of Username
+	// and Password, see Credentials.
+	UsernameFile string
+	PasswordFile string
+
+	// PollInterval polls the target in the background and serves the last
+	// snapshot; 0 scrapes on every request.
+	PollInterval time.Duration
+
+	// RateLimit limits API requests to this many per second on average, with
+	// bursts of up to RateBurst requests; 0 disables the limit.
+	RateLimit float64
+	RateBurst int
+
+	// CallBudget limits the API requests of a scrape; 0 disables the limit.
+	CallBudget int
+
+	// PageSize fetches collections of more entries in pages of this size;
+	// 0 fetches every collection in one response.
+	PageSize int
+
+	// ProxyInstance is the IP of an ADM managed instance. When set, URL and
+	// credentials point at the ADM, which forwards requests to the instance.
+	ProxyInstance string
+
+	// RecordDir records the target's Nitro traffic to files (see -record-dir).
+	RecordDir string
+
+	// Replay holds the recordings served instead of calling a "replay" target.
+	Replay ReplayConfig
+}
+
+// APIType returns the type of the target's Nitro API, "adc" or "mps": that
+// of the recorded target for a replay target.
+func (t Target) APIType() string {
+	if t.Type == "replay" {
+		return t.Replay.Type
+	}
+	return t.Type
+}
+
+// Credentials returns the target's credentials, read from UsernameFile and
+// PasswordFile where set.
+func (t Target) Credentials() *FileCredentials {
+	return NewFileCredentials(t.Username, t.Password, t.UsernameFile, t.PasswordFile)
+}
+
+// LoadFile reads and validates the configuration file at path.
+func LoadFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	return ParseFile(data)
+}
+
+// ParseFile parses and validates a YAML configuration document.
+// Unknown fields are rejected.
+func ParseFile(data []byte) (*File, error) {
+	var f File
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// Validate checks the configuration for unknown references, unknown module
+// names and duplicate targets.
+func (f *File) Validate() error {
+	for name, a := range f.Auths {
+		if err := ValidateAuthMode(a.Mode); err != nil {
+			return fmt.Errorf("auth %q: %w", name, err)
+		}
+		if a.Username != "" && a.UsernameFile != "" {
+			return fmt.Errorf("auth %q: username and username_file are mutually exclusive", name)
+		}
+		if a.Password != "" && a.PasswordFile != "" {
+			return fmt.Errorf("auth %q: password and password_file are mutually exclusive", name)
+		}
+	}
+
+	for name, m := range f.Modules {
+		for _, c := range m.Collectors {
+			if !IsKnownModule(c) {
+				return fmt.Errorf("module %q: unknown collector %q", name, c)
+			}
+		}
+		if m.Timeout != nil && *m.Timeout < 0 {
+			return fmt.Errorf("module %q: timeout must not be negative", name)
+		}
+		for c, timeout := range m.Timeouts {
+			if !IsKnownModule(c) {
+				return fmt.Errorf("module %q: timeouts: unknown collector %q", name, c)
+			}
+			if timeout < 0 {
+				return fmt.Errorf("module %q: timeouts: %s must not be negative", name, c)
+			}
+		}
+	}
+
+	names := make(map[string]bool)
+	urls := make(map[string]bool)
+	for i, t := range f.Targets {
+		if t.URL == "" {
+			return fmt.Errorf("target %d: url is required", i)
+		}
+		name := t.Name
+		if name == "" {
+			name = t.URL
+		}
+		if names[name] {
+			return fmt.Errorf("duplicate target name %q", name)
+		}
+		names[name] = true
+
+		url := strings.TrimRight(t.URL, "/")
+		if urls[url] {
+			return fmt.Errorf("target %q: duplicate url %q", name, t.URL)
+		}
+		urls[url] = true
+
+		if t.Type != "" && t.Type != "adc" && t.Type != "mps" && t.Type != "replay" {
+			return fmt.Errorf("target %q: invalid type %q (must be 'adc', 'mps' or 'replay')", name, t.Type)
+		}
+		if err := validateReplay(t.Type, t.Replay, true); err != nil {
+			return fmt.Errorf("target %q: %w", name, err)
+		}
+		if t.PollInterval != nil && *t.PollInterval < 0 {
+			return fmt.Errorf("target %q: poll_interval must not be negative", name)
+		}
+		if t.RateLimit != nil && *t.RateLimit < 0 {
+			return fmt.Errorf("target %q: rate_limit must not be negative", name)
+		}
+		if t.RateBurst != nil && *t.RateBurst < 0 {
+			return fmt.Errorf("target %q: rate_burst must not be negative", name)
+		}
+		if t.CallBudget != nil && *t.CallBudget < 0 {
+			return fmt.Errorf("target %q: call_budget must not be negative", name)
+		}
+		if t.PageSize != nil && *t.PageSize < 0 {
+			return fmt.Errorf("target %q: page_size must not be negative", name)
+		}
+		if t.Auth != "" {
+			if _, ok := f.Auths[t.Auth]; !ok {
+				return fmt.Errorf("target %q: unknown auth %q", name, t.Auth)
+			}
+		}
+		if t.TLS != nil {
+			if err := t.TLS.Validate(); err != nil {
+				return fmt.Errorf("target %q: tls: %w", name, err)
+			}
+		}
+		if t.Module != "" && t.Module != DefaultModule {
+			if _, ok := f.Modules[t.Module]; !ok {
+				return fmt.Errorf("target %q: unknown module %q", name, t.Module)
+			}
+		}
+	}
+
+	admNames := make(map[string]bool)
+	for i, d := range f.Discovery.ADM {
+		if d.Name == "" {
+			return fmt.Errorf("adm discovery %d: name is required", i)
+		}
+		if admNames[d.Name] {
+			return fmt.Errorf("duplicate adm discovery name %q", d.Name)
+		}
+		admNames[d.Name] = true
+		if d.URL == "" {
+			return fmt.Errorf("adm discovery %q: url is required", d.Name)
+		}
+		if d.Type != "" && d.Type != "mps" && d.Type != "replay" {
+			return fmt.Errorf("adm discovery %q: invalid type %q (must be 'mps' or 'replay')", d.Name, d.Type)
+		}
+		if err := validateReplay(d.Type, d.Replay, false); err != nil {
+			return fmt.Errorf("adm discovery %q: %w", d.Name, err)
+		}
+		for _, ref := range []string{d.Auth, d.DeviceAuth} {
+			if ref == "" {
+				continue
+			}
+			if _, ok := f.Auths[ref]; !ok {
+				return fmt.Errorf("adm discovery %q: unknown auth %q", d.Name, ref)
+			}
+		}
+		if d.Module != "" && d.Module != DefaultModule {
+			if _, ok := f.Modules[d.Module]; !ok {
+				return fmt.Errorf("adm discovery %q: unknown module %q", d.Name, d.Module)
+			}
+		}
+		for _, tls := range []*TLSConfig{d.TLS, d.DeviceTLS} {
+			if tls == nil {
+				continue
+			}
+			if err := tls.Validate(); err != nil {
+				return fmt.Errorf("adm discovery %q: tls: %w", d.Name, err)
+			}
+		}
+		if d.DeviceScheme != "" && d.DeviceScheme != "http" && d.DeviceScheme != "https" {
+			return fmt.Errorf("adm discovery %q: invalid device_scheme %q (must be 'http' or 'https')", d.Name, d.DeviceScheme)
+		}
+		if d.RefreshInterval < 0 {
+			return fmt.Errorf("adm discovery %q: refresh_interval must not be negative", d.Name)
+		}
+	}
+	return nil
+}
+
+// validateReplay checks that replay is set exactly for the type "replay", with
+// a directory, and that it names a recorded target type only if typed allows.
+func validateReplay(typ string, replay *ReplayConfig, typed bool) error {
+	switch {
+	case typ != "replay" && replay != nil:
+		return errors.New("replay requires type 'replay'")
+	case typ != "replay":
+		return nil
+	case replay == nil || replay.Dir == "":
+		return errors.New("type 'replay' requires replay.dir")
+	case !typed && replay.Type != "":
+		return errors.New("replay.type is not supported")
+	case replay.Type != "" && replay.Type != "adc" && replay.Type != "mps":
+		return fmt.Errorf("invalid replay.type %q (must be 'adc' or 'mps')", replay.Type)
+	}
+	return nil
+}
+
+// ModuleConfigs returns the exporter config for every module profile, using
+// base for labels, for settings a profile leaves unset and for the built-in
+// default module. A default module in the file overrides the built-in one.
+// Collectors disabled in base (-disabled-modules) stay disabled in every
+// profile, whether or not the profile lists them.
+func (f *File) ModuleConfigs(base *Config) map[string]*Config {
+	modules := map[string]*Config{DefaultModule: base}
+	for name, m := range f.Modules {
+		cfg := *base
+		cfg.DisabledModules = mergeModules(base.DisabledModules, m.DisabledModules())
+		if m.Timeout != nil {
+			cfg.Timeout = *m.Timeout
+		}
+		cfg.Timeouts = m.Timeouts
+		modules[name] = &cfg
+	}
+	return modules
+}
+
+// DisabledModules returns all known modules not listed in the profile's collectors.
+func (m ModuleConfig) DisabledModules() []string {
+	if len(m.Collectors) == 0 {
+		return nil
+	}
+	enabled := make(map[string]bool, len(m.Collectors))
+	for _, c := range m.Collectors {
+		enabled[c] = true
+	}
+	var disabled []string
+	for _, name := range ModuleNames {
+		if !enabled[name] {
+			disabled = append(disabled, name)
+		}
+	}
+	return disabled
+}
+
+// mergeModules returns the modules of a followed by those of b not in a.
+func mergeModules(a, b []string) []string {
+	merged := append([]string(nil), a...)
+	seen := make(map[string]bool, len(a))
+	for _, m := range a {
+		seen[m] = true
+	}
+	for _, m := range b {
+		if !seen[m] {
+			seen[m] = true
+			merged = append(merged, m)
+		}
+	}
+	return merged
+}
+
+// ResolveTargets returns the file's targets with auth, module and labels resolved.
+// Settings that a target leaves unset are taken from defaults.
+func (f *File) ResolveTargets(defaults Target) []Target {
+	modules := f.ModuleConfigs(defaults.Config)
+
+	targets := make([]Target, 0, len(f.Targets))
+	for _, tc := range f.Targets {
+		t := defaults
+		t.Name = tc.Name
+		if t.Name == "" {
+			t.Name = tc.URL
+		}
+		t.URL = strings.TrimRight(tc.URL, "/")
+		if tc.Type != "" {
+			t.Type = tc.Type
+		}
+		if tc.Replay != nil {
+			t.Replay = ReplayConfig{Dir: tc.Replay.Dir, Type: tc.Replay.Type}
+			if t.Replay.Type == "" {
+				t.Replay.Type = "adc"
+			}
+		}
+		if tc.Auth != "" {
+			auth := f.Auths[tc.Auth]
+			t.AuthMode = auth.Mode
+			t.Username = auth.Username
+			t.Password = auth.Password
+			t.UsernameFile = auth.UsernameFile
+			t.PasswordFile = auth.PasswordFile
+		}
+		if tc.TLS != nil {
+			t.TLS = *tc.TLS
+		}
+		if tc.PollInterval != nil {
+			t.PollInterval = *tc.PollInterval
+		}
+		if tc.RateLimit != nil {
+			t.RateLimit = *tc.RateLimit
+		}
+		if tc.RateBurst != nil {
+			t.RateBurst = *tc.RateBurst
+		}
+		if tc.CallBudget != nil {
+			t.CallBudget = *tc.CallBudget
+		}
+		if tc.PageSize != nil {
+			t.PageSize = *tc.PageSize
+		}
+		t.Module = DefaultModule
+		if tc.Module != "" {
+			t.Module = tc.Module
+		}
+
+		t.Config = targetConfig(modules[t.Module], tc.Labels)
+		targets = append(targets, t)
+	}
+	return targets
+}
+
+// ResolveADMDiscoveries returns the file's ADM discovery sources with auth,
+// module and labels resolved. Settings left unset are taken from defaults.
+func (f *File) ResolveADMDiscoveries(defaults Target) []ADMDiscovery {
+	modules := f.ModuleConfigs(defaults.Config)
+
+	discoveries := make([]ADMDiscovery, 0, len(f.Discovery.ADM))
+	for _, dc := range f.Discovery.ADM {
+		d := ADMDiscovery{
+			Name:            dc.Name,
+			Proxy:           dc.DeviceAuth == "",
+			DeviceScheme:    dc.DeviceScheme,
+			DeviceTypes:     dc.DeviceTypes,
+			RefreshInterval: dc.RefreshInterval,
+		}
+		if d.DeviceScheme == "" {
+			d.DeviceScheme = "https"
+		}
+		if d.RefreshInterval == 0 {
+			d.RefreshInterval = DefaultRefreshInterval
+		}
+
+		d.ADM = defaults
+		d.ADM.Name = dc.Name
+		d.ADM.URL = strings.TrimRight(dc.URL, "/")
+		d.ADM.Type = "mps"
+		if dc.Type == "replay" {
+			d.ADM.Type = "replay"
+			d.ADM.Replay = ReplayConfig{Dir: dc.Replay.Dir, Type: "mps"}
+		}
+		if dc.Auth != "" {
+			auth := f.Auths[dc.Auth]
+			d.ADM.AuthMode = auth.Mode
+			d.ADM.Username = auth.Username
+			d.ADM.Password = auth.Password
+			d.ADM.UsernameFile = auth.UsernameFile
+			d.ADM.PasswordFile = auth.PasswordFile
+		}
+		if dc.TLS != nil {
+			d.ADM.TLS = *dc.TLS
+		}
+
+		d.Device = defaults
+		d.Device.Type = "adc"
+		if dc.Type == "replay" {
+			d.Device.Type = "replay"
+			d.Device.Replay = ReplayConfig{Dir: dc.Replay.Dir, Type: "adc"}
+		}
+		if dc.DeviceAuth != "" {
+			auth := f.Auths[dc.DeviceAuth]
+			d.Device.AuthMode = auth.Mode
+			d.Device.Username = auth.Username
+			d.Device.Password = auth.Password
+			d.Device.UsernameFile = auth.UsernameFile
+			d.Device.PasswordFile = auth.PasswordFile
+		}
+		if dc.DeviceTLS != nil {
+			d.Device.TLS = *dc.DeviceTLS
+		}
+		d.Device.Module = DefaultModule
+		if dc.Module != "" {
+			d.Device.Module = dc.Module
+		}
+		d.Device.Config = targetConfig(modules[d.Device.Module], dc.Labels)
+
+		discoveries = append(discoveries, d)
+	}
+	return discoveries
+}
+
+// Validate checks that a client certificate comes with its key and that a CA
+// file is not combined with skipping verification, which would ignore it.
+func (t TLSConfig) Validate() error {
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return errors.New("cert_file and key_file must be set together")
+	}
+	if t.InsecureSkipVerify && t.CAFile != "" {
+		return errors.New("insecure_skip_verify and ca_file are mutually exclusive")
+	}
+	return nil
+}
+
+// ValidateAuthMode checks that mode is empty or a known authentication mode.
+func ValidateAuthMode(mode string) error {
+	switch mode {
+	case "", "session", "header", "mtls":
+		return nil
+	}
+	return fmt.Errorf("invalid mode %q (must be 'session', 'header' or 'mtls')", mode)
+}
+
+// targetConfig returns a copy of the module config with extra labels
+// extending/overriding the module labels.
+func targetConfig(moduleCfg *Config, extra map[string]string) *Config {
+	labels := make(map[string]string, len(moduleCfg.Labels)+len(extra))
+	for k, v := range moduleCfg.Labels {
+		labels[k] = v
+	}
+	for k, v := range extra {
+		labels[k] = v
+	}
+	cfg := *moduleCfg
+	cfg.Labels = labels
+	return &cfg
+}
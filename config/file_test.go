@@ -0,0 +1,169 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestParseFile checks that valid documents are accepted and that invalid
+// ones are rejected with an error naming the offending setting.
+func TestParseFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string // empty for a valid document
+	}{
+		{name: "empty", yaml: ``},
+		{name: "valid", yaml: `
+auths:
+  ro: {username: nsroot, password: secret, mode: header}
+modules:
+  lb: {collectors: [virtual_servers, services], timeout: 5s, timeouts: {services: 10s}}
+targets:
+  - {name: adc1, url: "https://adc1", auth: ro, module: lb}
+  - {url: "https://adc2", type: mps, tls: {cert_file: c.pem, key_file: k.pem}}
+  - {url: "https://adc3", type: replay, replay: {dir: ./recording, type: mps}}
+discovery:
+  adm:
+    - {name: adm1, url: "https://adm", auth: ro, device_auth: ro, device_scheme: http, module: lb}
+    - {name: adm2, url: "https://adm", type: replay, replay: {dir: ./recording}}
+`},
+		{name: "unknown field", yaml: "targets:\n  - {url: x, username: nsroot}", wantErr: "field username not found"},
+		{name: "unknown collector", yaml: "modules:\n  m: {collectors: [lbvservers]}", wantErr: `unknown collector "lbvservers"`},
+		{name: "unknown timeout collector", yaml: "modules:\n  m: {timeouts: {lbvservers: 1s}}", wantErr: `timeouts: unknown collector "lbvservers"`},
+		{name: "negative timeout", yaml: "modules:\n  m: {timeout: -1s}", wantErr: "timeout must not be negative"},
+		{name: "invalid auth mode", yaml: "auths:\n  a: {mode: token}", wantErr: `invalid mode "token"`},
+		{name: "password and password_file", yaml: "auths:\n  a: {password: x, password_file: f}", wantErr: "mutually exclusive"},
+		{name: "missing url", yaml: "targets:\n  - {name: adc1}", wantErr: "url is required"},
+		{name: "duplicate name", yaml: "targets:\n  - {name: adc1, url: a}\n  - {name: adc1, url: b}", wantErr: `duplicate target name "adc1"`},
+		{name: "duplicate url", yaml: "targets:\n  - {name: a, url: \"https://adc\"}\n  - {name: b, url: \"https://adc/\"}", wantErr: "duplicate url"},
+		{name: "invalid type", yaml: "targets:\n  - {url: a, type: sdx}", wantErr: `invalid type "sdx"`},
+		{name: "unknown auth", yaml: "targets:\n  - {url: a, auth: ro}", wantErr: `unknown auth "ro"`},
+		{name: "unknown module", yaml: "targets:\n  - {url: a, module: lb}", wantErr: `unknown module "lb"`},
+		{name: "negative poll interval", yaml: "targets:\n  - {url: a, poll_interval: -1s}", wantErr: "poll_interval must not be negative"},
+		{name: "cert without key", yaml: "targets:\n  - {url: a, tls: {cert_file: c.pem}}", wantErr: "cert_file and key_file must be set together"},
+		{name: "insecure with ca", yaml: "targets:\n  - {url: a, tls: {insecure_skip_verify: true, ca_file: ca.pem}}", wantErr: "insecure_skip_verify and ca_file are mutually exclusive"},
+		{name: "replay without dir", yaml: "targets:\n  - {url: a, type: replay}", wantErr: "type 'replay' requires replay.dir"},
+		{name: "replay without type", yaml: "targets:\n  - {url: a, replay: {dir: r}}", wantErr: "replay requires type 'replay'"},
+		{name: "invalid replay type", yaml: "targets:\n  - {url: a, type: replay, replay: {dir: r, type: replay}}", wantErr: `invalid replay.type "replay"`},
+		{name: "adm without name", yaml: "discovery:\n  adm:\n    - {url: a}", wantErr: "name is required"},
+		{name: "duplicate adm", yaml: "discovery:\n  adm:\n    - {name: a, url: a}\n    - {name: a, url: b}", wantErr: `duplicate adm discovery name "a"`},
+		{name: "adm unknown device auth", yaml: "discovery:\n  adm:\n    - {name: a, url: a, device_auth: ro}", wantErr: `unknown auth "ro"`},
+		{name: "adm invalid type", yaml: "discovery:\n  adm:\n    - {name: a, url: a, type: adc}", wantErr: `invalid type "adc"`},
+		{name: "adm replay type", yaml: "discovery:\n  adm:\n    - {name: a, url: a, type: replay, replay: {dir: r, type: adc}}", wantErr: "replay.type is not supported"},
+		{name: "adm invalid scheme", yaml: "discovery:\n  adm:\n    - {name: a, url: a, device_scheme: ftp}", wantErr: `invalid device_scheme "ftp"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFile([]byte(tt.yaml))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ParseFile: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ParseFile error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestModuleConfigs checks that module profiles keep the collectors disabled
+// in the base config and take the settings they leave unset from it.
+func TestModuleConfigs(t *testing.T) {
+	f, err := ParseFile([]byte(`
+modules:
+  all: {}
+  lb: {collectors: [virtual_servers, topology], timeout: 5s}
+`))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	base := &Config{Labels: map[string]string{"env": "prod"}, DisabledModules: []string{"topology", "ha_stats"}, Timeout: time.Second}
+	modules := f.ModuleConfigs(base)
+
+	if modules[DefaultModule] != base {
+		t.Error("default module is not the base config")
+	}
+	if got := modules["all"].DisabledModules; !reflect.DeepEqual(got, base.DisabledModules) {
+		t.Errorf("profile without collectors disables %v, want %v", got, base.DisabledModules)
+	}
+	if got := modules["all"].Timeout; got != time.Second {
+		t.Errorf("profile without timeout has timeout %s, want the base timeout", got)
+	}
+
+	lb := modules["lb"]
+	for _, m := range ModuleNames {
+		want := m != "virtual_servers"
+		if got := lb.IsModuleDisabled(m); got != want {
+			t.Errorf("lb profile: module %s disabled = %v, want %v", m, got, want)
+		}
+	}
+	if lb.Timeout != 5*time.Second || lb.Labels["env"] != "prod" {
+		t.Errorf("lb profile has timeout %s and labels %v, want 5s and the base labels", lb.Timeout, lb.Labels)
+	}
+	if len(base.DisabledModules) != 2 {
+		t.Errorf("base disabled modules changed to %v", base.DisabledModules)
+	}
+}
+
+// TestResolveTargets checks that targets take auth, module and labels from
+// the file and everything else from the defaults.
+func TestResolveTargets(t *testing.T) {
+	f, err := ParseFile([]byte(`
+auths:
+  ro: {username: reader, password_file: /run/secrets/pw, mode: header}
+modules:
+  lb: {collectors: [virtual_servers]}
+targets:
+  - {name: adc1, url: "https://adc1/", auth: ro, module: lb, labels: {site: a}, poll_interval: 30s}
+  - {url: "https://adc2"}
+  - {name: adc3, url: "https://adc3", type: replay, replay: {dir: ./recording}}
+`))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	defaults := Target{
+		Type:       "adc",
+		Username:   "nsroot",
+		Password:   "secret",
+		Config:     &Config{Labels: map[string]string{"env": "prod"}},
+		RateLimit:  5,
+		CallBudget: 100,
+	}
+	targets := f.ResolveTargets(defaults)
+	if len(targets) != 3 {
+		t.Fatalf("got %d targets, want 3", len(targets))
+	}
+
+	adc1 := targets[0]
+	if adc1.URL != "https://adc1" || adc1.Module != "lb" || adc1.PollInterval != 30*time.Second {
+		t.Errorf("adc1 = %+v, want url https://adc1, module lb and poll interval 30s", adc1)
+	}
+	if adc1.AuthMode != "header" || adc1.Username != "reader" || adc1.Password != "" || adc1.PasswordFile != "/run/secrets/pw" {
+		t.Errorf("adc1 auth = %q %q %q %q, want the ro auth", adc1.AuthMode, adc1.Username, adc1.Password, adc1.PasswordFile)
+	}
+	if !reflect.DeepEqual(adc1.Config.Labels, map[string]string{"env": "prod", "site": "a"}) {
+		t.Errorf("adc1 labels = %v, want env and site", adc1.Config.Labels)
+	}
+	if !adc1.Config.IsModuleDisabled("services") || adc1.Config.IsModuleDisabled("virtual_servers") {
+		t.Errorf("adc1 disabled modules = %v, want all but virtual_servers", adc1.Config.DisabledModules)
+	}
+
+	adc2 := targets[1]
+	if adc2.Name != "https://adc2" || adc2.Module != DefaultModule || adc2.Username != "nsroot" || adc2.RateLimit != 5 || adc2.CallBudget != 100 {
+		t.Errorf("adc2 = %+v, want the defaults", adc2)
+	}
+	if adc2.Type != "adc" || adc2.APIType() != "adc" || adc2.Replay != (ReplayConfig{}) {
+		t.Errorf("adc2 type = %q, replay %+v, want a live adc", adc2.Type, adc2.Replay)
+	}
+
+	adc3 := targets[2]
+	if adc3.Type != "replay" || adc3.APIType() != "adc" || adc3.Replay.Dir != "./recording" {
+		t.Errorf("adc3 type = %q, replay %+v, want an adc replayed from ./recording", adc3.Type, adc3.Replay)
+	}
+}
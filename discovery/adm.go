@@ -0,0 +1,182 @@
+// Package discovery generates probe targets from external inventories.
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/elohmeier/netscaler-exporter/config"
+	"github.com/elohmeier/netscaler-exporter/netscaler"
+)
+
+var invalidLabelChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// ADM discovers ADC targets from the instances managed by a Citrix ADM.
+type ADM struct {
+	cfg    config.ADMDiscovery
+	client *netscaler.MPSClient
+	logger *slog.Logger
+}
+
+// NewADM creates a discoverer for the given ADM discovery source.
+func NewADM(cfg config.ADMDiscovery, logger *slog.Logger) (*ADM, error) {
+	client, err := netscaler.NewMPSClient(cfg.ADM.URL, cfg.ADM.Credentials(), netscaler.TLSConfig(cfg.ADM.TLS), logger)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.ADM.Type == "replay" {
+		client.SetReplay(cfg.ADM.Replay.Dir)
+	} else {
+		client.SetRecording(cfg.ADM.RecordDir)
+	}
+	return &ADM{
+		cfg:    cfg,
+		client: client,
+		logger: logger.With("adm", cfg.Name),
+	}, nil
+}
+
+// Name returns the name of the discovery source.
+func (a *ADM) Name() string {
+	return a.cfg.Name
+}
+
+// Run discovers targets immediately and then every refresh interval until ctx
+// is cancelled. Each successful result is passed to update; on failure the
+// previous targets are kept.
+func (a *ADM) Run(ctx context.Context, update func([]config.Target)) {
+	ticker := time.NewTicker(a.cfg.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		// A refresh must not hang into the next one
+		dctx, cancel := context.WithTimeout(ctx, a.cfg.RefreshInterval)
+		targets, err := a.Discover(dctx)
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			a.logger.Error("ADM discovery failed", "url", a.cfg.ADM.URL, "err", err)
+		} else {
+			a.logger.Debug("ADM discovery finished", "targets", len(targets))
+			update(targets)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Discover asks ADM for its managed instances and returns one ADC target per instance.
+func (a *ADM) Discover(ctx context.Context) ([]config.Target, error) {
+	devices, err := netscaler.GetMPSManagedDevices(ctx, a.client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list managed devices: %w", err)
+	}
+
+	// Site names are optional metadata, so a failure here is not fatal
+	sites := make(map[string]string)
+	datacenters, err := netscaler.GetMPSDatacenters(ctx, a.client)
+	if err != nil {
+		a.logger.Warn("failed to list ADM sites", "err", err)
+	}
+	for _, dc := range datacenters {
+		sites[dc.ID] = dc.Name
+	}
+
+	targets := make([]config.Target, 0, len(devices))
+	names := make(map[string]bool, len(devices))
+	for _, d := range devices {
+		if d.IPAddress == "" || !a.wantType(d.Type) {
+			continue
+		}
+
+		t := a.cfg.Device
+		t.Name = d.Hostname
+		if t.Name == "" || names[t.Name] {
+			t.Name = d.IPAddress
+		}
+		if names[t.Name] {
+			continue
+		}
+		names[t.Name] = true
+
+		if a.cfg.Proxy {
+			// Scrape through ADM with the instance profile held by ADM
+			t.URL = a.cfg.ADM.URL
+			t.Username = a.cfg.ADM.Username
+			t.Password = a.cfg.ADM.Password
+			t.UsernameFile = a.cfg.ADM.UsernameFile
+			t.PasswordFile = a.cfg.ADM.PasswordFile
+			t.TLS = a.cfg.ADM.TLS
+			t.ProxyInstance = d.IPAddress
+		} else {
+			t.URL = a.cfg.DeviceScheme + "://" + d.IPAddress
+		}
+
+		cfg := *a.cfg.Device.Config
+		cfg.Labels = a.deviceLabels(d, sites[d.DatacenterID])
+		t.Config = &cfg
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+// Close logs out of ADM.
+func (a *ADM) Close() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := a.client.Logout(ctx); err != nil {
+		a.logger.Warn("failed to log out", "url", a.cfg.ADM.URL, "err", err)
+	}
+	a.client.CloseIdleConnections()
+}
+
+// wantType returns true if instances of the given ADM device type are discovered.
+func (a *ADM) wantType(deviceType string) bool {
+	if len(a.cfg.DeviceTypes) == 0 {
+		return true
+	}
+	for _, t := range a.cfg.DeviceTypes {
+		if strings.EqualFold(t, deviceType) {
+			return true
+		}
+	}
+	return false
+}
+
+// deviceLabels returns the configured labels extended with ADM instance metadata.
+// Only metadata that is stable across HA failovers is used: a changed label
+// changes the target, which would log it out and start new series.
+func (a *ADM) deviceLabels(d netscaler.MPSManagedDevice, site string) map[string]string {
+	labels := make(map[string]string, len(a.cfg.Device.Config.Labels)+len(d.Tags)+5)
+	for k, v := range a.cfg.Device.Config.Labels {
+		labels[k] = v
+	}
+
+	labels["adm"] = a.cfg.Name
+	labels["adm_instance"] = d.IPAddress
+	labels["adm_hostname"] = d.Hostname
+	labels["adm_site"] = site
+	labels["adm_ha_pair"] = ""
+	if d.HAIPAddress != "" {
+		// Both nodes of a pair get the same value regardless of which one is primary
+		pair := []string{d.IPAddress, d.HAIPAddress}
+		sort.Strings(pair)
+		labels["adm_ha_pair"] = strings.Join(pair, ",")
+	}
+
+	for k, v := range d.Tags {
+		labels["adm_tag_"+invalidLabelChars.ReplaceAllString(k, "_")] = v
+	}
+	return labels
+}
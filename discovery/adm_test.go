@@ -0,0 +1,215 @@
+package discovery
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elohmeier/netscaler-exporter/config"
+	"github.com/elohmeier/netscaler-exporter/netscaler/nitrotest"
+)
+
+// admFixtures describe an HA pair, a standalone SDX instance and an instance
+// without an IP, in two sites.
+var admFixtures = nitrotest.Fixtures{
+	"config/managed_device": `{"managed_device":[
+		{"ip_address":"10.0.0.11","hostname":"ns-a","type":"nsvpx","ha_ip_address":"10.0.0.12","ha_master_state":"Primary","datacenter_id":"dc1","tags":[{"key":"team-name","value":"web"}]},
+		{"ip_address":"10.0.0.12","hostname":"ns-b","type":"nsvpx","ha_ip_address":"10.0.0.11","ha_master_state":"Secondary","datacenter_id":"dc1"},
+		{"ip_address":"10.0.1.10","hostname":"sdx1","type":"nssdx","datacenter_id":"dc2","tags":"env:prod"},
+		{"ip_address":"","hostname":"pending","type":"nsvpx"}]}`,
+	"config/mps_datacenter": `{"mps_datacenter":[{"id":"dc1","name":"Frankfurt"},{"id":"dc2","name":"Berlin"}]}`,
+}
+
+// newTestADM returns a discoverer of the ADM discovery source in the YAML
+// document, with the ADM served by srv.
+func newTestADM(t *testing.T, srv *nitrotest.Server, yaml string) *ADM {
+	t.Helper()
+	f, err := config.ParseFile([]byte(strings.ReplaceAll(yaml, "ADM_URL", srv.URL)))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	defaults := config.Target{Type: "adc", Module: config.DefaultModule, Config: &config.Config{Labels: map[string]string{"env": "test"}}}
+	discoveries := f.ResolveADMDiscoveries(defaults)
+	adm, err := NewADM(discoveries[0], slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewADM: %v", err)
+	}
+	t.Cleanup(adm.Close)
+	return adm
+}
+
+// TestADMDiscoverProxy checks the targets of instances scraped through the
+// ADM API proxy and their labels.
+func TestADMDiscoverProxy(t *testing.T) {
+	srv := nitrotest.NewServer(admFixtures)
+	defer srv.Close()
+	srv.SetCredentials("admin", "secret")
+	adm := newTestADM(t, srv, `
+auths:
+  adm: {username: admin, password: secret}
+discovery:
+  adm:
+    - {name: adm1, url: ADM_URL, auth: adm, labels: {site: x}}
+`)
+
+	targets, err := adm.Discover(context.Background())
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	if len(targets) != 3 {
+		t.Fatalf("got %d targets, want 3: %+v", len(targets), targets)
+	}
+
+	nsA := targets[0]
+	if nsA.Name != "ns-a" || nsA.URL != srv.URL || nsA.ProxyInstance != "10.0.0.11" || nsA.Username != "admin" || nsA.Type != "adc" {
+		t.Errorf("ns-a = %+v, want scraped through ADM with its credentials", nsA)
+	}
+	want := map[string]string{
+		"env":               "test",
+		"site":              "x",
+		"adm":               "adm1",
+		"adm_instance":      "10.0.0.11",
+		"adm_hostname":      "ns-a",
+		"adm_site":          "Frankfurt",
+		"adm_ha_pair":       "10.0.0.11,10.0.0.12",
+		"adm_tag_team_name": "web",
+	}
+	if !reflect.DeepEqual(nsA.Config.Labels, want) {
+		t.Errorf("ns-a labels = %v, want %v", nsA.Config.Labels, want)
+	}
+	if pair := targets[1].Config.Labels["adm_ha_pair"]; pair != want["adm_ha_pair"] {
+		t.Errorf("ns-b adm_ha_pair = %q, want the same as ns-a", pair)
+	}
+	if tag := targets[2].Config.Labels["adm_tag_env"]; tag != "prod" {
+		t.Errorf("sdx1 adm_tag_env = %q, want prod", tag)
+	}
+}
+
+// TestADMDiscoverDeviceAuth checks that instances are scraped directly with
+// device_auth, filtered by device type.
+func TestADMDiscoverDeviceAuth(t *testing.T) {
+	srv := nitrotest.NewServer(admFixtures)
+	defer srv.Close()
+	adm := newTestADM(t, srv, `
+auths:
+  ro: {username: reader, password: secret, mode: header}
+discovery:
+  adm:
+    - {name: adm1, url: ADM_URL, device_auth: ro, device_scheme: http, device_types: [NSVPX]}
+`)
+
+	targets, err := adm.Discover(context.Background())
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	if len(targets) != 2 {
+		t.Fatalf("got %d targets, want the 2 nsvpx instances: %+v", len(targets), targets)
+	}
+	for _, tgt := range targets {
+		if tgt.URL != "http://"+tgt.Config.Labels["adm_instance"] || tgt.ProxyInstance != "" || tgt.Username != "reader" || tgt.AuthMode != "header" {
+			t.Errorf("target %s = %+v, want scraped directly with the device auth", tgt.Name, tgt)
+		}
+	}
+}
+
+// TestADMDiscoverFailover checks that the discovered targets do not change
+// when the HA nodes swap roles, so their exporters are kept by a reload.
+func TestADMDiscoverFailover(t *testing.T) {
+	srv := nitrotest.NewServer(admFixtures)
+	defer srv.Close()
+	adm := newTestADM(t, srv, `
+discovery:
+  adm:
+    - {name: adm1, url: ADM_URL}
+`)
+
+	before, err := adm.Discover(context.Background())
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	failedOver := strings.NewReplacer(`"Primary"`, `"Secondary"`, `"Secondary"`, `"Primary"`).Replace(admFixtures["config/managed_device"])
+	srv.SetResource("config/managed_device", failedOver)
+	after, err := adm.Discover(context.Background())
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	if !reflect.DeepEqual(before, after) {
+		t.Errorf("targets changed on failover:\nbefore %+v\nafter  %+v", before, after)
+	}
+}
+
+// TestADMRun checks that Run delivers the discovered targets and keeps
+// refreshing, and that a failed refresh delivers nothing.
+func TestADMRun(t *testing.T) {
+	srv := nitrotest.NewServer(admFixtures)
+	defer srv.Close()
+	adm := newTestADM(t, srv, `
+discovery:
+  adm:
+    - {name: adm1, url: ADM_URL, refresh_interval: 20ms}
+`)
+	srv.AddFault(nitrotest.Fault{Resource: "config/managed_device", Times: 1, StatusCode: 503, ErrorCode: 1, Message: "unavailable"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	updates := make(chan []config.Target, 10)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		adm.Run(ctx, func(targets []config.Target) { updates <- targets })
+	}()
+
+	select {
+	case targets := <-updates:
+		if len(targets) != 3 {
+			t.Errorf("got %d targets, want 3", len(targets))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no targets discovered")
+	}
+	cancel()
+	<-done
+	if n := srv.Count("config/managed_device"); n < 2 {
+		t.Errorf("managed_device requested %d times, want a refresh after the failure", n)
+	}
+}
+
+// TestADMReplay checks that an ADM discovery of type replay discovers the
+// recorded instances without calling the ADM, as targets replayed as well.
+func TestADMReplay(t *testing.T) {
+	dir := t.TempDir()
+	srv := nitrotest.NewServer(admFixtures)
+	discover := func(yaml string, recordDir string) []config.Target {
+		t.Helper()
+		f, err := config.ParseFile([]byte(strings.NewReplacer("ADM_URL", srv.URL, "DIR", dir).Replace(yaml)))
+		if err != nil {
+			t.Fatalf("ParseFile: %v", err)
+		}
+		defaults := config.Target{Type: "adc", Module: config.DefaultModule, Config: &config.Config{}, RecordDir: recordDir}
+		adm, err := NewADM(f.ResolveADMDiscoveries(defaults)[0], slog.New(slog.NewTextHandler(io.Discard, nil)))
+		if err != nil {
+			t.Fatalf("NewADM: %v", err)
+		}
+		defer adm.Close()
+		targets, err := adm.Discover(context.Background())
+		if err != nil {
+			t.Fatalf("Discover: %v", err)
+		}
+		return targets
+	}
+
+	recorded := discover("discovery:\n  adm:\n    - {name: adm1, url: ADM_URL}\n", dir)
+	srv.Close()
+	replayed := discover("discovery:\n  adm:\n    - {name: adm1, url: ADM_URL, type: replay, replay: {dir: DIR}}\n", "")
+	if len(replayed) != len(recorded) {
+		t.Fatalf("replayed %d targets, want the %d recorded", len(replayed), len(recorded))
+	}
+	for i, tgt := range replayed {
+		if tgt.Name != recorded[i].Name || tgt.Type != "replay" || tgt.APIType() != "adc" || tgt.Replay.Dir != dir {
+			t.Errorf("target %s = %+v, want %s replayed from the recording", tgt.Name, tgt, recorded[i].Name)
+		}
+	}
+}
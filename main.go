@@ -10,10 +10,10 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/elohmeier/netscaler-exporter/collector"
 	"github.com/elohmeier/netscaler-exporter/config"
+	"github.com/elohmeier/netscaler-exporter/netscaler"
 )
 
 var (
@@ -23,23 +23,66 @@ var (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "simulate" {
+		runSimulate(os.Args[2:])
+		return
+	}
+
 	var (
+		configFile      string
 		url             string
 		targetType      string
 		labelsStr       string
 		disabledModules string
 		bindPort        int
+		webExternalURL  string
 		parallelism     int
+		probeCacheTTL   time.Duration
+		allowedHosts    string
+		pollInterval    time.Duration
+		timeoutOffset   time.Duration
+		moduleTimeout   time.Duration
+		capRefresh      time.Duration
+		breakerFailures int
+		breakerBackoff  time.Duration
+		breakerMax      time.Duration
+		rateLimit       float64
+		rateBurst       int
+		callBudget      int
+		pageSize        int
+		retries         int
+		retryBackoff    time.Duration
+		recordDir       string
+		legacyNames     bool
 		showVersion     bool
 		debug           bool
 	)
 
+	flag.StringVar(&configFile, "config.file", "", "Path to YAML configuration file with targets, auths and modules")
 	flag.StringVar(&url, "url", "", "NetScaler URL (e.g., https://netscaler.example.com)")
 	flag.StringVar(&targetType, "type", "", "Target type: adc or mps (default: adc)")
 	flag.StringVar(&labelsStr, "labels", "", "Custom labels in key=value format, comma-separated (e.g., env=prod,dc=us-east)")
 	flag.StringVar(&disabledModules, "disabled-modules", "", "Comma-separated list of modules to disable")
 	flag.IntVar(&bindPort, "bind-port", 9280, "Port to bind the exporter endpoint to")
+	flag.StringVar(&webExternalURL, "web.external-url", "", "URL Prometheus reaches the exporter at, e.g. behind a reverse proxy; used by /sd (default: http://<host name>:<bind-port>)")
 	flag.IntVar(&parallelism, "parallelism", 5, "Maximum concurrent API requests")
+	flag.Float64Var(&rateLimit, "rate-limit", 0, "Maximum API requests per second to each target (0 disables rate limiting)")
+	flag.IntVar(&rateBurst, "rate-burst", 10, "Maximum burst of API requests to each target above -rate-limit")
+	flag.IntVar(&callBudget, "call-budget", 0, "Maximum API requests per scrape of a target; low-priority modules are deferred once it is spent (0 disables the budget)")
+	flag.IntVar(&pageSize, "page-size", 0, "Fetch collections of more entries (e.g. service groups, bindings, certificates) in pages of this size, concurrently within -parallelism (0 disables paging)")
+	flag.IntVar(&retries, "retries", netscaler.DefaultRetries, "Retry API requests failing with a connection error, HTTP 502/503/504 or a busy appliance this many times (0 disables retries)")
+	flag.DurationVar(&retryBackoff, "retry-backoff", netscaler.DefaultRetryBackoff, "Backoff before the first retry, doubled with jitter for every further retry")
+	flag.StringVar(&recordDir, "record-dir", "", "Record every Nitro request and response to this directory, with passwords and session IDs scrubbed")
+	flag.DurationVar(&probeCacheTTL, "probe-cache-ttl", 15*time.Minute, "Close sessions of /probe targets that are not configured and not probed for this long (0 keeps them forever)")
+	flag.StringVar(&allowedHosts, "probe-allowed-hosts", "", "Comma-separated host patterns (e.g. *.example.com,10.0.0.*) that /probe accepts besides the configured targets, scraped with the default credentials (empty allows configured targets only)")
+	flag.DurationVar(&pollInterval, "poll-interval", 0, "Poll targets in the background at this interval and serve the last snapshot (0 scrapes on every request)")
+	flag.DurationVar(&timeoutOffset, "scrape-timeout-offset", 500*time.Millisecond, "Subtracted from the Prometheus scrape timeout (X-Prometheus-Scrape-Timeout-Seconds) to bound a scrape")
+	flag.DurationVar(&moduleTimeout, "module-timeout", 0, "Maximum duration of each module's scrape (0 bounds modules only by the scrape timeout)")
+	flag.DurationVar(&capRefresh, "capability-refresh-interval", time.Hour, "Re-detect the appliance's license, features and HA setup at this interval to skip unsupported modules (0 disables detection)")
+	flag.IntVar(&breakerFailures, "circuit-breaker-failures", 5, "Suspend a module after this many consecutive failed scrapes (0 disables the circuit breaker)")
+	flag.DurationVar(&breakerBackoff, "circuit-breaker-backoff", time.Minute, "How long a failing module is suspended before it is retried")
+	flag.DurationVar(&breakerMax, "circuit-breaker-max-backoff", time.Hour, "Upper bound of the suspension, which doubles on every failed retry")
+	flag.BoolVar(&legacyNames, "legacy-metric-names", false, "Also publish cumulative counters under their metric schema 1 gauge names (e.g. netscaler_virtual_servers_total_requests)")
 	flag.BoolVar(&showVersion, "version", false, "Display application version")
 	flag.BoolVar(&debug, "debug", false, "Enable debug logging")
 	flag.Parse()
@@ -63,9 +106,7 @@ func main() {
 		url = config.GetURL()
 	}
 	if url == "" {
-		logger.Error("URL is required (use -url flag or NETSCALER_URL env var)")
-		flag.Usage()
-		os.Exit(1)
+		logger.Info("no URL configured (use -url flag or NETSCALER_URL env var), only /probe is available")
 	}
 
 	// Type: CLI flag takes precedence over env var, default to "adc"
@@ -94,14 +135,28 @@ func main() {
 	disabled := append(envDisabled, cliDisabled...)
 
 	cfg := &config.Config{
-		Labels:          labels,
-		DisabledModules: disabled,
+		Labels:            labels,
+		DisabledModules:   disabled,
+		LegacyMetricNames: legacyNames,
+		Timeout:           moduleTimeout,
+		CapabilityRefresh: capRefresh,
+		BreakerFailures:   breakerFailures,
+		BreakerBackoff:    breakerBackoff,
+		BreakerMaxBackoff: breakerMax,
 	}
 
 	// Get credentials from environment (optional for unauthenticated access)
 	username, password := config.GetCredentials()
-	if username == "" {
+	usernameFile, passwordFile := config.GetCredentialFiles()
+	creds := config.NewFileCredentials(username, password, usernameFile, passwordFile)
+	if err := creds.Reload(); err != nil {
+		logger.Error("failed to read credentials (NETSCALER_USERNAME_FILE, NETSCALER_PASSWORD_FILE)", "err", err)
+		os.Exit(1)
+	}
+	if user, _, _ := creds.Get(); user == "" {
 		logger.Info("no credentials provided, running without authentication")
+	} else if usernameFile != "" || passwordFile != "" {
+		logger.Info("reading credentials from files", "username_file", usernameFile, "password_file", passwordFile)
 	}
 
 	ignoreCert := config.GetIgnoreCert()
@@ -114,26 +169,112 @@ func main() {
 		logger.Info("using custom CA file", "path", caFile)
 	}
 
-	logger.Info("starting exporter", "url", url, "type", targetType, "labels", len(labels), "disabled_modules", len(disabled))
+	certFile, keyFile := config.GetClientCert()
+	clientTLS := config.TLSConfig{InsecureSkipVerify: ignoreCert, CAFile: caFile, CertFile: certFile, KeyFile: keyFile}
+	if err := clientTLS.Validate(); err != nil {
+		logger.Error("invalid TLS settings (NETSCALER_IGNORE_CERT, NETSCALER_CA_FILE, NETSCALER_CLIENT_CERT_FILE, NETSCALER_CLIENT_KEY_FILE)", "err", err)
+		os.Exit(1)
+	}
+	if certFile != "" {
+		logger.Info("using client certificate", "path", certFile)
+	}
 
-	// Create exporter
-	exporter, err := collector.NewExporter(cfg, url, targetType, username, password, ignoreCert, caFile, parallelism, logger)
-	if err != nil {
-		logger.Error("failed to create exporter", "err", err)
+	authMode := config.GetAuthMode()
+	if err := config.ValidateAuthMode(authMode); err != nil {
+		logger.Error("invalid NETSCALER_AUTH_MODE", "err", err)
+		os.Exit(1)
+	}
+
+	if recordDir != "" {
+		logger.Warn("recording Nitro traffic, recordings contain the appliances' configuration", "dir", recordDir)
+	}
+
+	// Defaults for ad-hoc probe targets and for settings a configured target leaves unset
+	defaults := config.Target{
+		Type:         targetType,
+		AuthMode:     authMode,
+		Username:     username,
+		Password:     password,
+		UsernameFile: usernameFile,
+		PasswordFile: passwordFile,
+		TLS:          clientTLS,
+		Module:       config.DefaultModule,
+		Config:       cfg,
+		PollInterval: pollInterval,
+		RateLimit:    rateLimit,
+		RateBurst:    rateBurst,
+		CallBudget:   callBudget,
+		PageSize:     pageSize,
+		RecordDir:    recordDir,
+	}
+
+	newExporter := exporterFactory(parallelism, retries, retryBackoff, logger)
+
+	var exporter *collector.Exporter
+	if url != "" {
+		logger.Info("starting exporter", "url", url, "type", targetType, "labels", len(labels), "disabled_modules", len(disabled))
+
+		// Create exporter for the implicit target from flags and environment variables
+		t := defaults
+		t.URL = url
+		var err error
+		exporter, err = newExporter(t)
+		if err != nil {
+			logger.Error("failed to create exporter", "err", err)
+			os.Exit(1)
+		}
+	}
+
+	// Probe modules: built-in default module, extended by the config file
+	modules := map[string]*config.Config{config.DefaultModule: cfg}
+	manager := collector.NewManager(defaults, modules, nil, newExporter, probeCacheTTL, logger)
+	manager.SetAllowedHosts(config.ParseList(allowedHosts))
+
+	// Reload the config file on SIGHUP and POST /-/reload
+	reloader := &reloader{path: configFile, defaults: defaults, manager: manager, logger: logger}
+	if configFile != "" {
+		file, err := config.LoadFile(configFile)
+		if err != nil {
+			logger.Error("failed to load config file", "path", configFile, "err", err)
+			os.Exit(1)
+		}
+		reloader.load(file)
+		logger.Info("loaded config file", "path", configFile, "targets", len(file.Targets), "modules", len(file.Modules), "adm_discoveries", len(file.Discovery.ADM))
+	}
+	reloader.markLoaded()
+	reloader.watchSIGHUP()
+
+	// Metric schema version, so dashboards and alerts can tell which names to expect
+	prometheus.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name:        "netscaler_exporter_metric_schema_version",
+		Help:        "Version of the exported metric names and types",
+		ConstLabels: prometheus.Labels{"legacy_names": strconv.FormatBool(legacyNames)},
+	}, func() float64 { return collector.MetricSchemaVersion }))
+
+	// Nitro API client metrics are served with the exporter's own metrics on /metrics
+	if err := netscaler.RegisterMetrics(prometheus.DefaultRegisterer); err != nil {
+		logger.Error("failed to register API metrics", "err", err)
 		os.Exit(1)
 	}
 
-	// Register with default Prometheus registry
-	prometheus.MustRegister(exporter)
+	sdURL, err := externalURL(webExternalURL, bindPort)
+	if err != nil {
+		logger.Error("invalid -web.external-url", "err", err)
+		os.Exit(1)
+	}
 
 	// Setup HTTP handlers
-	http.Handle("/metrics", promhttp.Handler())
+	http.Handle("/metrics", metricsHandler(exporter, timeoutOffset))
+	http.Handle("/probe", probeHandler(manager, timeoutOffset, logger))
+	http.Handle("/-/reload", reloader.handler())
+	http.Handle("/sd", sdHandler(manager, sdURL, logger))
+	http.Handle("/status/modules", statusHandler(exporter, url, manager, logger))
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
 	})
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		w.Write([]byte(app + " - /metrics for Prometheus metrics"))
+		w.Write([]byte(app + " - /metrics for Prometheus metrics, /probe?target=<host>&module=<name> for multi-target probes, /sd for Prometheus HTTP service discovery, /status/modules for failing modules"))
 	})
 
 	listenAddr := ":" + strconv.Itoa(bindPort)
@@ -149,3 +290,31 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// exporterFactory returns the factory creating the exporters of all targets,
+// live or replayed.
+func exporterFactory(parallelism, retries int, retryBackoff time.Duration, logger *slog.Logger) collector.ExporterFactory {
+	return func(t config.Target) (*collector.Exporter, error) {
+		exporter, err := collector.NewExporter(t.Config, t.URL, t.APIType(), t.AuthMode, t.Credentials(), t.TLS, parallelism, logger)
+		if err != nil {
+			return nil, err
+		}
+		if t.ProxyInstance != "" {
+			exporter.SetProxyInstance(t.ProxyInstance)
+		}
+		exporter.SetRateLimit(t.RateLimit, t.RateBurst)
+		exporter.SetCallBudget(t.CallBudget)
+		exporter.SetPageSize(t.PageSize)
+		exporter.SetRetries(retries, retryBackoff)
+		if t.Type == "replay" {
+			exporter.SetReplay(t.Replay.Dir)
+		} else {
+			exporter.SetRecording(t.RecordDir)
+		}
+		exporter.StartCapabilityDetection()
+		if t.PollInterval > 0 {
+			exporter.StartPolling(t.PollInterval)
+		}
+		return exporter, nil
+	}
+}
@@ -0,0 +1,276 @@
+package netscaler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// Authentication modes of a NitroClient.
+const (
+	AuthSession = "session" // POST config/login, then a session cookie (default)
+	AuthHeader  = "header"  // X-NITRO-USER/X-NITRO-PASS headers on every request
+	AuthMTLS    = "mtls"    // the client certificate of the TLS connection only
+)
+
+// Authenticator authenticates the requests of a client (see BaseClient).
+type Authenticator interface {
+	// Authenticate adds credentials to req, logging in through c first if
+	// the method uses sessions.
+	Authenticate(ctx context.Context, c *BaseClient, req *http.Request) error
+	// Expire discards the session after the API reported it as expired and
+	// reports whether the request should be retried with a new one.
+	Expire() bool
+	// Logout ends the session, if any.
+	Logout(ctx context.Context, c *BaseClient) error
+}
+
+// NewAuthenticator returns the authenticator for the given mode. An empty
+// mode selects session authentication.
+func NewAuthenticator(mode string, creds Credentials) (Authenticator, error) {
+	switch mode {
+	case "", AuthSession:
+		return NewSessionAuth(creds), nil
+	case AuthHeader:
+		return NewHeaderAuth(creds)
+	case AuthMTLS:
+		return CertAuth{}, nil
+	default:
+		return nil, fmt.Errorf("unknown authentication mode %q (must be '%s', '%s' or '%s')", mode, AuthSession, AuthHeader, AuthMTLS)
+	}
+}
+
+// SessionAuth logs in with POST config/login and sends the session ID as a
+// cookie. Without a username requests are sent unauthenticated. When the
+// credentials change, the session is logged out and a new one logged in; a
+// failed login reloads the credentials before the next attempt.
+type SessionAuth struct {
+	creds     Credentials
+	sessionID string
+	username  string // credentials of the session
+	password  string
+	mu        sync.Mutex
+}
+
+// NewSessionAuth returns a session authenticator for the given credentials.
+func NewSessionAuth(creds Credentials) *SessionAuth {
+	return &SessionAuth{creds: creds}
+}
+
+// Authenticate implements Authenticator.
+func (a *SessionAuth) Authenticate(ctx context.Context, c *BaseClient, req *http.Request) error {
+	username, password, err := a.creds.Get()
+	if err != nil {
+		return fmt.Errorf("failed to get login credentials: %w", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.sessionID != "" && (username != a.username || password != a.password) {
+		if c.logger != nil {
+			c.logger.Info("credentials changed, logging in again", "url", c.url)
+		}
+		if err := a.logout(ctx, c, a.sessionID); err != nil && c.logger != nil {
+			c.logger.Warn("failed to log out session of previous credentials", "url", c.url, "err", err)
+		}
+		a.sessionID = ""
+	}
+
+	// No credentials - skip login (for unauthenticated access)
+	if username == "" {
+		return nil
+	}
+	if a.sessionID == "" {
+		if err := a.login(ctx, c, username, password); err != nil {
+			if rerr := a.creds.Reload(); rerr != nil && c.logger != nil {
+				c.logger.Warn("failed to reload credentials", "url", c.url, "err", rerr)
+			}
+			return err
+		}
+	}
+	req.Header.Set("Cookie", "sessionid="+a.sessionID)
+	return nil
+}
+
+// loginResponse represents the JSON response from the login endpoint.
+type loginResponse struct {
+	SessionID string `json:"sessionid"`
+	ErrorCode int    `json:"errorcode"`
+	Message   string `json:"message"`
+}
+
+// login authenticates with the Nitro API and stores the session ID.
+// The caller must hold a.mu.
+func (a *SessionAuth) login(ctx context.Context, c *BaseClient, username, password string) (err error) {
+	defer func() { observeLogin(c.MetricsTarget(), err) }()
+
+	payload := map[string]interface{}{
+		"login": map[string]string{
+			"username": username,
+			"password": password,
+		},
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal login payload: %w", err)
+	}
+
+	url := c.url + "config/login"
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create login request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("login request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read login response: %w", err)
+	}
+
+	var loginResp loginResponse
+	if err := json.Unmarshal(body, &loginResp); err != nil {
+		return fmt.Errorf("failed to parse login response: %w", err)
+	}
+
+	if loginResp.ErrorCode != 0 {
+		return fmt.Errorf("login failed: %w", &NitroError{StatusCode: resp.StatusCode, ErrorCode: loginResp.ErrorCode, Message: loginResp.Message, Resource: "config/login"})
+	}
+
+	a.sessionID = loginResp.SessionID
+	a.username = username
+	a.password = password
+	if c.logger != nil {
+		c.logger.Info("session login successful", "url", c.url)
+	}
+	return nil
+}
+
+// Expire implements Authenticator.
+func (a *SessionAuth) Expire() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.sessionID == "" {
+		return false
+	}
+	a.sessionID = ""
+	return true
+}
+
+// Logout implements Authenticator. It ends the session on the Nitro API and
+// clears the session state. If no session is active, this is a no-op.
+func (a *SessionAuth) Logout(ctx context.Context, c *BaseClient) error {
+	a.mu.Lock()
+	sessionID := a.sessionID
+	a.sessionID = ""
+	a.mu.Unlock()
+
+	if sessionID == "" {
+		return nil
+	}
+	return a.logout(ctx, c, sessionID)
+}
+
+// logout ends the given session on the Nitro API.
+func (a *SessionAuth) logout(ctx context.Context, c *BaseClient, sessionID string) error {
+	payload := map[string]interface{}{
+		"logout": map[string]string{},
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal logout payload: %w", err)
+	}
+
+	url := c.url + "config/logout"
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create logout request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Cookie", "sessionid="+sessionID)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("logout request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("logout failed: %s", resp.Status)
+	}
+
+	if c.logger != nil {
+		c.logger.Info("session logout successful", "url", c.url)
+	}
+	return nil
+}
+
+// HeaderAuth sends the credentials in the X-NITRO-USER and X-NITRO-PASS
+// headers of every request, for appliances that only allow stateless access.
+type HeaderAuth struct {
+	Credentials Credentials
+}
+
+// NewHeaderAuth returns a header authenticator for the given credentials.
+// Unlike sessions, header authentication has no unauthenticated fallback, so
+// it fails without a username.
+func NewHeaderAuth(creds Credentials) (HeaderAuth, error) {
+	username, _, err := creds.Get()
+	if err != nil {
+		return HeaderAuth{}, fmt.Errorf("failed to get login credentials: %w", err)
+	}
+	if username == "" {
+		return HeaderAuth{}, errors.New("header authentication requires a username")
+	}
+	return HeaderAuth{Credentials: creds}, nil
+}
+
+// Authenticate implements Authenticator.
+func (a HeaderAuth) Authenticate(ctx context.Context, c *BaseClient, req *http.Request) error {
+	username, password, err := a.Credentials.Get()
+	if err != nil {
+		return fmt.Errorf("failed to get login credentials: %w", err)
+	}
+	if username == "" {
+		return errors.New("header authentication requires a username")
+	}
+	req.Header.Set("X-NITRO-USER", username)
+	req.Header.Set("X-NITRO-PASS", password)
+	return nil
+}
+
+// Expire implements Authenticator. Header credentials do not expire.
+func (a HeaderAuth) Expire() bool { return false }
+
+// Logout implements Authenticator. There is no session to end.
+func (a HeaderAuth) Logout(ctx context.Context, c *BaseClient) error { return nil }
+
+// CertAuth relies on the client certificate presented during the TLS
+// handshake (see TLSConfig) and adds nothing to requests.
+type CertAuth struct{}
+
+// Authenticate implements Authenticator.
+func (CertAuth) Authenticate(ctx context.Context, c *BaseClient, req *http.Request) error {
+	return nil
+}
+
+// Expire implements Authenticator. The certificate does not expire per session.
+func (CertAuth) Expire() bool { return false }
+
+// Logout implements Authenticator. There is no session to end.
+func (CertAuth) Logout(ctx context.Context, c *BaseClient) error { return nil }
@@ -0,0 +1,234 @@
+package netscaler
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestHeaderAuth checks that header authentication sends the credentials with
+// every request and never logs in.
+func TestHeaderAuth(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/nitro/v1/config/login" {
+			t.Error("header authentication logged in")
+		}
+		if r.Header.Get("X-NITRO-USER") != "user" || r.Header.Get("X-NITRO-PASS") != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("Cookie") != "" {
+			t.Errorf("unexpected cookie %q", r.Header.Get("Cookie"))
+		}
+		io.WriteString(w, `{"errorcode":0,"ns":{}}`)
+	}))
+	defer srv.Close()
+
+	auth, err := NewAuthenticator(AuthHeader, StaticCredentials{Username: "user", Password: "pass"})
+	if err != nil {
+		t.Fatalf("NewAuthenticator: %v", err)
+	}
+	c, err := NewNitroClient(srv.URL, auth, TLSConfig{}, nil)
+	if err != nil {
+		t.Fatalf("NewNitroClient: %v", err)
+	}
+	if _, err := c.GetStats(context.Background(), "ns", ""); err != nil {
+		t.Fatalf("GetStats: %v", err)
+	}
+	if err := c.Logout(context.Background()); err != nil {
+		t.Errorf("Logout: %v", err)
+	}
+}
+
+// TestSessionAuthRelogin checks that an expired session is replaced by a new
+// login and the request retried.
+func TestSessionAuthRelogin(t *testing.T) {
+	logins := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/nitro/v1/config/login":
+			logins++
+			fmt.Fprintf(w, `{"errorcode":0,"sessionid":"s%d"}`, logins)
+		case "/nitro/v1/stat/ns":
+			if r.Header.Get("Cookie") != "sessionid=s2" {
+				io.WriteString(w, `{"errorcode":444,"message":"Session expired"}`)
+				return
+			}
+			io.WriteString(w, `{"errorcode":0,"ns":{}}`)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewNitroClient(srv.URL, NewSessionAuth(StaticCredentials{Username: "user", Password: "pass"}), TLSConfig{}, nil)
+	if err != nil {
+		t.Fatalf("NewNitroClient: %v", err)
+	}
+	if _, err := c.GetStats(context.Background(), "ns", ""); err != nil {
+		t.Fatalf("GetStats: %v", err)
+	}
+	if logins != 2 {
+		t.Errorf("logins = %d, want 2", logins)
+	}
+}
+
+// rotatingCredentials is a password source whose new value is only picked
+// up by Reload, like a secret file that was not re-read yet.
+type rotatingCredentials struct {
+	password, next string
+	reloads        int
+}
+
+func (c *rotatingCredentials) Get() (string, string, error) { return "user", c.password, nil }
+
+func (c *rotatingCredentials) Reload() error {
+	c.reloads++
+	c.password = c.next
+	return nil
+}
+
+// TestSessionAuthRotation checks that a failed login reloads the credentials
+// and that changed credentials replace the session.
+func TestSessionAuthRotation(t *testing.T) {
+	valid := "new"
+	var logins, logouts int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/nitro/v1/config/login":
+			var body struct {
+				Login struct{ Password string } `json:"login"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			if body.Login.Password != valid {
+				io.WriteString(w, `{"errorcode":354,"message":"Invalid username or password"}`)
+				return
+			}
+			logins++
+			fmt.Fprintf(w, `{"errorcode":0,"sessionid":"s%d"}`, logins)
+		case "/nitro/v1/config/logout":
+			logouts++
+		case "/nitro/v1/stat/ns":
+			io.WriteString(w, `{"errorcode":0,"ns":{}}`)
+		}
+	}))
+	defer srv.Close()
+
+	creds := &rotatingCredentials{password: "old", next: "new"}
+	c, err := NewNitroClient(srv.URL, NewSessionAuth(creds), TLSConfig{}, nil)
+	if err != nil {
+		t.Fatalf("NewNitroClient: %v", err)
+	}
+	if _, err := c.GetStats(context.Background(), "ns", ""); err == nil {
+		t.Fatal("GetStats succeeded with the old password")
+	}
+	if creds.reloads != 1 {
+		t.Errorf("reloads = %d, want 1", creds.reloads)
+	}
+	if _, err := c.GetStats(context.Background(), "ns", ""); err != nil {
+		t.Fatalf("GetStats after reload: %v", err)
+	}
+
+	valid, creds.password = "newer", "newer"
+	if _, err := c.GetStats(context.Background(), "ns", ""); err != nil {
+		t.Fatalf("GetStats after rotation: %v", err)
+	}
+	if logins != 2 || logouts != 1 {
+		t.Errorf("logins = %d, logouts = %d, want 2 and 1", logins, logouts)
+	}
+}
+
+// TestHeaderAuthWithoutCredentials checks that header authentication without
+// a username is rejected when it is created, not on the first scrape.
+func TestHeaderAuthWithoutCredentials(t *testing.T) {
+	if _, err := NewAuthenticator(AuthHeader, StaticCredentials{}); err == nil {
+		t.Error("NewAuthenticator accepted header authentication without a username")
+	}
+	if _, err := NewAuthenticator(AuthSession, StaticCredentials{}); err != nil {
+		t.Errorf("NewAuthenticator rejected unauthenticated sessions: %v", err)
+	}
+}
+
+func TestNewAuthenticatorUnknownMode(t *testing.T) {
+	if _, err := NewAuthenticator("kerberos", StaticCredentials{}); err == nil {
+		t.Error("NewAuthenticator accepted an unknown mode")
+	}
+}
+
+// TestCertAuth checks that mtls authentication presents the client certificate
+// and sends no credentials.
+func TestCertAuth(t *testing.T) {
+	certFile, keyFile := writeClientCert(t)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 {
+			t.Error("no client certificate presented")
+		}
+		if r.Header.Get("X-NITRO-USER") != "" || r.Header.Get("Cookie") != "" {
+			t.Error("credentials sent with mtls authentication")
+		}
+		io.WriteString(w, `{"errorcode":0,"ns":{}}`)
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	srv.StartTLS()
+	defer srv.Close()
+
+	auth, err := NewAuthenticator(AuthMTLS, StaticCredentials{Username: "user", Password: "pass"})
+	if err != nil {
+		t.Fatalf("NewAuthenticator: %v", err)
+	}
+	c, err := NewNitroClient(srv.URL, auth, TLSConfig{InsecureSkipVerify: true, CertFile: certFile, KeyFile: keyFile}, nil)
+	if err != nil {
+		t.Fatalf("NewNitroClient: %v", err)
+	}
+	if _, err := c.GetStats(context.Background(), "ns", ""); err != nil {
+		t.Fatalf("GetStats: %v", err)
+	}
+}
+
+// writeClientCert writes a self-signed client certificate and its key to a
+// temporary directory.
+func writeClientCert(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "client.crt")
+	keyFile = filepath.Join(dir, "client.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
@@ -1,18 +1,13 @@
 package netscaler
 
 import (
-	"bytes"
 	"context"
-	"crypto/tls"
-	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
-	"os"
 	"strings"
-	"sync"
 	"time"
 )
 
@@ -22,165 +17,153 @@ const (
 	NSERR_AUTHTIMEOUT     = 0x403 // 1027 - Auth timeout
 )
 
-// NitroClient represents the client used to connect to the API.
-// It uses session-based authentication with automatic re-login on session expiration.
-type NitroClient struct {
-	url       string
-	username  string
-	password  string
-	client    *http.Client
-	sessionID string
-	sessionMu sync.Mutex
-	logger    *slog.Logger
+// MPSProxyInstanceHeader tells Citrix ADM to forward a Nitro request to the
+// managed instance with the given IP, using the instance profile held by ADM.
+const MPSProxyInstanceHeader = "_MPS_API_PROXY_MANAGED_INSTANCE_IP"
+
+// Client is a Nitro API client of any flavor. The Get* functions and the
+// collectors depend on it only, so they work with every flavor and with fakes.
+type Client interface {
+	// GetStats retrieves stats for the given type.
+	GetStats(ctx context.Context, statsType string, querystring string) ([]byte, error)
+	// GetConfig retrieves configuration for the given type.
+	GetConfig(ctx context.Context, configType string, querystring string) ([]byte, error)
+	// GetConfigCollection retrieves the configuration of all entities of the
+	// given type, paged if the client has a page size.
+	GetConfigCollection(ctx context.Context, configType string, querystring string) ([]byte, error)
 }
 
-// NewNitroClient creates a new client used to interact with the Nitro API.
-// Uses session-based authentication with automatic re-login on session expiration.
-// If caFile is provided, it will be used for TLS verification.
-// If ignoreCert is true, TLS verification is skipped entirely.
-func NewNitroClient(url string, username string, password string, ignoreCert bool, caFile string, logger *slog.Logger) (*NitroClient, error) {
-	transport := &http.Transport{
-		Proxy:               http.ProxyFromEnvironment,
-		MaxIdleConns:        20,
-		MaxIdleConnsPerHost: 20,
-		IdleConnTimeout:     30 * time.Second,
-	}
+// Base paths of the Nitro API flavors.
+const (
+	APIPathV1 = "/nitro/v1/" // NetScaler ADC
+	APIPathV2 = "/nitro/v2/" // Citrix ADM (MPS)
+)
 
-	if ignoreCert {
-		transport.TLSClientConfig = &tls.Config{
-			InsecureSkipVerify: true,
-		}
-	} else if caFile != "" {
-		caCert, err := os.ReadFile(caFile)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read CA file: %w", err)
-		}
-		caCertPool := x509.NewCertPool()
-		if !caCertPool.AppendCertsFromPEM(caCert) {
-			return nil, fmt.Errorf("failed to parse CA certificate")
-		}
-		transport.TLSClientConfig = &tls.Config{
-			RootCAs: caCertPool,
-		}
+// BaseClient is the core shared by all Nitro API flavors: the HTTP transport,
+// authentication, rate limit, call budget, retries, paging and metrics. The
+// flavors (NitroClient, MPSClient) wrap it with their API path and
+// authentication; new target types plug in the same way.
+type BaseClient struct {
+	url           string
+	client        *http.Client
+	auth          Authenticator
+	proxyInstance string
+	limiter       *rateLimiter
+	pageSize      int
+	retry         retryPolicy
+	logger        *slog.Logger
+}
+
+// NewBaseClient creates the core of a client for the Nitro API at url with
+// the given API path, e.g. APIPathV1.
+// Requests are authenticated by auth, e.g. a session with automatic re-login
+// on session expiration.
+// If tlsCfg has a CA file, it will be used for TLS verification; with
+// InsecureSkipVerify, TLS verification is skipped entirely. A client
+// certificate in tlsCfg is presented for mutual TLS.
+func NewBaseClient(url, apiPath string, auth Authenticator, tlsCfg TLSConfig, logger *slog.Logger) (*BaseClient, error) {
+	transport, err := newTransport(tlsCfg)
+	if err != nil {
+		return nil, err
 	}
 
-	return &NitroClient{
-		url:      strings.Trim(url, " /") + "/nitro/v1/",
-		username: username,
-		password: password,
+	return &BaseClient{
+		url:   strings.Trim(url, " /") + apiPath,
+		auth:  auth,
+		retry: retryPolicy{retries: DefaultRetries, backoff: DefaultRetryBackoff},
+		// Requests are bounded by their context, e.g. the scrape deadline
 		client: &http.Client{
-			Timeout:   30 * time.Second,
 			Transport: transport,
 		},
 		logger: logger,
 	}, nil
 }
 
-// CloseIdleConnections closes idle connections in the transport pool.
-func (c *NitroClient) CloseIdleConnections() {
-	c.client.CloseIdleConnections()
-}
-
-// loginResponse represents the JSON response from the login endpoint.
-type loginResponse struct {
-	SessionID string `json:"sessionid"`
-	ErrorCode int    `json:"errorcode"`
-	Message   string `json:"message"`
+// NitroClient represents the client used to connect to the NetScaler ADC
+// Nitro v1 API. Requests are authenticated by an Authenticator: a session
+// with automatic re-login on session expiration, per-request headers or a
+// client certificate.
+type NitroClient struct {
+	*BaseClient
 }
 
-// Login authenticates with the Nitro API and stores the session ID.
-// If already logged in, this is a no-op.
-func (c *NitroClient) Login(ctx context.Context) error {
-	c.sessionMu.Lock()
-	defer c.sessionMu.Unlock()
-
-	// Already logged in
-	if c.sessionID != "" {
-		return nil
-	}
-
-	// No credentials - skip login (for unauthenticated access)
-	if c.username == "" {
-		return nil
-	}
-
-	payload := map[string]interface{}{
-		"login": map[string]string{
-			"username": c.username,
-			"password": c.password,
-		},
-	}
-
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("failed to marshal login payload: %w", err)
-	}
-
-	url := c.url + "config/login"
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+// NewNitroClient creates a new client used to interact with the Nitro API.
+// See NewBaseClient for auth and tlsCfg.
+func NewNitroClient(url string, auth Authenticator, tlsCfg TLSConfig, logger *slog.Logger) (*NitroClient, error) {
+	base, err := NewBaseClient(url, APIPathV1, auth, tlsCfg, logger)
 	if err != nil {
-		return fmt.Errorf("failed to create login request: %w", err)
+		return nil, err
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
+	return &NitroClient{base}, nil
+}
 
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return fmt.Errorf("login request failed: %w", err)
-	}
-	defer resp.Body.Close()
+// SetProxyInstance routes all stat and config requests through Citrix ADM to the
+// managed instance with the given IP. The client's URL and credentials must
+// point at the ADM. Login and logout still go to the ADM itself.
+func (c *NitroClient) SetProxyInstance(ip string) {
+	c.proxyInstance = ip
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read login response: %w", err)
+// SetRateLimit limits stat and config requests to rate per second on average,
+// with bursts of up to burst requests. A rate of 0 removes the limit.
+func (c *BaseClient) SetRateLimit(rate float64, burst int) {
+	if rate <= 0 {
+		c.limiter = nil
+		return
 	}
+	c.limiter = newRateLimiter(rate, burst)
+}
 
-	var loginResp loginResponse
-	if err := json.Unmarshal(body, &loginResp); err != nil {
-		return fmt.Errorf("failed to parse login response: %w", err)
-	}
+// SetRetries retries requests failing with a connection error, a truncated
+// response body or a transient Nitro error (see IsTransient) up to retries
+// times, after a jittered backoff starting at backoff and doubling per
+// retry. 0 retries disables retrying.
+func (c *BaseClient) SetRetries(retries int, backoff time.Duration) {
+	c.retry = retryPolicy{retries: retries, backoff: backoff}
+}
 
-	if loginResp.ErrorCode != 0 {
-		return fmt.Errorf("login failed: %s (errorcode: %d)", loginResp.Message, loginResp.ErrorCode)
-	}
+// CloseIdleConnections closes idle connections in the transport pool.
+func (c *BaseClient) CloseIdleConnections() {
+	c.client.CloseIdleConnections()
+}
 
-	c.sessionID = loginResp.SessionID
-	if c.logger != nil {
-		c.logger.Info("session login successful", "url", c.url)
-	}
-	return nil
+// Logout ends the client's session, if its authentication uses one.
+func (c *BaseClient) Logout(ctx context.Context) error {
+	return c.auth.Logout(ctx, c)
 }
 
-// Logout clears the session state.
-func (c *NitroClient) Logout() {
-	c.sessionMu.Lock()
-	defer c.sessionMu.Unlock()
-	c.sessionID = ""
+// MetricsTarget returns the target label of the client's API metrics.
+func (c *BaseClient) MetricsTarget() string {
+	return metricsTarget(c.url, c.proxyInstance)
 }
 
-// HasSession returns true if a session is active.
-func (c *NitroClient) HasSession() bool {
-	c.sessionMu.Lock()
-	defer c.sessionMu.Unlock()
-	return c.sessionID != ""
+// get performs an authenticated GET request to the Nitro API.
+// Automatically handles session expiration by re-logging in and retries
+// transient failures. The request is charged to the call budget of ctx once,
+// however often it is retried.
+func (c *BaseClient) get(ctx context.Context, path string, querystring string) ([]byte, error) {
+	if budget := callBudgetFrom(ctx); budget != nil && !budget.take() {
+		observeCallBudgetExhausted(c.MetricsTarget())
+		return nil, ErrCallBudgetExhausted
+	}
+	return c.retry.do(ctx, c.logger, c.MetricsTarget(), path, func() ([]byte, error) {
+		return c.doGet(ctx, path, querystring, true)
+	})
 }
 
-// get performs a GET request to the Nitro API using session-based auth.
-// Automatically handles session expiration by re-logging in.
-func (c *NitroClient) get(ctx context.Context, path string, querystring string) ([]byte, error) {
-	// Ensure we have a session (or no auth needed)
-	if c.username != "" && !c.HasSession() {
-		if err := c.Login(ctx); err != nil {
+// doGet performs the actual GET request. If retryOnSessionExpiry is true and
+// the session has expired, it will re-login and retry once.
+func (c *BaseClient) doGet(ctx context.Context, path string, querystring string, retryOnSessionExpiry bool) ([]byte, error) {
+	if c.limiter != nil {
+		wait, err := c.limiter.wait(ctx)
+		if err != nil {
 			return nil, err
 		}
+		if wait > 0 {
+			observeRateLimitWait(c.MetricsTarget(), wait)
+		}
 	}
 
-	return c.doGet(ctx, path, querystring, true)
-}
-
-// doGet performs the actual GET request. If retryOnSessionExpiry is true and
-// the session has expired, it will re-login and retry once.
-func (c *NitroClient) doGet(ctx context.Context, path string, querystring string, retryOnSessionExpiry bool) ([]byte, error) {
 	url := c.url + path
 	if querystring != "" {
 		url = url + "?" + querystring
@@ -191,61 +174,60 @@ func (c *NitroClient) doGet(ctx context.Context, path string, querystring string
 		return nil, fmt.Errorf("error creating HTTP request: %w", err)
 	}
 
-	// Use session cookie if we have one, otherwise no auth
-	c.sessionMu.Lock()
-	if c.sessionID != "" {
-		req.Header.Set("Cookie", "sessionid="+c.sessionID)
+	if err := c.auth.Authenticate(ctx, c, req); err != nil {
+		return nil, err
 	}
-	c.sessionMu.Unlock()
 	req.Header.Set("Accept", "application/json")
+	if c.proxyInstance != "" {
+		req.Header.Set(MPSProxyInstanceHeader, c.proxyInstance)
+	}
 
+	start := time.Now()
 	resp, err := c.client.Do(req)
 	if err != nil {
 		if resp != nil {
 			io.Copy(io.Discard, resp.Body)
 			resp.Body.Close()
 		}
+		observeRequest(c.MetricsTarget(), path, 0, nil, 0, time.Since(start))
 		return nil, fmt.Errorf("error sending request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("error reading response body: %w", err)
+		observeRequest(c.MetricsTarget(), path, 0, nil, 0, time.Since(start))
+		return nil, &bodyError{err: err}
 	}
 
+	var apiResp nitroResponse
+	json.Unmarshal(body, &apiResp)
+	observeRequest(c.MetricsTarget(), path, resp.StatusCode, apiResp.ErrorCode, len(body), time.Since(start))
+
 	// Check for session expiration in the response
-	if retryOnSessionExpiry && resp.StatusCode == http.StatusOK {
-		var apiResp struct {
-			ErrorCode int `json:"errorcode"`
-		}
-		if json.Unmarshal(body, &apiResp) == nil {
-			if apiResp.ErrorCode == NSERR_SESSION_EXPIRED || apiResp.ErrorCode == NSERR_AUTHTIMEOUT {
-				if c.logger != nil {
-					c.logger.Info("session expired, re-logging in", "url", c.url)
-				}
-				c.Logout()
-				if err := c.Login(ctx); err != nil {
-					return nil, fmt.Errorf("re-login failed: %w", err)
-				}
-				// Retry once without allowing further retries
-				return c.doGet(ctx, path, querystring, false)
+	if retryOnSessionExpiry && resp.StatusCode == http.StatusOK && apiResp.ErrorCode != nil {
+		if errorCode := *apiResp.ErrorCode; (errorCode == NSERR_SESSION_EXPIRED || errorCode == NSERR_AUTHTIMEOUT) && c.auth.Expire() {
+			observeRelogin(c.MetricsTarget(), errorCode)
+			if c.logger != nil {
+				c.logger.Info("session expired, re-logging in", "url", c.url)
 			}
+			// Retry once without allowing further retries
+			return c.doGet(ctx, path, querystring, false)
 		}
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return body, fmt.Errorf("request failed: %s (%s)", resp.Status, string(body))
+	if err := checkResponse(path, resp.StatusCode, apiResp); err != nil {
+		return body, err
 	}
 	return body, nil
 }
 
 // GetStats sends a request to the Nitro API and retrieves stats for the given type.
-func (c *NitroClient) GetStats(ctx context.Context, statsType string, querystring string) ([]byte, error) {
+func (c *BaseClient) GetStats(ctx context.Context, statsType string, querystring string) ([]byte, error) {
 	return c.get(ctx, "stat/"+statsType, querystring)
 }
 
 // GetConfig sends a request to the Nitro API and retrieves configuration for the given type.
-func (c *NitroClient) GetConfig(ctx context.Context, configType string, querystring string) ([]byte, error) {
+func (c *BaseClient) GetConfig(ctx context.Context, configType string, querystring string) ([]byte, error) {
 	return c.get(ctx, "config/"+configType, querystring)
 }
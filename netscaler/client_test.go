@@ -0,0 +1,48 @@
+package netscaler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestMPSClient checks that the ADM flavor uses the v2 API path and the
+// shared session handling, including re-login after an expired session.
+func TestMPSClient(t *testing.T) {
+	logins := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/nitro/v2/config/login":
+			logins++
+			fmt.Fprintf(w, `{"errorcode":0,"sessionid":"s%d"}`, logins)
+		case "/nitro/v2/stat/mps_health":
+			if r.Header.Get("Cookie") != "sessionid=s2" {
+				io.WriteString(w, `{"errorcode":444,"message":"Session expired"}`)
+				return
+			}
+			io.WriteString(w, `{"errorcode":0,"mps_health":[{"cpu_usage":"12.5"}]}`)
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewMPSClient(srv.URL, StaticCredentials{Username: "admin", Password: "secret"}, TLSConfig{}, nil)
+	if err != nil {
+		t.Fatalf("NewMPSClient: %v", err)
+	}
+	resp, err := GetMPSHealth(context.Background(), c)
+	if err != nil {
+		t.Fatalf("GetMPSHealth: %v", err)
+	}
+	if len(resp.MPSHealth) != 1 {
+		t.Errorf("got %d mps_health entries, want 1", len(resp.MPSHealth))
+	}
+	if logins != 2 {
+		t.Errorf("logins = %d, want 2", logins)
+	}
+}
@@ -0,0 +1,29 @@
+package netscaler
+
+// Credentials provides the username and password of a client. Implementations
+// may re-read them from their source, e.g. a rotated secret file, so clients
+// ask for them on every login instead of keeping a copy.
+type Credentials interface {
+	// Get returns the current username and password. An empty username means
+	// unauthenticated access.
+	Get() (username, password string, err error)
+	// Reload re-reads the credentials from their source, e.g. after a
+	// failed login.
+	Reload() error
+}
+
+// StaticCredentials are credentials that never change.
+type StaticCredentials struct {
+	Username string
+	Password string
+}
+
+// Get implements Credentials.
+func (c StaticCredentials) Get() (string, string, error) {
+	return c.Username, c.Password, nil
+}
+
+// Reload implements Credentials. Static credentials have no source to re-read.
+func (c StaticCredentials) Reload() error {
+	return nil
+}
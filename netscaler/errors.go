@@ -0,0 +1,177 @@
+package netscaler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Nitro API error codes classified by the Is* helpers
+const (
+	NSERR_BUSY                = 0x10  // 16 - Resource busy
+	NSERR_AGAIN               = 0x23  // 35 - Resource temporarily unavailable, try again
+	NSERR_FEATURE_NOT_ENABLED = 0x101 // 257 - Feature(s) not enabled
+	NSERR_NOENT               = 0x102 // 258 - No such resource
+	NSERR_NOUSER              = 0x162 // 354 - Invalid username or password
+	NSERR_NOT_LICENSED        = 0x445 // 1093 - Feature(s) not licensed
+	NSERR_NOT_AUTHORIZED      = 0x85A // 2138 - Not authorized to execute this command
+)
+
+// knownErrorCode reports whether the Is* helpers classify code by itself.
+// Errors with another code, or none, fall back to their HTTP status and
+// message, whose wording varies between firmware releases.
+func knownErrorCode(code int) bool {
+	switch code {
+	case NSERR_BUSY, NSERR_AGAIN, NSERR_FEATURE_NOT_ENABLED, NSERR_NOENT, NSERR_NOUSER, NSERR_NOT_LICENSED,
+		NSERR_NOT_AUTHORIZED, NSERR_SESSION_EXPIRED, NSERR_AUTHTIMEOUT:
+		return true
+	}
+	return false
+}
+
+// messageContains reports whether the message of an error with an unknown
+// errorcode contains any of the phrases, ignoring case.
+func (e *NitroError) messageContains(phrases ...string) bool {
+	if knownErrorCode(e.ErrorCode) {
+		return false
+	}
+	msg := strings.ToLower(e.Message)
+	for _, p := range phrases {
+		if strings.Contains(msg, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// NitroError is an error response of the Nitro API: a non-2xx HTTP status or
+// a non-zero errorcode with severity ERROR.
+type NitroError struct {
+	StatusCode int    // HTTP status code
+	ErrorCode  int    // Nitro errorcode, 0 if the body had none
+	Message    string // Nitro message
+	Severity   string // Nitro severity, e.g. "ERROR"
+	Resource   string // requested resource, e.g. "stat/lbvserver"
+}
+
+func (e *NitroError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	return fmt.Sprintf("request failed: %s: %s (HTTP %d, errorcode: %d)", e.Resource, msg, e.StatusCode, e.ErrorCode)
+}
+
+// nitroResponse holds the status fields of every Nitro response body.
+type nitroResponse struct {
+	ErrorCode *int   `json:"errorcode"`
+	Message   string `json:"message"`
+	Severity  string `json:"severity"`
+}
+
+// checkResponse returns a NitroError if the response reports a failure.
+// Warnings come with a non-zero errorcode but the requested data, so they
+// are not failures.
+func checkResponse(resource string, statusCode int, resp nitroResponse) error {
+	errorCode := 0
+	if resp.ErrorCode != nil {
+		errorCode = *resp.ErrorCode
+	}
+	if statusCode >= 200 && statusCode < 300 && (errorCode == 0 || strings.EqualFold(resp.Severity, "WARNING")) {
+		return nil
+	}
+	return &NitroError{
+		StatusCode: statusCode,
+		ErrorCode:  errorCode,
+		Message:    resp.Message,
+		Severity:   resp.Severity,
+		Resource:   resource,
+	}
+}
+
+// asNitroError returns the NitroError in err's chain, or nil.
+func asNitroError(err error) *NitroError {
+	var nerr *NitroError
+	if errors.As(err, &nerr) {
+		return nerr
+	}
+	return nil
+}
+
+// IsNotFound reports whether err is a Nitro error for a resource that does
+// not exist on the appliance.
+func IsNotFound(err error) bool {
+	e := asNitroError(err)
+	return e != nil && (e.StatusCode == http.StatusNotFound || e.ErrorCode == NSERR_NOENT)
+}
+
+// IsUnsupported reports whether err is a Nitro error for a resource the
+// appliance does not support, e.g. because its feature is not licensed or
+// not enabled, or the firmware is too old. It classifies by errorcode; the
+// message is only matched for errorcodes it does not know.
+func IsUnsupported(err error) bool {
+	e := asNitroError(err)
+	if e == nil {
+		return false
+	}
+	switch e.ErrorCode {
+	case NSERR_FEATURE_NOT_ENABLED, NSERR_NOT_LICENSED:
+		return true
+	}
+	return e.StatusCode == http.StatusNotImplemented ||
+		e.messageContains("not licensed", "not enabled", "not supported", "invalid resource")
+}
+
+// IsPermissionDenied reports whether err is a Nitro error for a resource the
+// exporter's user may not read, e.g. due to its command policy. It classifies
+// by errorcode and HTTP status; the message is only matched for errorcodes it
+// does not know.
+func IsPermissionDenied(err error) bool {
+	e := asNitroError(err)
+	if e == nil {
+		return false
+	}
+	if e.ErrorCode == NSERR_NOT_AUTHORIZED {
+		return true
+	}
+	return (e.StatusCode == http.StatusForbidden && !knownErrorCode(e.ErrorCode)) || e.messageContains("not authorized")
+}
+
+// IsAuthFailure reports whether err is a Nitro error for rejected or expired
+// credentials.
+func IsAuthFailure(err error) bool {
+	e := asNitroError(err)
+	if e == nil {
+		return false
+	}
+	switch e.ErrorCode {
+	case NSERR_NOUSER, NSERR_SESSION_EXPIRED, NSERR_AUTHTIMEOUT:
+		return true
+	}
+	return e.StatusCode == http.StatusUnauthorized || e.Resource == "config/login"
+}
+
+// IsTransient reports whether err is a Nitro error that is likely to go away
+// on its own, such as an overloaded management plane or a busy resource. It
+// classifies by errorcode; other known errorcodes are never transient, and
+// unknown ones are classified by HTTP status and, as a fallback, by the
+// message.
+func IsTransient(err error) bool {
+	e := asNitroError(err)
+	if e == nil {
+		return false
+	}
+	switch e.ErrorCode {
+	case NSERR_BUSY, NSERR_AGAIN:
+		return true
+	}
+	if knownErrorCode(e.ErrorCode) {
+		return false
+	}
+	switch e.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return e.messageContains("busy", "try again")
+}
@@ -0,0 +1,138 @@
+package netscaler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+// TestNitroError checks that error responses are returned as NitroError and
+// classified, while warnings are not errors.
+func TestNitroError(t *testing.T) {
+	responses := map[string]struct {
+		status int
+		body   string
+	}{
+		"/nitro/v1/stat/lbvserver":   {http.StatusOK, `{"errorcode":258,"message":"No such resource","severity":"ERROR"}`},
+		"/nitro/v1/stat/vpnvserver":  {599, `{"errorcode":1093,"message":"Feature(s) not licensed","severity":"ERROR"}`},
+		"/nitro/v1/stat/csvserver":   {599, `{"errorcode":2138,"message":"Not authorized to execute this command","severity":"ERROR"}`},
+		"/nitro/v1/stat/ns":          {http.StatusServiceUnavailable, ``},
+		"/nitro/v1/stat/gslbvserver": {http.StatusOK, `{"errorcode":1,"message":"Partial data","severity":"WARNING","gslbvserver":[]}`},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := responses[r.URL.Path]
+		w.WriteHeader(resp.status)
+		io.WriteString(w, resp.body)
+	}))
+	defer srv.Close()
+
+	c, err := NewNitroClient(srv.URL, CertAuth{}, TLSConfig{}, nil)
+	if err != nil {
+		t.Fatalf("NewNitroClient: %v", err)
+	}
+	c.SetRetries(0, 0)
+
+	for _, tc := range []struct {
+		resource string
+		is       func(error) bool
+	}{
+		{"lbvserver", IsNotFound},
+		{"vpnvserver", IsUnsupported},
+		{"csvserver", IsPermissionDenied},
+		{"ns", IsTransient},
+	} {
+		_, err := c.GetStats(context.Background(), tc.resource, "")
+		var nerr *NitroError
+		if !errors.As(err, &nerr) {
+			t.Errorf("%s: error %v is not a NitroError", tc.resource, err)
+			continue
+		}
+		if nerr.Resource != "stat/"+tc.resource {
+			t.Errorf("%s: resource = %q", tc.resource, nerr.Resource)
+		}
+		if !tc.is(err) {
+			t.Errorf("%s: %v classified wrongly", tc.resource, err)
+		}
+	}
+
+	if _, err := c.GetStats(context.Background(), "gslbvserver", ""); err != nil {
+		t.Errorf("warning returned as error: %v", err)
+	}
+}
+
+// TestClassifyNitroError checks that errors are classified by errorcode, and
+// by HTTP status and message only if the errorcode is unknown.
+func TestClassifyNitroError(t *testing.T) {
+	classify := func(err error) []string {
+		var classes []string
+		for _, c := range []struct {
+			name string
+			is   func(error) bool
+		}{
+			{"not_found", IsNotFound},
+			{"unsupported", IsUnsupported},
+			{"permission", IsPermissionDenied},
+			{"auth", IsAuthFailure},
+			{"transient", IsTransient},
+		} {
+			if c.is(err) {
+				classes = append(classes, c.name)
+			}
+		}
+		return classes
+	}
+
+	tests := []struct {
+		name string
+		err  *NitroError
+		want []string
+	}{
+		{"not licensed", &NitroError{StatusCode: 599, ErrorCode: NSERR_NOT_LICENSED, Message: "Lizenz fehlt"}, []string{"unsupported"}},
+		{"not enabled", &NitroError{StatusCode: 599, ErrorCode: NSERR_FEATURE_NOT_ENABLED}, []string{"unsupported"}},
+		{"not authorized", &NitroError{StatusCode: 599, ErrorCode: NSERR_NOT_AUTHORIZED}, []string{"permission"}},
+		{"no such resource", &NitroError{StatusCode: 200, ErrorCode: NSERR_NOENT, Message: "No such resource, try again later"}, []string{"not_found"}},
+		{"session expired", &NitroError{StatusCode: 503, ErrorCode: NSERR_SESSION_EXPIRED}, []string{"auth"}},
+		{"unknown code, licensing message", &NitroError{StatusCode: 599, ErrorCode: 9999, Message: "Feature(s) not licensed [GSLB]"}, []string{"unsupported"}},
+		{"unknown code, authorization message", &NitroError{StatusCode: 599, ErrorCode: 9999, Message: "Not authorized to execute this command"}, []string{"permission"}},
+		{"forbidden", &NitroError{StatusCode: 403}, []string{"permission"}},
+		{"busy", &NitroError{StatusCode: 599, ErrorCode: 9999, Message: "Resource busy"}, []string{"transient"}},
+		{"unavailable", &NitroError{StatusCode: 503}, []string{"transient"}},
+		{"other", &NitroError{StatusCode: 599, ErrorCode: 9999, Message: "Invalid argument"}, nil},
+	}
+	for _, tt := range tests {
+		if got := classify(fmt.Errorf("wrapped: %w", tt.err)); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: classified as %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestTransientErrorCodes checks that busy errorcodes are transient whatever
+// the HTTP status and message, and other known errorcodes never are.
+func TestTransientErrorCodes(t *testing.T) {
+	tests := []struct {
+		code   int
+		status int
+		want   bool
+	}{
+		{NSERR_BUSY, 599, true},
+		{NSERR_BUSY, http.StatusOK, true},
+		{NSERR_AGAIN, 599, true},
+		{NSERR_AGAIN, http.StatusInternalServerError, true},
+		{NSERR_NOENT, http.StatusServiceUnavailable, false},
+		{NSERR_NOT_LICENSED, 599, false},
+		{NSERR_SESSION_EXPIRED, http.StatusServiceUnavailable, false},
+		{9999, 599, false},
+		{9999, http.StatusServiceUnavailable, true},
+	}
+	for _, tt := range tests {
+		err := &NitroError{StatusCode: tt.status, ErrorCode: tt.code}
+		if got := IsTransient(err); got != tt.want {
+			t.Errorf("errorcode %d, HTTP %d: transient = %v, want %v", tt.code, tt.status, got, tt.want)
+		}
+	}
+}
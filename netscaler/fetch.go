@@ -0,0 +1,136 @@
+package netscaler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// pathKind is the kind of path a resource is requested at.
+type pathKind int
+
+const (
+	statPath             pathKind = iota // stat/<resource>
+	configPath                           // config/<resource>
+	configCollectionPath                 // config/<resource>, paged if the client has a page size
+)
+
+// getter returns the method of c requesting resources of kind k.
+func (k pathKind) getter(c Client) func(context.Context, string, string) ([]byte, error) {
+	switch k {
+	case configPath:
+		return c.GetConfig
+	case configCollectionPath:
+		return c.GetConfigCollection
+	}
+	return c.GetStats
+}
+
+// Options are the query parameters of a Stat or Config request.
+type Options struct {
+	Attrs        []string          // attrs: only return these attributes
+	Filter       map[string]string // filter: only return entities with these attribute values
+	Args         map[string]string // args: arguments of the resource, e.g. a node ID
+	StatBindings bool              // statbindings=yes: include the stats of bound entities
+	BulkBindings bool              // bulkbindings=yes: the bindings of all entities at once
+
+	// Collection fetches a configuration resource as a collection, in pages if
+	// the client has a page size (see BaseClient.SetPageSize). Nitro pages
+	// only configuration, so Stat ignores it.
+	Collection bool
+}
+
+// query returns the Nitro query string of the options.
+func (o Options) query() string {
+	var params []string
+	if len(o.Attrs) > 0 {
+		params = append(params, "attrs="+strings.Join(o.Attrs, ","))
+	}
+	if len(o.Filter) > 0 {
+		params = append(params, "filter="+keyValues(o.Filter))
+	}
+	if len(o.Args) > 0 {
+		params = append(params, "args="+keyValues(o.Args))
+	}
+	if o.StatBindings {
+		params = append(params, "statbindings=yes")
+	}
+	if o.BulkBindings {
+		params = append(params, "bulkbindings=yes")
+	}
+	return strings.Join(params, "&")
+}
+
+// keyValues formats m as the sorted, comma-separated key:value list of the
+// filter and args parameters.
+func keyValues(m map[string]string) string {
+	pairs := make([]string, 0, len(m))
+	for k, v := range m {
+		pairs = append(pairs, k+":"+url.QueryEscape(v))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Stat retrieves the stats of resource, e.g. "lbvserver" or
+// "servicegroup/web", and decodes its entries into T. Only the resource's key
+// of the response is decoded.
+func Stat[T any](ctx context.Context, c Client, resource string, opts Options) ([]T, error) {
+	return fetch[T](ctx, c, statPath, resource, opts)
+}
+
+// Config retrieves the configuration of resource, e.g. "servicegroup" or
+// "lbvserver_service_binding/web", and decodes its entries into T. Only the
+// resource's key of the response is decoded.
+func Config[T any](ctx context.Context, c Client, resource string, opts Options) ([]T, error) {
+	kind := configPath
+	if opts.Collection {
+		kind = configCollectionPath
+	}
+	return fetch[T](ctx, c, kind, resource, opts)
+}
+
+func fetch[T any](ctx context.Context, c Client, kind pathKind, resource string, opts Options) ([]T, error) {
+	body, err := kind.getter(c)(ctx, resource, opts.query())
+	if err != nil {
+		return nil, fmt.Errorf("error getting %s: %w", resource, err)
+	}
+	entries, err := decodeResource[T](body, resource)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshalling %s: %w", resource, err)
+	}
+	return entries, nil
+}
+
+// decodeResource decodes the entries under the key of resource, its type
+// without the entity name. Nitro returns a list for most resources and a
+// single object for global ones such as "ns"; a missing key means no entries.
+func decodeResource[T any](body []byte, resource string) ([]T, error) {
+	key, _, _ := strings.Cut(resource, "/")
+
+	var resp map[string]json.RawMessage
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+	raw, ok := resp[key]
+	if !ok {
+		return nil, nil
+	}
+
+	if raw = bytes.TrimSpace(raw); len(raw) > 0 && raw[0] == '{' {
+		var entry T
+		if err := json.Unmarshal(raw, &entry); err != nil {
+			return nil, err
+		}
+		return []T{entry}, nil
+	}
+	var entries []T
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
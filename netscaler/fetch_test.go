@@ -0,0 +1,72 @@
+package netscaler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestStatConfig checks that the generic fetch helpers build the query from
+// their options and decode lists, global objects and missing keys.
+func TestStatConfig(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		switch r.URL.Path {
+		case "/nitro/v1/stat/lbvserver":
+			io.WriteString(w, `{"errorcode":0,"lbvserver":[{"name":"lb1"},{"name":"lb2"}],"servicegroup":"ignored"}`)
+		case "/nitro/v1/stat/ns":
+			io.WriteString(w, `{"errorcode":0,"ns":{"cpuusagepcnt":"3.5"}}`)
+		case "/nitro/v1/config/servicegroup/web":
+			io.WriteString(w, `{"errorcode":0}`)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewNitroClient(srv.URL, CertAuth{}, TLSConfig{}, nil)
+	if err != nil {
+		t.Fatalf("NewNitroClient: %v", err)
+	}
+	ctx := context.Background()
+
+	type vserver struct {
+		Name string `json:"name"`
+	}
+	vservers, err := Stat[vserver](ctx, c, "lbvserver", Options{
+		Attrs:  []string{"name", "state"},
+		Filter: map[string]string{"state": "UP", "name": "lb 1"},
+	})
+	if err != nil {
+		t.Fatalf("Stat lbvserver: %v", err)
+	}
+	if len(vservers) != 2 || vservers[1].Name != "lb2" {
+		t.Errorf("lbvserver = %+v", vservers)
+	}
+	if want := "attrs=name,state&filter=name:lb+1,state:UP"; query != want {
+		t.Errorf("query = %q, want %q", query, want)
+	}
+
+	type ns struct {
+		CPU string `json:"cpuusagepcnt"`
+	}
+	global, err := Stat[ns](ctx, c, "ns", Options{})
+	if err != nil {
+		t.Fatalf("Stat ns: %v", err)
+	}
+	if len(global) != 1 || global[0].CPU != "3.5" {
+		t.Errorf("ns = %+v", global)
+	}
+
+	groups, err := Config[ServiceGroups](ctx, c, "servicegroup/web", Options{StatBindings: true})
+	if err != nil {
+		t.Fatalf("Config servicegroup: %v", err)
+	}
+	if len(groups) != 0 {
+		t.Errorf("servicegroup = %+v, want none", groups)
+	}
+	if query != "statbindings=yes" {
+		t.Errorf("query = %q, want statbindings=yes", query)
+	}
+}
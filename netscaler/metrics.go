@@ -0,0 +1,156 @@
+package netscaler
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsNamespace = "netscaler_exporter"
+
+var (
+	apiRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "api_request_duration_seconds",
+		Help:      "Latency of Nitro API requests by target and resource",
+		Buckets:   []float64{.01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	}, []string{"target", "resource"})
+	apiResponseSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "api_response_size_bytes",
+		Help:      "Size of Nitro API response bodies by target and resource",
+		Buckets:   prometheus.ExponentialBuckets(256, 4, 8), // 256B .. 4MiB
+	}, []string{"target", "resource"})
+	apiResponses = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "api_responses_total",
+		Help:      "Nitro API responses by target, resource, HTTP status code and Nitro errorcode (code=\"error\" for transport failures)",
+	}, []string{"target", "resource", "code", "errorcode"})
+	apiLogins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "api_logins_total",
+		Help:      "Nitro session logins by target and result",
+	}, []string{"target", "result"})
+	apiRelogins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "api_relogins_total",
+		Help:      "Nitro session re-logins after an expired session by target and reason",
+	}, []string{"target", "reason"})
+	apiRateLimitWait = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "api_rate_limit_wait_seconds",
+		Help:      "Time Nitro API requests were delayed by the client-side rate limiter by target",
+		Buckets:   []float64{.01, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"target"})
+	apiCallBudgetExhausted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "api_call_budget_exhausted_total",
+		Help:      "Nitro API requests refused because the scrape's call budget was spent, by target",
+	}, []string{"target"})
+	apiRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "api_retries_total",
+		Help:      "Nitro API requests retried after a connection error or transient failure by target, resource and reason",
+	}, []string{"target", "resource", "reason"})
+)
+
+// RegisterMetrics registers the Nitro API client metrics with reg.
+func RegisterMetrics(reg prometheus.Registerer) error {
+	for _, c := range []prometheus.Collector{apiRequestDuration, apiResponseSize, apiResponses, apiLogins, apiRelogins, apiRateLimitWait, apiCallBudgetExhausted, apiRetries} {
+		if err := reg.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ForgetTarget deletes the API metrics of target (see BaseClient.MetricsTarget).
+// Call it once no client of the target is left, so that targets that are no
+// longer scraped do not leave their series behind.
+func ForgetTarget(target string) {
+	labels := prometheus.Labels{"target": target}
+	apiRequestDuration.DeletePartialMatch(labels)
+	apiResponseSize.DeletePartialMatch(labels)
+	apiResponses.DeletePartialMatch(labels)
+	apiLogins.DeletePartialMatch(labels)
+	apiRelogins.DeletePartialMatch(labels)
+	apiRateLimitWait.DeletePartialMatch(labels)
+	apiCallBudgetExhausted.DeletePartialMatch(labels)
+	apiRetries.DeletePartialMatch(labels)
+}
+
+// metricsTarget returns the target label for a client base URL: the host, or
+// the managed instance IP when requests are proxied through ADM.
+func metricsTarget(baseURL, proxyInstance string) string {
+	if proxyInstance != "" {
+		return proxyInstance
+	}
+	if u, err := url.Parse(baseURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return baseURL
+}
+
+// metricsResource reduces a request path to its resource type, e.g.
+// "stat/servicegroup/web" becomes "stat/servicegroup".
+func metricsResource(path string) string {
+	parts := strings.SplitN(path, "/", 3)
+	if len(parts) < 2 {
+		return path
+	}
+	return parts[0] + "/" + parts[1]
+}
+
+// observeRequest records a completed API request. status is 0 for transport
+// failures and errorCode is nil when the body carried no Nitro errorcode.
+func observeRequest(target, path string, status int, errorCode *int, size int, duration time.Duration) {
+	resource := metricsResource(path)
+	apiRequestDuration.WithLabelValues(target, resource).Observe(duration.Seconds())
+
+	code := "error"
+	if status != 0 {
+		code = strconv.Itoa(status)
+		apiResponseSize.WithLabelValues(target, resource).Observe(float64(size))
+	}
+	errorcode := ""
+	if errorCode != nil {
+		errorcode = strconv.Itoa(*errorCode)
+	}
+	apiResponses.WithLabelValues(target, resource, code, errorcode).Inc()
+}
+
+// observeLogin records the result of a session login.
+func observeLogin(target string, err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	apiLogins.WithLabelValues(target, result).Inc()
+}
+
+// observeRelogin records a re-login caused by the given Nitro errorcode.
+func observeRelogin(target string, errorCode int) {
+	reason := "session_expired"
+	if errorCode == NSERR_AUTHTIMEOUT {
+		reason = "auth_timeout"
+	}
+	apiRelogins.WithLabelValues(target, reason).Inc()
+}
+
+// observeRateLimitWait records a request delayed by the rate limiter.
+func observeRateLimitWait(target string, wait time.Duration) {
+	apiRateLimitWait.WithLabelValues(target).Observe(wait.Seconds())
+}
+
+// observeRetry records a retried request.
+func observeRetry(target, path, reason string) {
+	apiRetries.WithLabelValues(target, metricsResource(path), reason).Inc()
+}
+
+// observeCallBudgetExhausted records a request refused by the call budget.
+func observeCallBudgetExhausted(target string) {
+	apiCallBudgetExhausted.WithLabelValues(target).Inc()
+}
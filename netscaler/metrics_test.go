@@ -0,0 +1,66 @@
+package netscaler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// seriesByTarget gathers the API metrics and counts their series per target.
+func seriesByTarget(t *testing.T) map[string]int {
+	t.Helper()
+	reg := prometheus.NewPedanticRegistry()
+	if err := RegisterMetrics(reg); err != nil {
+		t.Fatalf("RegisterMetrics: %v", err)
+	}
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather failed: %v", err)
+	}
+	series := make(map[string]int)
+	for _, mf := range families {
+		for _, m := range mf.GetMetric() {
+			for _, lp := range m.GetLabel() {
+				if lp.GetName() == "target" {
+					series[lp.GetValue()]++
+				}
+			}
+		}
+	}
+	return series
+}
+
+// TestForgetTarget checks that the observe helpers record series per target
+// and that ForgetTarget deletes those of one target only.
+func TestForgetTarget(t *testing.T) {
+	errorCode := NSERR_SESSION_EXPIRED
+	for _, target := range []string{"metrics-a:443", "metrics-b:443"} {
+		observeRequest(target, "stat/servicegroup/web", 200, &errorCode, 512, 10*time.Millisecond)
+		observeRequest(target, "stat/lbvserver", 0, nil, 0, time.Second)
+		observeLogin(target, nil)
+		observeLogin(target, errors.New("login failed"))
+		observeRelogin(target, NSERR_AUTHTIMEOUT)
+		observeRateLimitWait(target, 100*time.Millisecond)
+		observeRetry(target, "stat/lbvserver", "status_503")
+		observeCallBudgetExhausted(target)
+	}
+
+	// Duration and size histograms of two resources, responses of two codes,
+	// two login results and one series each of the other metrics
+	const want = 2 + 1 + 2 + 2 + 4
+	series := seriesByTarget(t)
+	if series["metrics-a:443"] != want || series["metrics-b:443"] != want {
+		t.Fatalf("series per target = %v, want %d for both", series, want)
+	}
+
+	ForgetTarget("metrics-a:443")
+	series = seriesByTarget(t)
+	if series["metrics-a:443"] != 0 {
+		t.Errorf("%d series of the forgotten target left", series["metrics-a:443"])
+	}
+	if series["metrics-b:443"] != want {
+		t.Errorf("%d series of the other target, want %d", series["metrics-b:443"], want)
+	}
+}
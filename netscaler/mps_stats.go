@@ -2,21 +2,19 @@ package netscaler
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 )
 
 // GetMPSHealth queries the Citrix ADM Nitro v2 API for mps_health stats.
-func GetMPSHealth(ctx context.Context, c *MPSClient) (MPSAPIResponse, error) {
-	data, err := c.GetStats(ctx, "mps_health", "")
-	if err != nil {
-		return MPSAPIResponse{}, err
-	}
+func GetMPSHealth(ctx context.Context, c Client) (MPSAPIResponse, error) {
+	return getResponse[MPSAPIResponse](ctx, c, statPath, "mps_health", "")
+}
 
-	var response MPSAPIResponse
-	if err = json.Unmarshal(data, &response); err != nil {
-		return MPSAPIResponse{}, fmt.Errorf("error unmarshalling mps_health response: %w", err)
-	}
+// GetMPSManagedDevices queries the Citrix ADM Nitro v2 API for all managed instances.
+func GetMPSManagedDevices(ctx context.Context, c Client) ([]MPSManagedDevice, error) {
+	return Config[MPSManagedDevice](ctx, c, "managed_device", Options{})
+}
 
-	return response, nil
+// GetMPSDatacenters queries the Citrix ADM Nitro v2 API for all sites.
+func GetMPSDatacenters(ctx context.Context, c Client) ([]MPSDatacenter, error) {
+	return Config[MPSDatacenter](ctx, c, "mps_datacenter", Options{})
 }
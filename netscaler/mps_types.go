@@ -1,5 +1,10 @@
 package netscaler
 
+import (
+	"encoding/json"
+	"strings"
+)
+
 // MPSHealthStats represents health statistics from a Citrix ADM (MPS) node.
 type MPSHealthStats struct {
 	NodeType    string `json:"node_type"`
@@ -17,3 +22,59 @@ type MPSHealthStats struct {
 type MPSAPIResponse struct {
 	MPSHealth []MPSHealthStats `json:"mps_health"`
 }
+
+// MPSManagedDevice represents an instance managed by Citrix ADM (managed_device resource).
+type MPSManagedDevice struct {
+	ID            string  `json:"id"`
+	IPAddress     string  `json:"ip_address"`
+	MgmtIPAddress string  `json:"mgmt_ip_address"`
+	Hostname      string  `json:"hostname"`
+	DisplayName   string  `json:"display_name"`
+	Type          string  `json:"type"`
+	Version       string  `json:"version"`
+	InstanceState string  `json:"instance_state"`
+	HAIPAddress   string  `json:"ha_ip_address"`
+	HAMasterState string  `json:"ha_master_state"`
+	DatacenterID  string  `json:"datacenter_id"`
+	ProfileName   string  `json:"profile_name"`
+	Tags          MPSTags `json:"tags"`
+}
+
+// MPSDatacenter represents an ADM site (mps_datacenter resource).
+type MPSDatacenter struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// MPSTags holds the key/value tags ADM attaches to an instance.
+// ADM returns them either as a list of {"key","value"} objects or as a
+// comma-separated "key:value" string; anything else is ignored.
+type MPSTags map[string]string
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *MPSTags) UnmarshalJSON(data []byte) error {
+	tags := MPSTags{}
+
+	var list []struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}
+	var str string
+	if json.Unmarshal(data, &list) == nil {
+		for _, kv := range list {
+			if kv.Key != "" {
+				tags[kv.Key] = kv.Value
+			}
+		}
+	} else if json.Unmarshal(data, &str) == nil {
+		for _, pair := range strings.Split(str, ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(pair), ":")
+			if key != "" {
+				tags[key] = value
+			}
+		}
+	}
+
+	*t = tags
+	return nil
+}
@@ -0,0 +1,50 @@
+package nitrotest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Fixtures maps Nitro resources to the response bodies served for them. A
+// resource is the request path below the API version, e.g. "stat/lbvserver",
+// "config/lbvserver_servicegroup_binding" or "stat/servicegroup/web".
+type Fixtures map[string]string
+
+// LoadFixtures reads fixtures from a JSON file holding an object that maps
+// resources to response bodies, e.g.
+//
+//	{"stat/lbvserver": {"lbvserver": [{"name": "web", "state": "UP"}]}}
+//
+// If path is a directory, the fixtures of all its *.json files are merged in
+// lexical order, later files overriding earlier ones.
+func LoadFixtures(path string) (Fixtures, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{path}
+	if info.IsDir() {
+		files, err = filepath.Glob(filepath.Join(path, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	fixtures := make(Fixtures)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var bodies map[string]json.RawMessage
+		if err := json.Unmarshal(data, &bodies); err != nil {
+			return nil, fmt.Errorf("error parsing fixtures %s: %w", file, err)
+		}
+		for resource, body := range bodies {
+			fixtures[resource] = string(body)
+		}
+	}
+	return fixtures, nil
+}
@@ -0,0 +1,448 @@
+// Package nitrotest provides a fake NetScaler Nitro API for tests of the
+// exporter and of other Nitro integrations.
+//
+// A Server serves the v1 (ADC) and v2 (ADM) APIs from fixtures: login and
+// logout with session IDs, stat and config resources with the filter and attrs
+// query parameters, count and paging of config resources (Nitro does not page
+// stats), single entities and bulkbindings. Faults
+// such as latency, error codes and expired sessions can be injected per
+// resource.
+package nitrotest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elohmeier/netscaler-exporter/netscaler"
+)
+
+// errArgMissing is the errorcode of a binding requested without an entity
+// name or bulkbindings=yes.
+const errArgMissing = 1092
+
+// Fault changes the responses to matching requests.
+type Fault struct {
+	// Resource is the request path below the API version, e.g.
+	// "stat/lbvserver" or "config/login". Empty matches every request.
+	Resource string
+	// Times is the number of requests the fault applies to, 0 for all.
+	Times int
+
+	// Latency delays the response.
+	Latency time.Duration
+	// ExpireSession ends the session of the request, which is then answered
+	// with errorcode 444 like a session the appliance timed out.
+	ExpireSession bool
+	// StatusCode and ErrorCode, if set, replace the response with an error.
+	// StatusCode defaults to 200 and ErrorCode to none.
+	StatusCode int
+	ErrorCode  int
+	Message    string
+	Severity   string // defaults to "ERROR"
+}
+
+// Request is a request received by a Server.
+type Request struct {
+	Method   string
+	Version  string // API version, "v1" or "v2"
+	Resource string // path below the API version, e.g. "stat/lbvserver"
+	Query    url.Values
+	Instance string // managed instance of an ADM proxy request, if any
+}
+
+// Server is a fake Nitro API. Without credentials (see SetCredentials) every
+// login succeeds and requests without a session are accepted. It is safe for
+// concurrent use.
+type Server struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	fixtures    Fixtures
+	username    string
+	password    string
+	sessions    map[string]bool
+	nextSession int
+	faults      []*Fault
+	requests    []Request
+}
+
+// NewServer starts a Server serving the given fixtures. The caller must call
+// Close when finished.
+func NewServer(fixtures Fixtures) *Server {
+	s := &Server{
+		fixtures: make(Fixtures, len(fixtures)),
+		sessions: make(map[string]bool),
+	}
+	for resource, body := range fixtures {
+		s.fixtures[resource] = body
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// SetCredentials requires requests to authenticate with the given username
+// and password, by session or X-NITRO-USER/X-NITRO-PASS headers.
+func (s *Server) SetCredentials(username, password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.username = username
+	s.password = password
+}
+
+// SetResource serves body for resource. An empty body removes the resource.
+func (s *Server) SetResource(resource, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if body == "" {
+		delete(s.fixtures, resource)
+		return
+	}
+	s.fixtures[resource] = body
+}
+
+// AddFault injects f. Faults are matched in the order they were added.
+func (s *Server) AddFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes all faults.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// ExpireSessions ends all sessions, as if the appliance timed them out.
+func (s *Server) ExpireSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.sessions)
+}
+
+// Requests returns the requests received so far, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Count returns the number of requests received for resource.
+func (s *Server) Count(resource string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, r := range s.requests {
+		if r.Resource == resource {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	var version, resource string
+	switch {
+	case strings.HasPrefix(r.URL.Path, netscaler.APIPathV1):
+		version, resource = "v1", strings.TrimPrefix(r.URL.Path, netscaler.APIPathV1)
+	case strings.HasPrefix(r.URL.Path, netscaler.APIPathV2):
+		version, resource = "v2", strings.TrimPrefix(r.URL.Path, netscaler.APIPathV2)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		Method:   r.Method,
+		Version:  version,
+		Resource: resource,
+		Query:    r.URL.Query(),
+		Instance: r.Header.Get(netscaler.MPSProxyInstanceHeader),
+	})
+	fault := s.fault(resource)
+	if fault != nil && fault.ExpireSession {
+		if cookie, err := r.Cookie("sessionid"); err == nil {
+			delete(s.sessions, cookie.Value)
+		}
+	}
+	s.mu.Unlock()
+
+	if fault != nil && fault.Latency > 0 {
+		timer := time.NewTimer(fault.Latency)
+		select {
+		case <-timer.C:
+		case <-r.Context().Done():
+			timer.Stop()
+			return
+		}
+	}
+	if fault != nil && (fault.StatusCode != 0 || fault.ErrorCode != 0) {
+		status := fault.StatusCode
+		if status == 0 {
+			status = http.StatusOK
+		}
+		severity := fault.Severity
+		if severity == "" {
+			severity = "ERROR"
+		}
+		writeJSON(w, status, map[string]any{"errorcode": fault.ErrorCode, "message": fault.Message, "severity": severity})
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPost && resource == "config/login":
+		s.login(w, r)
+	case r.Method == http.MethodPost && resource == "config/logout":
+		s.logout(w, r)
+	case r.Method == http.MethodGet:
+		if s.authorize(w, r) {
+			s.serveResource(w, resource, r.URL.Query())
+		}
+	default:
+		writeError(w, http.StatusMethodNotAllowed, 0, "Method not allowed")
+	}
+}
+
+// fault returns the first fault matching resource, counting the request
+// towards its Times. The caller must hold s.mu.
+func (s *Server) fault(resource string) *Fault {
+	for i, f := range s.faults {
+		if f.Resource != "" && f.Resource != resource {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+func (s *Server) login(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Login struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"login"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, 0, "Invalid login payload")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.username != "" && (payload.Login.Username != s.username || payload.Login.Password != s.password) {
+		writeError(w, http.StatusUnauthorized, netscaler.NSERR_NOUSER, "Invalid username or password")
+		return
+	}
+	s.nextSession++
+	sessionID := "session-" + strconv.Itoa(s.nextSession)
+	s.sessions[sessionID] = true
+	writeJSON(w, http.StatusCreated, map[string]any{"errorcode": 0, "message": "Done", "severity": "NONE", "sessionid": sessionID})
+}
+
+func (s *Server) logout(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie("sessionid"); err == nil {
+		s.mu.Lock()
+		delete(s.sessions, cookie.Value)
+		s.mu.Unlock()
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{"errorcode": 0, "message": "Done", "severity": "NONE"})
+}
+
+// authorize checks the credentials of r, answering it with an error and
+// returning false if they are missing or invalid. A session ID is checked
+// even without credentials, so that sessions can expire.
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cookie, err := r.Cookie("sessionid"); err == nil {
+		if !s.sessions[cookie.Value] {
+			writeError(w, http.StatusOK, netscaler.NSERR_SESSION_EXPIRED, "Session expired or killed. Please login again")
+			return false
+		}
+		return true
+	}
+	if s.username == "" {
+		return true
+	}
+	if user := r.Header.Get("X-NITRO-USER"); user != "" {
+		if user != s.username || r.Header.Get("X-NITRO-PASS") != s.password {
+			writeError(w, http.StatusUnauthorized, netscaler.NSERR_NOUSER, "Invalid username or password")
+			return false
+		}
+		return true
+	}
+	writeError(w, http.StatusUnauthorized, netscaler.NSERR_SESSION_EXPIRED, "Not logged in")
+	return false
+}
+
+// serveResource answers a GET of resource from the fixtures. A resource
+// without a fixture of its own is derived from the fixture of its collection:
+// "config/lbvserver_servicegroup_binding/web" serves the entries of
+// "config/lbvserver_servicegroup_binding" whose name is "web".
+func (s *Server) serveResource(w http.ResponseWriter, resource string, query url.Values) {
+	kind, name := resource, ""
+	if parts := strings.SplitN(resource, "/", 3); len(parts) == 3 {
+		kind, name = parts[0]+"/"+parts[1], parts[2]
+	}
+	key := kind[strings.Index(kind, "/")+1:]
+
+	if name == "" && strings.HasSuffix(key, "_binding") && query.Get("bulkbindings") != "yes" {
+		writeError(w, http.StatusBadRequest, errArgMissing, "Argument pre-requisite missing [name]")
+		return
+	}
+
+	s.mu.Lock()
+	body, ok := s.fixtures[resource]
+	if ok {
+		name = ""
+	} else if name != "" {
+		body, ok = s.fixtures[kind]
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		if name != "" {
+			writeError(w, http.StatusNotFound, netscaler.NSERR_NOENT, "No such resource ["+name+"]")
+			return
+		}
+		// Nitro omits the collection when it is empty
+		writeJSON(w, http.StatusOK, map[string]any{"errorcode": 0, "message": "Done", "severity": "NONE"})
+		return
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(body), &fields); err != nil {
+		writeError(w, http.StatusInternalServerError, 0, fmt.Sprintf("Invalid fixture for %s: %v", resource, err))
+		return
+	}
+	if raw, ok := fields[key]; ok {
+		if entries, err := selectEntries(raw, key, name, query, strings.HasPrefix(kind, "config/")); err != nil {
+			writeError(w, http.StatusBadRequest, 0, err.Error())
+			return
+		} else if entries != nil {
+			fields[key] = entries
+		}
+	}
+	if _, ok := fields["errorcode"]; !ok {
+		fields["errorcode"] = json.RawMessage("0")
+		fields["message"] = json.RawMessage(`"Done"`)
+		fields["severity"] = json.RawMessage(`"NONE"`)
+	}
+	writeJSON(w, http.StatusOK, fields)
+}
+
+// selectEntries applies the entity name and the filter, count, paging and
+// attrs query parameters to the collection raw; count and paging only if
+// paged. count=yes alone returns only the count; with pagesize, it returns
+// the page with the count in its first entry. It returns nil if raw is a single object, which is served as is.
+func selectEntries(raw json.RawMessage, key, name string, query url.Values, paged bool) (json.RawMessage, error) {
+	var entries []map[string]json.RawMessage
+	if json.Unmarshal(raw, &entries) != nil {
+		return nil, nil
+	}
+
+	filter := make(map[string]string)
+	if name != "" {
+		filter["name"] = name
+	}
+	if f := query.Get("filter"); f != "" {
+		for _, pair := range strings.Split(f, ",") {
+			k, v, _ := strings.Cut(pair, ":")
+			unescaped, err := url.QueryUnescape(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid filter %q", f)
+			}
+			filter[k] = unescaped
+		}
+	}
+	selected := entries[:0]
+	for _, entry := range entries {
+		if matches(entry, key, filter) {
+			selected = append(selected, entry)
+		}
+	}
+
+	count := len(selected)
+	counted := paged && query.Get("count") == "yes"
+	size := query.Get("pagesize")
+	if counted && size == "" {
+		return json.Marshal([]map[string]int{{"__count": count}})
+	}
+	if paged && size != "" {
+		pagesize, err := strconv.Atoi(size)
+		if err != nil || pagesize < 1 {
+			return nil, fmt.Errorf("invalid pagesize %q", size)
+		}
+		pageno, err := strconv.Atoi(query.Get("pageno"))
+		if err != nil || pageno < 1 {
+			return nil, fmt.Errorf("invalid pageno %q", query.Get("pageno"))
+		}
+		start := min((pageno-1)*pagesize, len(selected))
+		selected = selected[start:min(start+pagesize, len(selected))]
+	}
+	if attrs := query.Get("attrs"); attrs != "" {
+		keep := strings.Split(attrs, ",")
+		for i, entry := range selected {
+			projected := make(map[string]json.RawMessage, len(keep))
+			for _, attr := range keep {
+				if v, ok := entry[attr]; ok {
+					projected[attr] = v
+				}
+			}
+			selected[i] = projected
+		}
+	}
+	// A counted page carries the __count in its first entry
+	if counted && len(selected) > 0 {
+		selected[0]["__count"] = json.RawMessage(strconv.Itoa(count))
+	}
+	return json.Marshal(selected)
+}
+
+// matches reports whether entry has the attribute values of filter. The
+// "name" attribute also matches the <key>name attribute some resources use,
+// e.g. servicegroupname.
+func matches(entry map[string]json.RawMessage, key string, filter map[string]string) bool {
+	for attr, want := range filter {
+		raw, ok := entry[attr]
+		if !ok && attr == "name" {
+			raw, ok = entry[key+"name"]
+		}
+		if !ok {
+			return false
+		}
+		var s string
+		if json.Unmarshal(raw, &s) != nil {
+			s = string(raw)
+		}
+		if s != want {
+			return false
+		}
+	}
+	return true
+}
+
+func writeError(w http.ResponseWriter, status, errorCode int, message string) {
+	writeJSON(w, status, map[string]any{"errorcode": errorCode, "message": message, "severity": "ERROR"})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
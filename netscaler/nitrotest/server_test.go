@@ -0,0 +1,153 @@
+package nitrotest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/elohmeier/netscaler-exporter/netscaler"
+)
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	fixtures, err := LoadFixtures("testdata")
+	if err != nil {
+		t.Fatalf("LoadFixtures: %v", err)
+	}
+	srv := NewServer(fixtures)
+	t.Cleanup(srv.Close)
+	srv.SetCredentials("nsroot", "secret")
+	return srv
+}
+
+func newTestClient(t *testing.T, srv *Server) *netscaler.NitroClient {
+	t.Helper()
+	c, err := netscaler.NewNitroClient(srv.URL, netscaler.NewSessionAuth(netscaler.StaticCredentials{Username: "nsroot", Password: "secret"}), netscaler.TLSConfig{}, nil)
+	if err != nil {
+		t.Fatalf("NewNitroClient: %v", err)
+	}
+	return c
+}
+
+// TestSessionExpiry checks that the client logs in again after the fake ends
+// its session, both on demand and by an injected fault.
+func TestSessionExpiry(t *testing.T) {
+	srv := newTestServer(t)
+	c := newTestClient(t, srv)
+	ctx := context.Background()
+
+	if _, err := netscaler.GetNSStats(ctx, c, ""); err != nil {
+		t.Fatalf("GetNSStats: %v", err)
+	}
+	srv.ExpireSessions()
+	if _, err := netscaler.GetNSStats(ctx, c, ""); err != nil {
+		t.Fatalf("GetNSStats after ExpireSessions: %v", err)
+	}
+	srv.AddFault(Fault{Resource: "stat/ns", ExpireSession: true, Times: 1})
+	resp, err := netscaler.GetNSStats(ctx, c, "")
+	if err != nil {
+		t.Fatalf("GetNSStats after expiry fault: %v", err)
+	}
+	if resp.NSStats.MemUsagePcnt != 42 {
+		t.Errorf("memusagepcnt = %v, want 42", resp.NSStats.MemUsagePcnt)
+	}
+	if n := srv.Count("config/login"); n != 3 {
+		t.Errorf("logins = %d, want 3", n)
+	}
+
+	bad, err := netscaler.NewNitroClient(srv.URL, netscaler.HeaderAuth{Credentials: netscaler.StaticCredentials{Username: "nsroot", Password: "wrong"}}, netscaler.TLSConfig{}, nil)
+	if err != nil {
+		t.Fatalf("NewNitroClient: %v", err)
+	}
+	if _, err := netscaler.GetNSStats(ctx, bad, ""); !netscaler.IsAuthFailure(err) {
+		t.Errorf("GetNSStats with wrong password: err = %v, want an auth failure", err)
+	}
+}
+
+// TestResources checks bulk and per-entity bindings, paging and the v2 API.
+func TestResources(t *testing.T) {
+	srv := newTestServer(t)
+	c := newTestClient(t, srv)
+	ctx := context.Background()
+
+	all, err := netscaler.GetAllLBVServerServiceGroupBindings(ctx, c)
+	if err != nil {
+		t.Fatalf("GetAllLBVServerServiceGroupBindings: %v", err)
+	}
+	if len(all) != 2 {
+		t.Errorf("got %d bulk bindings, want 2", len(all))
+	}
+	one, err := netscaler.GetLBVServerServiceGroupBindings(ctx, c, "lb-api")
+	if err != nil {
+		t.Fatalf("GetLBVServerServiceGroupBindings: %v", err)
+	}
+	if len(one) != 1 || one[0].ServiceGroupName != "sg-api" {
+		t.Errorf("got bindings %+v, want sg-api only", one)
+	}
+	var nerr *netscaler.NitroError
+	if _, err := c.GetConfig(ctx, "lbvserver_servicegroup_binding", ""); !errors.As(err, &nerr) || nerr.StatusCode != http.StatusBadRequest {
+		t.Errorf("binding without name or bulkbindings: err = %v, want HTTP 400", err)
+	}
+
+	c.SetPageSize(1)
+	before := srv.Count("config/lbvserver_servicegroup_binding")
+	if all, err := netscaler.GetAllLBVServerServiceGroupBindings(ctx, c); err != nil || len(all) != 2 {
+		t.Fatalf("paged GetAllLBVServerServiceGroupBindings = %d bindings, %v, want 2", len(all), err)
+	}
+	if n := srv.Count("config/lbvserver_servicegroup_binding") - before; n != 2 {
+		t.Errorf("binding requests = %d, want 2 (the counted first page and the second)", n)
+	}
+	vservers, err := netscaler.GetVirtualServerStats(ctx, c, "")
+	if err != nil {
+		t.Fatalf("GetVirtualServerStats: %v", err)
+	}
+	if len(vservers.VirtualServerStats) != 3 {
+		t.Errorf("got %d lbvservers, want 3", len(vservers.VirtualServerStats))
+	}
+	if n := srv.Count("stat/lbvserver"); n != 1 {
+		t.Errorf("lbvserver requests = %d, want 1 (stats are not paged)", n)
+	}
+
+	mps, err := netscaler.NewMPSClient(srv.URL, netscaler.StaticCredentials{Username: "nsroot", Password: "secret"}, netscaler.TLSConfig{}, nil)
+	if err != nil {
+		t.Fatalf("NewMPSClient: %v", err)
+	}
+	health, err := netscaler.GetMPSHealth(ctx, mps)
+	if err != nil {
+		t.Fatalf("GetMPSHealth: %v", err)
+	}
+	if len(health.MPSHealth) != 1 {
+		t.Errorf("got %d mps_health entries, want 1", len(health.MPSHealth))
+	}
+	if r := srv.Requests(); r[len(r)-1].Version != "v2" {
+		t.Errorf("last request used API %s, want v2", r[len(r)-1].Version)
+	}
+}
+
+// TestFaults checks injected error codes and latency.
+func TestFaults(t *testing.T) {
+	srv := newTestServer(t)
+	c := newTestClient(t, srv)
+	c.SetRetries(1, time.Millisecond)
+	ctx := context.Background()
+
+	srv.AddFault(Fault{Resource: "stat/ns", StatusCode: http.StatusServiceUnavailable, Times: 1})
+	if _, err := netscaler.GetNSStats(ctx, c, ""); err != nil {
+		t.Errorf("GetNSStats after one transient fault: %v", err)
+	}
+
+	srv.AddFault(Fault{Resource: "stat/lbvserver", StatusCode: http.StatusNotFound, ErrorCode: netscaler.NSERR_NOENT, Message: "No such resource"})
+	if _, err := netscaler.GetVirtualServerStats(ctx, c, ""); !netscaler.IsNotFound(err) {
+		t.Errorf("GetVirtualServerStats: err = %v, want not found", err)
+	}
+	srv.ClearFaults()
+
+	srv.AddFault(Fault{Latency: time.Second})
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if _, err := netscaler.GetNSStats(ctx, c, ""); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetNSStats with latency: err = %v, want deadline exceeded", err)
+	}
+}
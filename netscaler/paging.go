@@ -0,0 +1,220 @@
+package netscaler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// SetPageSize makes GetConfigCollection fetch collections of more than size
+// entries in pages of size entries. A size of 0 fetches every collection in a
+// single response. Stats are never paged: Nitro supports count=yes, pagesize
+// and pageno on configuration resources only.
+func (c *BaseClient) SetPageSize(size int) {
+	c.pageSize = size
+}
+
+type semaphoreKey struct{}
+
+// WithSemaphore returns a context whose paged requests fetch additional pages
+// concurrently while they can take a token from sem. The caller is expected
+// to hold a token already; it is never waited for.
+func WithSemaphore(ctx context.Context, sem chan struct{}) context.Context {
+	return context.WithValue(ctx, semaphoreKey{}, sem)
+}
+
+// semaphoreFrom returns the semaphore of ctx, or nil if it has none.
+func semaphoreFrom(ctx context.Context) chan struct{} {
+	sem, _ := ctx.Value(semaphoreKey{}).(chan struct{})
+	return sem
+}
+
+// GetConfigCollection retrieves the configuration of all entities of the given
+// type, paging through them if a page size is set (see SetPageSize).
+func (c *BaseClient) GetConfigCollection(ctx context.Context, configType string, querystring string) ([]byte, error) {
+	return c.getCollection(ctx, "config/"+configType, querystring)
+}
+
+// getCollection fetches the first page of a collection together with its
+// __count (count=yes) and, if the collection does not fit in that page,
+// fetches the remaining pages and merges them into a single response. A
+// response without __count comes from an endpoint that ignored count=yes: it
+// is the whole collection, unless it holds exactly one page of entities and
+// the endpoint may have paged it, in which case the collection is fetched
+// again unpaged.
+func (c *BaseClient) getCollection(ctx context.Context, path string, querystring string) ([]byte, error) {
+	size := c.pageSize
+	if size <= 0 {
+		return c.get(ctx, path, querystring)
+	}
+	page := func(ctx context.Context, pageno int) ([]byte, error) {
+		return c.get(ctx, path, joinQuery(querystring, "pagesize="+strconv.Itoa(size)+"&pageno="+strconv.Itoa(pageno)))
+	}
+
+	first, err := c.get(ctx, path, joinQuery(querystring, "pagesize="+strconv.Itoa(size)+"&pageno=1&count=yes"))
+	if err != nil {
+		return nil, err
+	}
+	count, entities, ok, err := parseCount(first)
+	if err != nil {
+		return nil, fmt.Errorf("error counting %s: %w", path, err)
+	}
+	if !ok {
+		if entities == size {
+			return c.get(ctx, path, querystring)
+		}
+		return first, nil
+	}
+
+	if count == 0 {
+		// The endpoint counted without paging and the response holds only
+		// the count
+		return c.get(ctx, path, querystring)
+	}
+	pages := make([][]byte, (count+size-1)/size)
+	// An endpoint that counted without paging returned no entities: the
+	// first page is fetched again with the others
+	if entities >= min(count, size) {
+		if len(pages) <= 1 {
+			return first, nil
+		}
+		pages[0] = first
+	}
+	if err := c.fetchPages(ctx, pages, page); err != nil {
+		return nil, err
+	}
+	return mergePages(pages)
+}
+
+// fetchPages fills the nil pages by calling fetch with their page numbers,
+// starting at 1.
+// The calling goroutine fetches pages itself and is joined by a worker for
+// every token it can take from the context's semaphore right away. The first
+// error cancels the remaining pages.
+func (c *BaseClient) fetchPages(ctx context.Context, pages [][]byte, fetch func(ctx context.Context, pageno int) ([]byte, error)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		next     atomic.Int64
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	worker := func() {
+		for {
+			i := int(next.Add(1)) - 1
+			if i >= len(pages) || ctx.Err() != nil {
+				return
+			}
+			if pages[i] != nil {
+				continue
+			}
+			page, err := fetch(ctx, i+1)
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = fmt.Errorf("error fetching page %d: %w", i+1, err)
+					cancel()
+				})
+				return
+			}
+			pages[i] = page
+		}
+	}
+
+	if sem := semaphoreFrom(ctx); sem != nil {
+	spawn:
+		for i := 1; i < len(pages); i++ {
+			select {
+			case sem <- struct{}{}:
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer func() { <-sem }()
+					worker()
+				}()
+			default:
+				break spawn
+			}
+		}
+	}
+	worker()
+	wg.Wait()
+	if firstErr == nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return firstErr
+}
+
+// parseCount returns the __count of a count=yes response, which Nitro puts
+// in the first entry of the collection, and the number of entities in the
+// response, not counting an entry holding only __count. ok is false if the
+// response has no __count; Nitro omits the collection when it is empty.
+func parseCount(body []byte) (count, entities int, ok bool, err error) {
+	var resp map[string]json.RawMessage
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return 0, 0, false, err
+	}
+	for _, raw := range resp {
+		var entries []map[string]json.RawMessage
+		if json.Unmarshal(raw, &entries) != nil || len(entries) == 0 {
+			continue
+		}
+		for _, entry := range entries {
+			if _, counted := entry["__count"]; !counted || len(entry) > 1 {
+				entities++
+			}
+		}
+		var n json.Number
+		if json.Unmarshal(entries[0]["__count"], &n) != nil || n == "" {
+			return 0, entities, false, nil
+		}
+		c, err := n.Int64()
+		if err != nil {
+			return 0, 0, false, err
+		}
+		return int(c), entities, true, nil
+	}
+	return 0, 0, false, nil
+}
+
+// mergePages concatenates the collections of the pages, keeping the other
+// fields of the first page.
+func mergePages(pages [][]byte) ([]byte, error) {
+	var merged map[string]json.RawMessage
+	lists := make(map[string][]json.RawMessage)
+	for i, page := range pages {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(page, &fields); err != nil {
+			return nil, fmt.Errorf("error unmarshalling page %d: %w", i+1, err)
+		}
+		if i == 0 {
+			merged = fields
+		}
+		for key, raw := range fields {
+			var entries []json.RawMessage
+			if json.Unmarshal(raw, &entries) == nil {
+				lists[key] = append(lists[key], entries...)
+			}
+		}
+	}
+	for key, entries := range lists {
+		raw, err := json.Marshal(entries)
+		if err != nil {
+			return nil, err
+		}
+		merged[key] = raw
+	}
+	return json.Marshal(merged)
+}
+
+// joinQuery appends param to a query string.
+func joinQuery(querystring, param string) string {
+	if querystring == "" {
+		return param
+	}
+	return querystring + "&" + param
+}
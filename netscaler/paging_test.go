@@ -0,0 +1,129 @@
+package netscaler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// TestPaging checks that a collection larger than the page size is counted
+// with its first page, fetched page by page and merged in order.
+func TestPaging(t *testing.T) {
+	const groups = 5
+	var requests atomic.Int64
+	var countOnly atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		q := r.URL.Query()
+		counted := q.Get("count") == "yes"
+		if counted && countOnly.Load() {
+			fmt.Fprintf(w, `{"errorcode":0,"servicegroup":[{"__count":%d}]}`, groups)
+			return
+		}
+		first, last := 1, groups
+		if size, _ := strconv.Atoi(q.Get("pagesize")); size > 0 {
+			pageno, _ := strconv.Atoi(q.Get("pageno"))
+			first = (pageno-1)*size + 1
+			last = min(pageno*size, groups)
+		}
+		var entries []string
+		for i := first; i <= last; i++ {
+			if counted && i == first {
+				entries = append(entries, fmt.Sprintf(`{"servicegroupname":"sg%d","__count":%d}`, i, groups))
+				continue
+			}
+			entries = append(entries, fmt.Sprintf(`{"servicegroupname":"sg%d"}`, i))
+		}
+		fmt.Fprintf(w, `{"errorcode":0,"message":"Done","servicegroup":[%s]}`, strings.Join(entries, ","))
+	}))
+	defer srv.Close()
+
+	c, err := NewNitroClient(srv.URL, CertAuth{}, TLSConfig{}, nil)
+	if err != nil {
+		t.Fatalf("NewNitroClient: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name      string
+		pageSize  int
+		countOnly bool
+		requests  int64
+	}{
+		{"unpaged", 0, false, 1},
+		{"one page", 10, false, 1},
+		{"paged", 2, false, 3},
+		// An endpoint answering count=yes with the count alone
+		{"count only", 2, true, 4},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			requests.Store(0)
+			countOnly.Store(tc.countOnly)
+			c.SetPageSize(tc.pageSize)
+			ctx := WithSemaphore(context.Background(), make(chan struct{}, 2))
+			resp, err := GetServiceGroups(ctx, c, "")
+			if err != nil {
+				t.Fatalf("GetServiceGroups: %v", err)
+			}
+			if len(resp.ServiceGroups) != groups {
+				t.Fatalf("got %d service groups, want %d", len(resp.ServiceGroups), groups)
+			}
+			for i, sg := range resp.ServiceGroups {
+				if want := fmt.Sprintf("sg%d", i+1); sg.Name != want {
+					t.Errorf("service group %d = %q, want %q", i, sg.Name, want)
+				}
+			}
+			if n := requests.Load(); n != tc.requests {
+				t.Errorf("requests = %d, want %d", n, tc.requests)
+			}
+		})
+	}
+}
+
+// TestPagingUnsupported checks that stats are never paged and that a config
+// endpoint ignoring count=yes is not fetched a second time.
+func TestPagingUnsupported(t *testing.T) {
+	var mu sync.Mutex
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		queries = append(queries, strings.TrimPrefix(r.URL.Path, "/nitro/v1/")+"?"+r.URL.RawQuery)
+		mu.Unlock()
+		// Every query parameter is ignored
+		switch r.URL.Path {
+		case "/nitro/v1/stat/lbvserver":
+			io.WriteString(w, `{"errorcode":0,"lbvserver":[{"name":"lb1"},{"name":"lb2"},{"name":"lb3"}]}`)
+		case "/nitro/v1/config/servicegroup":
+			io.WriteString(w, `{"errorcode":0,"servicegroup":[{"servicegroupname":"sg1"},{"servicegroupname":"sg2"},{"servicegroupname":"sg3"}]}`)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewNitroClient(srv.URL, CertAuth{}, TLSConfig{}, nil)
+	if err != nil {
+		t.Fatalf("NewNitroClient: %v", err)
+	}
+	c.SetPageSize(2)
+
+	vservers, err := GetVirtualServerStats(context.Background(), c, "")
+	if err != nil {
+		t.Fatalf("GetVirtualServerStats: %v", err)
+	}
+	groups, err := GetServiceGroups(context.Background(), c, "")
+	if err != nil {
+		t.Fatalf("GetServiceGroups: %v", err)
+	}
+	if len(vservers.VirtualServerStats) != 3 || len(groups.ServiceGroups) != 3 {
+		t.Errorf("got %d virtual servers and %d service groups, want 3 each", len(vservers.VirtualServerStats), len(groups.ServiceGroups))
+	}
+	if want := []string{"stat/lbvserver?", "config/servicegroup?pagesize=2&pageno=1&count=yes"}; !slices.Equal(queries, want) {
+		t.Errorf("requests = %q, want %q", queries, want)
+	}
+}
@@ -0,0 +1,108 @@
+package netscaler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrCallBudgetExhausted is returned for requests beyond the call budget of
+// their context.
+var ErrCallBudgetExhausted = errors.New("call budget exhausted")
+
+// rateLimiter is a token bucket allowing rate requests per second on average
+// and bursts of up to burst requests.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// wait takes a token, blocking until one is available, and returns how long
+// it waited. It fails right away if ctx ends before the token would be.
+func (l *rateLimiter) wait(ctx context.Context) (time.Duration, error) {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens--
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay == 0 {
+		return 0, nil
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		l.cancel()
+		return 0, fmt.Errorf("rate limit delay of %s exceeds deadline: %w", delay, context.DeadlineExceeded)
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return delay, nil
+	case <-ctx.Done():
+		l.cancel()
+		return 0, ctx.Err()
+	}
+}
+
+// cancel returns a token taken by a request that was not sent.
+func (l *rateLimiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens++
+}
+
+// CallBudget limits the number of API requests made with a context, e.g.
+// during one scrape. It is safe for concurrent use.
+type CallBudget struct {
+	remaining atomic.Int64
+}
+
+// NewCallBudget returns a budget of the given number of requests.
+func NewCallBudget(calls int) *CallBudget {
+	b := &CallBudget{}
+	b.remaining.Store(int64(calls))
+	return b
+}
+
+// Remaining returns the number of requests left in the budget.
+func (b *CallBudget) Remaining() int {
+	return int(max(b.remaining.Load(), 0))
+}
+
+// take spends one request, reporting false if the budget is exhausted.
+func (b *CallBudget) take() bool {
+	return b.remaining.Add(-1) >= 0
+}
+
+type callBudgetKey struct{}
+
+// WithCallBudget returns a context whose API requests are charged to b. A nil b
+// exempts the requests from the budget of ctx.
+func WithCallBudget(ctx context.Context, b *CallBudget) context.Context {
+	return context.WithValue(ctx, callBudgetKey{}, b)
+}
+
+// callBudgetFrom returns the call budget of ctx, or nil if it has none.
+func callBudgetFrom(ctx context.Context) *CallBudget {
+	b, _ := ctx.Value(callBudgetKey{}).(*CallBudget)
+	return b
+}
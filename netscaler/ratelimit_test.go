@@ -0,0 +1,44 @@
+package netscaler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	l := newRateLimiter(20, 2)
+	ctx := context.Background()
+
+	start := time.Now()
+	for range 4 {
+		if _, err := l.wait(ctx); err != nil {
+			t.Fatalf("wait: %v", err)
+		}
+	}
+	// The burst covers two requests, the other two wait 50ms each
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("4 requests took %s, want at least 100ms at 20/s with burst 2", elapsed)
+	}
+
+	// A delay beyond the deadline fails right away and returns the token
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("wait with short deadline: err = %v, want deadline exceeded", err)
+	}
+}
+
+func TestCallBudget(t *testing.T) {
+	b := NewCallBudget(2)
+	if !b.take() || !b.take() {
+		t.Fatal("budget of 2 refused one of the first two calls")
+	}
+	if b.take() {
+		t.Error("budget of 2 allowed a third call")
+	}
+	if got := b.Remaining(); got != 0 {
+		t.Errorf("Remaining() = %d, want 0", got)
+	}
+}
@@ -0,0 +1,207 @@
+package netscaler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// recording is a Nitro request and its response as stored on disk.
+type recording struct {
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Query  string          `json:"query,omitempty"`
+	Status int             `json:"status"`
+	Body   json.RawMessage `json:"body,omitempty"`
+	Text   string          `json:"text,omitempty"` // body that is not JSON
+}
+
+// redacted replaces scrubbed values in recordings.
+const redacted = "REDACTED"
+
+// sensitiveKey matches the JSON keys whose values are scrubbed from recordings.
+var sensitiveKey = regexp.MustCompile(`(?i)passw|passphrase|passplain|secret|sessionid|token`)
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// SetRecording makes the client write every request and response to files
+// below dir, with passwords and session IDs scrubbed. An empty dir leaves the
+// client unchanged.
+func (c *BaseClient) SetRecording(dir string) {
+	if dir != "" {
+		c.client.Transport = recorder{next: c.client.Transport, dir: dir}
+	}
+}
+
+// SetReplay makes the client serve requests from the recordings below dir
+// (see SetRecording) instead of the network.
+func (c *BaseClient) SetReplay(dir string) {
+	c.client.Transport = replayer{dir: dir}
+}
+
+// recordingFile returns the file of the recording of req: a directory per
+// target, the managed instance for requests proxied through ADM, and a file
+// per method, resource and query.
+func recordingFile(dir string, req *http.Request) string {
+	target := req.URL.Host
+	if instance := req.Header.Get(MPSProxyInstanceHeader); instance != "" {
+		target = instance
+	}
+	name := req.Method + "_" + strings.Trim(req.URL.Path, "/")
+	if req.URL.RawQuery != "" {
+		sum := sha256.Sum256([]byte(req.URL.RawQuery))
+		name += "_" + hex.EncodeToString(sum[:4])
+	}
+	return filepath.Join(dir, unsafeFileChars.ReplaceAllString(target, "_"), unsafeFileChars.ReplaceAllString(name, "_")+".json")
+}
+
+// recorder is a transport that writes the exchanges of next to dir.
+type recorder struct {
+	next http.RoundTripper
+	dir  string
+}
+
+func (r recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	rec := recording{Method: req.Method, Path: req.URL.Path, Query: req.URL.RawQuery, Status: resp.StatusCode}
+	if scrubbed, ok := scrub(body); ok {
+		rec.Body = scrubbed
+	} else {
+		rec.Text = string(body)
+	}
+	if err := writeRecording(recordingFile(r.dir, req), rec); err != nil {
+		return nil, fmt.Errorf("failed to record response: %w", err)
+	}
+	return resp, nil
+}
+
+// CloseIdleConnections closes the idle connections of the wrapped transport.
+func (r recorder) CloseIdleConnections() {
+	if t, ok := r.next.(interface{ CloseIdleConnections() }); ok {
+		t.CloseIdleConnections()
+	}
+}
+
+// writeRecording writes rec to path, replacing an earlier recording atomically.
+func writeRecording(path string, rec recording) error {
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".recording-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// scrub returns the JSON body with the values of sensitive keys replaced,
+// reporting false if body is not JSON.
+func scrub(body []byte) (json.RawMessage, bool) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, false
+	}
+	data, err := json.Marshal(scrubValue(v))
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+func scrubValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, val := range v {
+			if _, isString := val.(string); isString && sensitiveKey.MatchString(k) {
+				v[k] = redacted
+			} else {
+				v[k] = scrubValue(val)
+			}
+		}
+	case []any:
+		for i, val := range v {
+			v[i] = scrubValue(val)
+		}
+	}
+	return v
+}
+
+// replayer is a transport serving recordings from dir. Requests without a
+// recording get a Nitro "No such resource" error.
+type replayer struct {
+	dir string
+}
+
+func (r replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	var rec recording
+	path := recordingFile(r.dir, req)
+	data, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		rec.Status = http.StatusNotFound
+		rec.Body, _ = json.Marshal(map[string]any{
+			"errorcode": NSERR_NOENT,
+			"message":   "No recording for " + req.Method + " " + req.URL.RequestURI(),
+			"severity":  "ERROR",
+		})
+	case err != nil:
+		return nil, err
+	default:
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return nil, fmt.Errorf("failed to parse recording %s: %w", path, err)
+		}
+	}
+
+	body := []byte(rec.Body)
+	if rec.Text != "" {
+		body = []byte(rec.Text)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.Status, http.StatusText(rec.Status)),
+		StatusCode:    rec.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,72 @@
+package netscaler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRecordReplay checks that recorded traffic is scrubbed and replayed
+// without the appliance.
+func TestRecordReplay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/nitro/v1/config/login":
+			io.WriteString(w, `{"errorcode":0,"sessionid":"##secret-session"}`)
+		case "/nitro/v1/stat/lbvserver":
+			io.WriteString(w, `{"errorcode":0,"lbvserver":[{"name":"lb1","totalrequests":"12345678901234567890"}]}`)
+		case "/nitro/v1/config/systemuser":
+			io.WriteString(w, `{"errorcode":0,"systemuser":[{"username":"nsroot","password":"hunter2"}]}`)
+		}
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	creds := StaticCredentials{Username: "nsroot", Password: "hunter2"}
+	c, err := NewNitroClient(srv.URL, NewSessionAuth(creds), TLSConfig{}, nil)
+	if err != nil {
+		t.Fatalf("NewNitroClient: %v", err)
+	}
+	c.SetRecording(dir)
+	ctx := context.Background()
+	recorded, err := c.GetStats(ctx, "lbvserver", "attrs=name,totalrequests")
+	if err != nil {
+		t.Fatalf("GetStats: %v", err)
+	}
+	if _, err := c.GetConfig(ctx, "systemuser", ""); err != nil {
+		t.Fatalf("GetConfig: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*", "*.json"))
+	if len(files) != 3 {
+		t.Fatalf("recorded %d files, want 3: %v", len(files), files)
+	}
+	for _, f := range files {
+		data, _ := os.ReadFile(f)
+		if strings.Contains(string(data), "hunter2") || strings.Contains(string(data), "secret-session") {
+			t.Errorf("%s contains a secret: %s", f, data)
+		}
+	}
+
+	srv.Close()
+	replay, err := NewNitroClient(srv.URL, NewSessionAuth(creds), TLSConfig{}, nil)
+	if err != nil {
+		t.Fatalf("NewNitroClient: %v", err)
+	}
+	replay.SetReplay(dir)
+	replayed, err := replay.GetStats(ctx, "lbvserver", "attrs=name,totalrequests")
+	if err != nil {
+		t.Fatalf("replayed GetStats: %v", err)
+	}
+	if !strings.Contains(string(replayed), `"12345678901234567890"`) || !strings.Contains(string(recorded), `"lb1"`) {
+		t.Errorf("replayed %s, recorded %s", replayed, recorded)
+	}
+	if _, err := replay.GetStats(ctx, "csvserver", ""); !IsNotFound(err) {
+		t.Errorf("request without recording: err = %v, want not found", err)
+	}
+}
@@ -0,0 +1,107 @@
+package netscaler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/url"
+	"time"
+)
+
+// Retry defaults of new clients, see SetRetries.
+const (
+	DefaultRetries      = 2
+	DefaultRetryBackoff = 250 * time.Millisecond
+
+	// maxRetryBackoff bounds the exponential backoff between retries.
+	maxRetryBackoff = 5 * time.Second
+)
+
+// retryPolicy retries failed idempotent requests with exponential backoff.
+type retryPolicy struct {
+	retries int
+	backoff time.Duration
+}
+
+// bodyError is a failure to read a response body, e.g. a connection reset
+// after the headers were received.
+type bodyError struct {
+	err error
+}
+
+func (e *bodyError) Error() string {
+	return fmt.Sprintf("error reading response body: %v", e.err)
+}
+
+func (e *bodyError) Unwrap() error {
+	return e.err
+}
+
+// retryReason returns why a failed request may be retried, or "" if it may
+// not: "network" for connection errors and "transient" for Nitro errors that
+// are likely to go away on their own. Nitro errors are classified by their
+// errorcode first, see IsTransient.
+func retryReason(err error) string {
+	var nitroErr *NitroError
+	var urlErr *url.Error
+	var bodyErr *bodyError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return ""
+	case errors.As(err, &nitroErr):
+		if IsTransient(nitroErr) {
+			return "transient"
+		}
+		return ""
+	case errors.As(err, &urlErr) && !urlErr.Timeout():
+		return "network"
+	case errors.As(err, &bodyErr):
+		return "network"
+	}
+	return ""
+}
+
+// delay returns the jittered backoff before the given retry, counted from 0:
+// a random duration between half and all of backoff doubled per retry.
+func (p retryPolicy) delay(retry int) time.Duration {
+	d := min(p.backoff<<retry, maxRetryBackoff)
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// do calls fn and retries it while it fails with a retryable error, up to
+// p.retries times. It gives up early if the backoff would exceed the deadline
+// of ctx and returns the last result.
+func (p retryPolicy) do(ctx context.Context, logger *slog.Logger, target, path string, fn func() ([]byte, error)) ([]byte, error) {
+	for retry := 0; ; retry++ {
+		body, err := fn()
+		if err == nil || retry >= p.retries {
+			return body, err
+		}
+		reason := retryReason(err)
+		if reason == "" {
+			return body, err
+		}
+
+		delay := p.delay(retry)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return body, err
+		}
+		observeRetry(target, path, reason)
+		if logger != nil {
+			logger.Debug("retrying Nitro API request", "target", target, "path", path, "reason", reason, "delay", delay, "err", err)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return body, err
+		}
+	}
+}
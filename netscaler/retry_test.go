@@ -0,0 +1,127 @@
+package netscaler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestRetries checks that transient failures are retried up to the limit and
+// other errors are not.
+func TestRetries(t *testing.T) {
+	var requests atomic.Int64
+	var failures int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		switch {
+		case r.URL.Path == "/nitro/v1/stat/lbvserver":
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"errorcode":258,"message":"No such resource","severity":"ERROR"}`)
+		case n <= failures:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			io.WriteString(w, `{"errorcode":0,"ns":{}}`)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewNitroClient(srv.URL, CertAuth{}, TLSConfig{}, nil)
+	if err != nil {
+		t.Fatalf("NewNitroClient: %v", err)
+	}
+	c.SetRetries(2, time.Millisecond)
+
+	for _, tc := range []struct {
+		name     string
+		resource string
+		failures int64
+		requests int64
+		ok       bool
+	}{
+		{"recovers", "ns", 2, 3, true},
+		{"gives up", "ns", 5, 3, false},
+		{"not retried", "lbvserver", 0, 1, false},
+	} {
+		requests.Store(0)
+		failures = tc.failures
+		_, err := c.GetStats(context.Background(), tc.resource, "")
+		if (err == nil) != tc.ok {
+			t.Errorf("%s: err = %v", tc.name, err)
+		}
+		if n := requests.Load(); n != tc.requests {
+			t.Errorf("%s: requests = %d, want %d", tc.name, n, tc.requests)
+		}
+	}
+
+	// A backoff beyond the deadline fails right away
+	c.SetRetries(2, time.Hour)
+	requests.Store(0)
+	failures = 5
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := c.GetStats(ctx, "ns", ""); err == nil || requests.Load() != 1 {
+		t.Errorf("retried past the deadline: err = %v, requests = %d", err, requests.Load())
+	}
+}
+
+// TestRetryBody checks that a response whose body cannot be read is retried.
+func TestRetryBody(t *testing.T) {
+	var requests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			// Promise more than is sent so that reading the body fails
+			w.Header().Set("Content-Length", "100")
+			io.WriteString(w, `{"errorcode":0`)
+			return
+		}
+		io.WriteString(w, `{"errorcode":0,"ns":{}}`)
+	}))
+	defer srv.Close()
+
+	c, err := NewNitroClient(srv.URL, CertAuth{}, TLSConfig{}, nil)
+	if err != nil {
+		t.Fatalf("NewNitroClient: %v", err)
+	}
+	c.SetRetries(2, time.Millisecond)
+	if _, err := c.GetStats(context.Background(), "ns", ""); err != nil {
+		t.Errorf("GetStats: %v", err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("requests = %d, want 2", n)
+	}
+}
+
+// TestRetryCallBudget checks that a request is charged to the call budget
+// once, however often it is retried.
+func TestRetryCallBudget(t *testing.T) {
+	var requests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		io.WriteString(w, `{"errorcode":0,"ns":{}}`)
+	}))
+	defer srv.Close()
+
+	c, err := NewNitroClient(srv.URL, CertAuth{}, TLSConfig{}, nil)
+	if err != nil {
+		t.Fatalf("NewNitroClient: %v", err)
+	}
+	c.SetRetries(2, time.Millisecond)
+	budget := NewCallBudget(2)
+	ctx := WithCallBudget(context.Background(), budget)
+	if _, err := c.GetStats(ctx, "ns", ""); err != nil {
+		t.Fatalf("GetStats: %v", err)
+	}
+	if n := requests.Load(); n != 3 {
+		t.Errorf("requests = %d, want 3", n)
+	}
+	if n := budget.Remaining(); n != 1 {
+		t.Errorf("remaining budget = %d, want 1", n)
+	}
+}
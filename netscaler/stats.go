@@ -6,301 +6,192 @@ import (
 	"fmt"
 )
 
-// getStats is a helper that retrieves and unmarshals stats from the Nitro API.
-func getStats(ctx context.Context, c *NitroClient, statsType string, querystring string) (NSAPIResponse, error) {
-	data, err := c.GetStats(ctx, statsType, querystring)
+// getResponse retrieves resource of the given kind and unmarshals the whole
+// response into R. It backs the Get* functions of the existing collectors,
+// which decode into NSAPIResponse; new resources use Stat and Config instead.
+func getResponse[R any](ctx context.Context, c Client, kind pathKind, resource string, querystring string) (R, error) {
+	var response R
+	data, err := kind.getter(c)(ctx, resource, querystring)
 	if err != nil {
-		return NSAPIResponse{}, err
+		return response, err
 	}
-
-	var response NSAPIResponse
 	if err = json.Unmarshal(data, &response); err != nil {
-		return NSAPIResponse{}, fmt.Errorf("error unmarshalling response: %w", err)
+		var zero R
+		return zero, fmt.Errorf("error unmarshalling %s response: %w", resource, err)
 	}
-
-	return response, nil
-}
-
-// getConfig is a helper that retrieves and unmarshals config from the Nitro API.
-func getConfig(ctx context.Context, c *NitroClient, configType string, querystring string) (NSAPIResponse, error) {
-	data, err := c.GetConfig(ctx, configType, querystring)
-	if err != nil {
-		return NSAPIResponse{}, err
-	}
-
-	var response NSAPIResponse
-	if err = json.Unmarshal(data, &response); err != nil {
-		return NSAPIResponse{}, fmt.Errorf("error unmarshalling response: %w", err)
-	}
-
 	return response, nil
 }
 
 // GetNSStats queries the Nitro API for ns stats
-func GetNSStats(ctx context.Context, c *NitroClient, querystring string) (NSAPIResponse, error) {
-	return getStats(ctx, c, "ns", querystring)
+func GetNSStats(ctx context.Context, c Client, querystring string) (NSAPIResponse, error) {
+	return getResponse[NSAPIResponse](ctx, c, statPath, "ns", querystring)
 }
 
 // GetInterfaceStats queries the Nitro API for interface stats
-func GetInterfaceStats(ctx context.Context, c *NitroClient, querystring string) (NSAPIResponse, error) {
-	return getStats(ctx, c, "Interface", querystring)
+func GetInterfaceStats(ctx context.Context, c Client, querystring string) (NSAPIResponse, error) {
+	return getResponse[NSAPIResponse](ctx, c, statPath, "Interface", querystring)
 }
 
 // GetVirtualServerStats queries the Nitro API for virtual server stats
-func GetVirtualServerStats(ctx context.Context, c *NitroClient, querystring string) (NSAPIResponse, error) {
-	return getStats(ctx, c, "lbvserver", querystring)
+func GetVirtualServerStats(ctx context.Context, c Client, querystring string) (NSAPIResponse, error) {
+	return getResponse[NSAPIResponse](ctx, c, statPath, "lbvserver", querystring)
 }
 
 // GetServiceStats queries the Nitro API for service stats
-func GetServiceStats(ctx context.Context, c *NitroClient, querystring string) (NSAPIResponse, error) {
-	return getStats(ctx, c, "service", querystring)
+func GetServiceStats(ctx context.Context, c Client, querystring string) (NSAPIResponse, error) {
+	return getResponse[NSAPIResponse](ctx, c, statPath, "service", querystring)
 }
 
 // GetServiceGroupMemberStats queries the Nitro API for service group member stats.
 // Uses the servicegroup/{name}?statbindings=yes endpoint which returns members inline.
-func GetServiceGroupMemberStats(ctx context.Context, c *NitroClient, servicegroupName string) (NSAPIResponse, error) {
-	return getStats(ctx, c, "servicegroup/"+servicegroupName, "statbindings=yes")
+func GetServiceGroupMemberStats(ctx context.Context, c Client, servicegroupName string) (NSAPIResponse, error) {
+	return getResponse[NSAPIResponse](ctx, c, statPath, "servicegroup/"+servicegroupName, "statbindings=yes")
 }
 
 // GetGSLBServiceStats queries the Nitro API for GSLB service stats
-func GetGSLBServiceStats(ctx context.Context, c *NitroClient, querystring string) (NSAPIResponse, error) {
-	return getStats(ctx, c, "gslbservice", querystring)
+func GetGSLBServiceStats(ctx context.Context, c Client, querystring string) (NSAPIResponse, error) {
+	return getResponse[NSAPIResponse](ctx, c, statPath, "gslbservice", querystring)
 }
 
 // GetGSLBVirtualServerStats queries the Nitro API for GSLB virtual server stats
-func GetGSLBVirtualServerStats(ctx context.Context, c *NitroClient, querystring string) (NSAPIResponse, error) {
-	return getStats(ctx, c, "gslbvserver", querystring)
+func GetGSLBVirtualServerStats(ctx context.Context, c Client, querystring string) (NSAPIResponse, error) {
+	return getResponse[NSAPIResponse](ctx, c, statPath, "gslbvserver", querystring)
 }
 
 // GetCSVirtualServerStats queries the Nitro API for CS virtual server stats
-func GetCSVirtualServerStats(ctx context.Context, c *NitroClient, querystring string) (NSAPIResponse, error) {
-	return getStats(ctx, c, "csvserver", querystring)
+func GetCSVirtualServerStats(ctx context.Context, c Client, querystring string) (NSAPIResponse, error) {
+	return getResponse[NSAPIResponse](ctx, c, statPath, "csvserver", querystring)
 }
 
 // GetVPNVirtualServerStats queries the Nitro API for VPN virtual server stats
-func GetVPNVirtualServerStats(ctx context.Context, c *NitroClient, querystring string) (NSAPIResponse, error) {
-	return getStats(ctx, c, "vpnvserver", querystring)
+func GetVPNVirtualServerStats(ctx context.Context, c Client, querystring string) (NSAPIResponse, error) {
+	return getResponse[NSAPIResponse](ctx, c, statPath, "vpnvserver", querystring)
 }
 
 // GetAAAStats queries the Nitro API for AAA stats
-func GetAAAStats(ctx context.Context, c *NitroClient, querystring string) (NSAPIResponse, error) {
-	return getStats(ctx, c, "aaa", querystring)
+func GetAAAStats(ctx context.Context, c Client, querystring string) (NSAPIResponse, error) {
+	return getResponse[NSAPIResponse](ctx, c, statPath, "aaa", querystring)
 }
 
 // GetNSLicense queries the Nitro API for license config
-func GetNSLicense(ctx context.Context, c *NitroClient, querystring string) (NSAPIResponse, error) {
-	return getConfig(ctx, c, "nslicense", querystring)
+func GetNSLicense(ctx context.Context, c Client, querystring string) (NSAPIResponse, error) {
+	return getResponse[NSAPIResponse](ctx, c, configPath, "nslicense", querystring)
 }
 
-// GetServiceGroups queries the Nitro API for service group config
-func GetServiceGroups(ctx context.Context, c *NitroClient, querystring string) (NSAPIResponse, error) {
-	return getConfig(ctx, c, "servicegroup", querystring)
+// GetNSFeature queries the Nitro API for the enabled features
+func GetNSFeature(ctx context.Context, c Client) (NSAPIResponse, error) {
+	return getResponse[NSAPIResponse](ctx, c, configPath, "nsfeature", "")
 }
 
-// GetLBVServerServiceBindings retrieves service bindings for a specific LB virtual server.
-func GetLBVServerServiceBindings(ctx context.Context, c *NitroClient, lbvserverName string) ([]LBVServerServiceBinding, error) {
-	body, err := c.GetConfig(ctx, "lbvserver_service_binding/"+lbvserverName, "")
-	if err != nil {
-		return nil, fmt.Errorf("error getting lbvserver_service_binding: %w", err)
-	}
-
-	var response BindingsResponse
-	if err = json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("error unmarshalling lbvserver_service_binding response: %w", err)
-	}
+// GetNSVersion queries the Nitro API for the firmware version
+func GetNSVersion(ctx context.Context, c Client) (NSAPIResponse, error) {
+	return getResponse[NSAPIResponse](ctx, c, configPath, "nsversion", "")
+}
 
-	return response.LBVServerServiceBindings, nil
+// GetNSMode queries the Nitro API for the enabled modes
+func GetNSMode(ctx context.Context, c Client) (NSAPIResponse, error) {
+	return getResponse[NSAPIResponse](ctx, c, configPath, "nsmode", "")
 }
 
-// GetLBVServerServiceGroupBindings retrieves service group bindings for a specific LB virtual server.
-func GetLBVServerServiceGroupBindings(ctx context.Context, c *NitroClient, lbvserverName string) ([]LBVServerServiceGroupBinding, error) {
-	body, err := c.GetConfig(ctx, "lbvserver_servicegroup_binding/"+lbvserverName, "")
-	if err != nil {
-		return nil, fmt.Errorf("error getting lbvserver_servicegroup_binding: %w", err)
-	}
+// GetServiceGroups queries the Nitro API for service group config
+func GetServiceGroups(ctx context.Context, c Client, querystring string) (NSAPIResponse, error) {
+	return getResponse[NSAPIResponse](ctx, c, configCollectionPath, "servicegroup", querystring)
+}
 
-	var response BindingsResponse
-	if err = json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("error unmarshalling lbvserver_servicegroup_binding response: %w", err)
-	}
+// GetLBVServerServiceBindings retrieves service bindings for a specific LB virtual server.
+func GetLBVServerServiceBindings(ctx context.Context, c Client, lbvserverName string) ([]LBVServerServiceBinding, error) {
+	return Config[LBVServerServiceBinding](ctx, c, "lbvserver_service_binding/"+lbvserverName, Options{})
+}
 
-	return response.LBVServerServiceGroupBindings, nil
+// GetLBVServerServiceGroupBindings retrieves service group bindings for a specific LB virtual server.
+func GetLBVServerServiceGroupBindings(ctx context.Context, c Client, lbvserverName string) ([]LBVServerServiceGroupBinding, error) {
+	return Config[LBVServerServiceGroupBinding](ctx, c, "lbvserver_servicegroup_binding/"+lbvserverName, Options{})
 }
 
 // GetCSVServerLBVServerBindings retrieves LB vserver bindings for a specific CS virtual server.
-func GetCSVServerLBVServerBindings(ctx context.Context, c *NitroClient, csvserverName string) ([]CSVServerLBVServerBinding, error) {
-	body, err := c.GetConfig(ctx, "csvserver_lbvserver_binding/"+csvserverName, "")
-	if err != nil {
-		return nil, fmt.Errorf("error getting csvserver_lbvserver_binding: %w", err)
-	}
-
-	var response BindingsResponse
-	if err = json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("error unmarshalling csvserver_lbvserver_binding response: %w", err)
-	}
-
-	return response.CSVServerLBVServerBindings, nil
+func GetCSVServerLBVServerBindings(ctx context.Context, c Client, csvserverName string) ([]CSVServerLBVServerBinding, error) {
+	return Config[CSVServerLBVServerBinding](ctx, c, "csvserver_lbvserver_binding/"+csvserverName, Options{})
 }
 
 // Bulk binding functions using bulkbindings=yes (NS 11.1+)
-// These fetch all bindings in a single API call instead of per-vserver queries.
+// These fetch all bindings in a single API call, or its pages, instead of per-vserver queries.
 
 // GetAllLBVServerServiceBindings retrieves all service bindings for all LB vservers in one call.
-func GetAllLBVServerServiceBindings(ctx context.Context, c *NitroClient) ([]LBVServerServiceBinding, error) {
-	body, err := c.GetConfig(ctx, "lbvserver_service_binding", "bulkbindings=yes")
-	if err != nil {
-		return nil, fmt.Errorf("error getting bulk lbvserver_service_binding: %w", err)
-	}
-
-	var response BulkLBVServerServiceBindingResponse
-	if err = json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("error unmarshalling bulk lbvserver_service_binding: %w", err)
-	}
-
-	return response.LBVServerServiceBindings, nil
+func GetAllLBVServerServiceBindings(ctx context.Context, c Client) ([]LBVServerServiceBinding, error) {
+	return Config[LBVServerServiceBinding](ctx, c, "lbvserver_service_binding", Options{BulkBindings: true, Collection: true})
 }
 
 // GetAllLBVServerServiceGroupBindings retrieves all service group bindings for all LB vservers in one call.
-func GetAllLBVServerServiceGroupBindings(ctx context.Context, c *NitroClient) ([]LBVServerServiceGroupBinding, error) {
-	body, err := c.GetConfig(ctx, "lbvserver_servicegroup_binding", "bulkbindings=yes")
-	if err != nil {
-		return nil, fmt.Errorf("error getting bulk lbvserver_servicegroup_binding: %w", err)
-	}
-
-	var response BulkLBVServerServiceGroupBindingResponse
-	if err = json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("error unmarshalling bulk lbvserver_servicegroup_binding: %w", err)
-	}
-
-	return response.LBVServerServiceGroupBindings, nil
+func GetAllLBVServerServiceGroupBindings(ctx context.Context, c Client) ([]LBVServerServiceGroupBinding, error) {
+	return Config[LBVServerServiceGroupBinding](ctx, c, "lbvserver_servicegroup_binding", Options{BulkBindings: true, Collection: true})
 }
 
 // GetAllCSVServerLBVServerBindings retrieves all LB vserver bindings for all CS vservers in one call.
-func GetAllCSVServerLBVServerBindings(ctx context.Context, c *NitroClient) ([]CSVServerLBVServerBinding, error) {
-	body, err := c.GetConfig(ctx, "csvserver_lbvserver_binding", "bulkbindings=yes")
-	if err != nil {
-		return nil, fmt.Errorf("error getting bulk csvserver_lbvserver_binding: %w", err)
-	}
-
-	var response BulkCSVServerLBVServerBindingResponse
-	if err = json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("error unmarshalling bulk csvserver_lbvserver_binding: %w", err)
-	}
-
-	return response.CSVServerLBVServerBindings, nil
+func GetAllCSVServerLBVServerBindings(ctx context.Context, c Client) ([]CSVServerLBVServerBinding, error) {
+	return Config[CSVServerLBVServerBinding](ctx, c, "csvserver_lbvserver_binding", Options{BulkBindings: true, Collection: true})
 }
 
 // GetAllCSVServerCSPolicyBindings retrieves all CS policy bindings for all CS vservers in one call.
-func GetAllCSVServerCSPolicyBindings(ctx context.Context, c *NitroClient) ([]CSVServerCSPolicyBinding, error) {
-	body, err := c.GetConfig(ctx, "csvserver_cspolicy_binding", "bulkbindings=yes")
-	if err != nil {
-		return nil, fmt.Errorf("error getting bulk csvserver_cspolicy_binding: %w", err)
-	}
-
-	var response BulkCSVServerCSPolicyBindingResponse
-	if err = json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("error unmarshalling bulk csvserver_cspolicy_binding: %w", err)
-	}
-
-	return response.CSVServerCSPolicyBindings, nil
+func GetAllCSVServerCSPolicyBindings(ctx context.Context, c Client) ([]CSVServerCSPolicyBinding, error) {
+	return Config[CSVServerCSPolicyBinding](ctx, c, "csvserver_cspolicy_binding", Options{BulkBindings: true, Collection: true})
 }
 
 // GetAllCSPolicies retrieves all CS policies.
-func GetAllCSPolicies(ctx context.Context, c *NitroClient) ([]CSPolicy, error) {
-	body, err := c.GetConfig(ctx, "cspolicy", "")
-	if err != nil {
-		return nil, fmt.Errorf("error getting cspolicy: %w", err)
-	}
-
-	var response CSPolicyResponse
-	if err = json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("error unmarshalling cspolicy: %w", err)
-	}
-
-	return response.CSPolicies, nil
+func GetAllCSPolicies(ctx context.Context, c Client) ([]CSPolicy, error) {
+	return Config[CSPolicy](ctx, c, "cspolicy", Options{Collection: true})
 }
 
 // GetAllCSActions retrieves all CS actions.
-func GetAllCSActions(ctx context.Context, c *NitroClient) ([]CSAction, error) {
-	body, err := c.GetConfig(ctx, "csaction", "")
-	if err != nil {
-		return nil, fmt.Errorf("error getting csaction: %w", err)
-	}
-
-	var response CSActionResponse
-	if err = json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("error unmarshalling csaction: %w", err)
-	}
-
-	return response.CSActions, nil
+func GetAllCSActions(ctx context.Context, c Client) ([]CSAction, error) {
+	return Config[CSAction](ctx, c, "csaction", Options{Collection: true})
 }
 
 // GetProtocolHTTPStats queries the Nitro API for protocol HTTP stats
-func GetProtocolHTTPStats(ctx context.Context, c *NitroClient, querystring string) (NSAPIResponse, error) {
-	return getStats(ctx, c, "protocolhttp", querystring)
+func GetProtocolHTTPStats(ctx context.Context, c Client, querystring string) (NSAPIResponse, error) {
+	return getResponse[NSAPIResponse](ctx, c, statPath, "protocolhttp", querystring)
 }
 
 // GetProtocolTCPStats queries the Nitro API for protocol TCP stats
-func GetProtocolTCPStats(ctx context.Context, c *NitroClient, querystring string) (NSAPIResponse, error) {
-	return getStats(ctx, c, "protocoltcp", querystring)
+func GetProtocolTCPStats(ctx context.Context, c Client, querystring string) (NSAPIResponse, error) {
+	return getResponse[NSAPIResponse](ctx, c, statPath, "protocoltcp", querystring)
 }
 
 // GetProtocolIPStats queries the Nitro API for protocol IP stats
-func GetProtocolIPStats(ctx context.Context, c *NitroClient, querystring string) (NSAPIResponse, error) {
-	return getStats(ctx, c, "protocolip", querystring)
+func GetProtocolIPStats(ctx context.Context, c Client, querystring string) (NSAPIResponse, error) {
+	return getResponse[NSAPIResponse](ctx, c, statPath, "protocolip", querystring)
 }
 
 // GetSSLStats queries the Nitro API for SSL stats
-func GetSSLStats(ctx context.Context, c *NitroClient, querystring string) (NSAPIResponse, error) {
-	return getStats(ctx, c, "ssl", querystring)
+func GetSSLStats(ctx context.Context, c Client, querystring string) (NSAPIResponse, error) {
+	return getResponse[NSAPIResponse](ctx, c, statPath, "ssl", querystring)
 }
 
 // GetSSLCertKeys queries the Nitro API for SSL certificate keys
-func GetSSLCertKeys(ctx context.Context, c *NitroClient, querystring string) (NSAPIResponse, error) {
-	return getConfig(ctx, c, "sslcertkey", querystring)
+func GetSSLCertKeys(ctx context.Context, c Client, querystring string) (NSAPIResponse, error) {
+	return getResponse[NSAPIResponse](ctx, c, configCollectionPath, "sslcertkey", querystring)
 }
 
 // GetSSLVServerStats queries the Nitro API for SSL virtual server stats
-func GetSSLVServerStats(ctx context.Context, c *NitroClient, querystring string) (NSAPIResponse, error) {
-	return getStats(ctx, c, "sslvserver", querystring)
+func GetSSLVServerStats(ctx context.Context, c Client, querystring string) (NSAPIResponse, error) {
+	return getResponse[NSAPIResponse](ctx, c, statPath, "sslvserver", querystring)
 }
 
 // GetSystemCPUStats queries the Nitro API for system CPU stats
-func GetSystemCPUStats(ctx context.Context, c *NitroClient, querystring string) (NSAPIResponse, error) {
-	return getStats(ctx, c, "systemcpu", querystring)
+func GetSystemCPUStats(ctx context.Context, c Client, querystring string) (NSAPIResponse, error) {
+	return getResponse[NSAPIResponse](ctx, c, statPath, "systemcpu", querystring)
 }
 
 // GetNSCapacityStats queries the Nitro API for bandwidth capacity stats
-func GetNSCapacityStats(ctx context.Context, c *NitroClient, querystring string) (NSAPIResponse, error) {
-	return getStats(ctx, c, "nscapacity", querystring)
+func GetNSCapacityStats(ctx context.Context, c Client, querystring string) (NSAPIResponse, error) {
+	return getResponse[NSAPIResponse](ctx, c, statPath, "nscapacity", querystring)
 }
 
 // GetHANodeConfig queries the Nitro API for HA node configuration
-func GetHANodeConfig(ctx context.Context, c *NitroClient) (HANodeConfigResponse, error) {
-	data, err := c.GetConfig(ctx, "hanode", "")
-	if err != nil {
-		return HANodeConfigResponse{}, err
-	}
-
-	var response HANodeConfigResponse
-	if err = json.Unmarshal(data, &response); err != nil {
-		return HANodeConfigResponse{}, fmt.Errorf("error unmarshalling hanode config response: %w", err)
-	}
-
-	return response, nil
+func GetHANodeConfig(ctx context.Context, c Client) (HANodeConfigResponse, error) {
+	return getResponse[HANodeConfigResponse](ctx, c, configPath, "hanode", "")
 }
 
 // GetHANodeStats queries the Nitro API for HA node statistics
-func GetHANodeStats(ctx context.Context, c *NitroClient) (HANodeStatsResponse, error) {
-	data, err := c.GetStats(ctx, "hanode", "")
-	if err != nil {
-		return HANodeStatsResponse{}, err
-	}
-
-	var response HANodeStatsResponse
-	if err = json.Unmarshal(data, &response); err != nil {
-		return HANodeStatsResponse{}, fmt.Errorf("error unmarshalling hanode stats response: %w", err)
-	}
-
-	return response, nil
+func GetHANodeStats(ctx context.Context, c Client) (HANodeStatsResponse, error) {
+	return getResponse[HANodeStatsResponse](ctx, c, statPath, "hanode", "")
 }
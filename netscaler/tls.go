@@ -0,0 +1,62 @@
+package netscaler
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// TLSConfig holds the TLS settings of a client connection.
+type TLSConfig struct {
+	// InsecureSkipVerify skips verification of the server certificate.
+	InsecureSkipVerify bool
+	// CAFile verifies the server certificate against the CAs in this PEM file.
+	CAFile string
+	// CertFile and KeyFile hold a PEM client certificate and key presented
+	// for mutual TLS.
+	CertFile string
+	KeyFile  string
+}
+
+// newTransport returns the HTTP transport shared by the Nitro and MPS clients.
+func newTransport(cfg TLSConfig) (*http.Transport, error) {
+	transport := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		MaxIdleConns:        20,
+		MaxIdleConnsPerHost: 20,
+		IdleConnTimeout:     30 * time.Second,
+	}
+
+	if !cfg.InsecureSkipVerify && cfg.CAFile == "" && cfg.CertFile == "" {
+		return transport, nil
+	}
+	tlsConfig := &tls.Config{}
+
+	if cfg.InsecureSkipVerify {
+		tlsConfig.InsecureSkipVerify = true
+	} else if cfg.CAFile != "" {
+		caCert, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		caCertPool := x509.NewCertPool()
+		if !caCertPool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("failed to parse CA certificate")
+		}
+		tlsConfig.RootCAs = caCertPool
+	}
+
+	if cfg.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
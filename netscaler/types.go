@@ -25,12 +25,17 @@ func (f *FlexString) UnmarshalJSON(data []byte) error {
 	return fmt.Errorf("FlexString: cannot unmarshal %s", string(data))
 }
 
-// NSAPIResponse represents the main portion of the Nitro API response
+// NSAPIResponse represents the main portion of the Nitro API response.
+// It is decoded by the Get* functions of the existing collectors only; new
+// resources are decoded with Stat and Config and need no field here.
 type NSAPIResponse struct {
 	Errorcode               int64                     `json:"errorcode"`
 	Message                 string                    `json:"message"`
 	Severity                string                    `json:"severity"`
 	NSLicense               NSLicense                 `json:"nslicense"`
+	NSFeature               NSFeature                 `json:"nsfeature"`
+	NSVersion               NSVersion                 `json:"nsversion"`
+	NSMode                  NSMode                    `json:"nsmode"`
 	NSStats                 NSStats                   `json:"ns"`
 	InterfaceStats          []InterfaceStats          `json:"Interface"`
 	VirtualServerStats      []VirtualServerStats      `json:"lbvserver"`
@@ -52,9 +57,37 @@ type NSAPIResponse struct {
 	NSCapacityStats         NSCapacityStats           `json:"nscapacity"`
 }
 
-// NSLicense represents the data returned from the /config/nslicense Nitro API endpoint
+// NSLicense represents the data returned from the /config/nslicense Nitro API endpoint.
+// A flag is nil if the appliance did not report it.
 type NSLicense struct {
 	ModelID string `json:"modelid"`
+	LB      *bool  `json:"lb"`
+	CS      *bool  `json:"cs"`
+	SSL     *bool  `json:"ssl"`
+	GSLB    *bool  `json:"gslb"`
+	SSLVPN  *bool  `json:"sslvpn"`
+	AAA     *bool  `json:"aaa"`
+}
+
+// NSFeature represents the data returned from the /config/nsfeature Nitro API endpoint.
+// A flag is nil if the appliance did not report it.
+type NSFeature struct {
+	LB     *bool `json:"lb"`
+	CS     *bool `json:"cs"`
+	SSL    *bool `json:"ssl"`
+	GSLB   *bool `json:"gslb"`
+	SSLVPN *bool `json:"sslvpn"`
+	AAA    *bool `json:"aaa"`
+}
+
+// NSVersion represents the data returned from the /config/nsversion Nitro API endpoint
+type NSVersion struct {
+	Version string `json:"version"`
+}
+
+// NSMode represents the data returned from the /config/nsmode Nitro API endpoint
+type NSMode struct {
+	Mode []string `json:"mode"` // enabled modes, e.g. FR, L3, USNIP
 }
 
 // NSStats represents the data returned from the /stat/ns Nitro API endpoint
@@ -246,13 +279,6 @@ type CSVServerLBVServerBinding struct {
 	Priority  string `json:"priority"`
 }
 
-// BindingsResponse holds the response from binding API calls.
-type BindingsResponse struct {
-	LBVServerServiceBindings      []LBVServerServiceBinding      `json:"lbvserver_service_binding"`
-	LBVServerServiceGroupBindings []LBVServerServiceGroupBinding `json:"lbvserver_servicegroup_binding"`
-	CSVServerLBVServerBindings    []CSVServerLBVServerBinding    `json:"csvserver_lbvserver_binding"`
-}
-
 // ProtocolHTTPStats represents the data returned from the /stat/protocolhttp Nitro API endpoint
 type ProtocolHTTPStats struct {
 	// Counters
@@ -445,23 +471,6 @@ type NSCapacityStats struct {
 	Bandwidth         string `json:"bandwidth"`
 }
 
-// Bulk binding response types for bulkbindings=yes queries (NS 11.1+)
-
-// BulkLBVServerServiceBindingResponse for lbvserver_service_binding?bulkbindings=yes response
-type BulkLBVServerServiceBindingResponse struct {
-	LBVServerServiceBindings []LBVServerServiceBinding `json:"lbvserver_service_binding,omitempty"`
-}
-
-// BulkLBVServerServiceGroupBindingResponse for lbvserver_servicegroup_binding?bulkbindings=yes response
-type BulkLBVServerServiceGroupBindingResponse struct {
-	LBVServerServiceGroupBindings []LBVServerServiceGroupBinding `json:"lbvserver_servicegroup_binding,omitempty"`
-}
-
-// BulkCSVServerLBVServerBindingResponse for csvserver_lbvserver_binding?bulkbindings=yes response
-type BulkCSVServerLBVServerBindingResponse struct {
-	CSVServerLBVServerBindings []CSVServerLBVServerBinding `json:"csvserver_lbvserver_binding,omitempty"`
-}
-
 // CSVServerCSPolicyBinding represents a binding between a CS virtual server and a CS policy.
 // The targetlbvserver may be set directly on the binding, or determined via policy → action.
 type CSVServerCSPolicyBinding struct {
@@ -484,21 +493,6 @@ type CSAction struct {
 	TargetLBVServer string `json:"targetlbvserver"` // Target LB vserver
 }
 
-// BulkCSVServerCSPolicyBindingResponse for csvserver_cspolicy_binding?bulkbindings=yes response
-type BulkCSVServerCSPolicyBindingResponse struct {
-	CSVServerCSPolicyBindings []CSVServerCSPolicyBinding `json:"csvserver_cspolicy_binding,omitempty"`
-}
-
-// CSPolicyResponse for cspolicy config response
-type CSPolicyResponse struct {
-	CSPolicies []CSPolicy `json:"cspolicy,omitempty"`
-}
-
-// CSActionResponse for csaction config response
-type CSActionResponse struct {
-	CSActions []CSAction `json:"csaction,omitempty"`
-}
-
 // HANodeConfig represents the data returned from the /config/hanode Nitro API endpoint
 type HANodeConfig struct {
 	ID              string `json:"id"`
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/elohmeier/netscaler-exporter/collector"
+)
+
+// probeHandler serves /probe?target=<name|host>&module=<name>.
+// Each request gathers into a fresh registry using the cached per-target exporter,
+// scraping within the request's scrape context.
+func probeHandler(manager *collector.Manager, timeoutOffset time.Duration, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+
+		target := params.Get("target")
+		if target == "" {
+			http.Error(w, "target parameter is required", http.StatusBadRequest)
+			return
+		}
+
+		// An empty module selects the configured target's module or the default module
+		module := params.Get("module")
+
+		exporter, release, err := manager.Get(target, module)
+		if errors.Is(err, collector.ErrUnknownModule) {
+			http.Error(w, "unknown module "+module, http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, collector.ErrTargetNotAllowed) {
+			http.Error(w, "target "+target+" is not configured and not allowed by -probe-allowed-hosts", http.StatusForbidden)
+			return
+		}
+		if err != nil {
+			logger.Error("failed to create exporter for probe", "target", target, "module", module, "err", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer release()
+
+		ctx, cancel := scrapeContext(r, timeoutOffset)
+		defer cancel()
+
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(exporter.WithContext(ctx))
+		promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+	}
+}
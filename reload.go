@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/elohmeier/netscaler-exporter/collector"
+	"github.com/elohmeier/netscaler-exporter/config"
+	"github.com/elohmeier/netscaler-exporter/discovery"
+)
+
+var (
+	configReloadSuccess = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "netscaler_exporter_config_last_reload_successful",
+		Help: "Whether the last configuration reload attempt was successful",
+	})
+	configReloadSeconds = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "netscaler_exporter_config_last_reload_success_timestamp_seconds",
+		Help: "Timestamp of the last successful configuration reload",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(configReloadSuccess, configReloadSeconds)
+}
+
+// reloader re-reads the configuration file and applies it, together with the
+// targets found by discovery, to the manager.
+type reloader struct {
+	path     string
+	defaults config.Target
+	manager  *collector.Manager
+	logger   *slog.Logger
+
+	mu            sync.Mutex
+	modules       map[string]*config.Config
+	targets       []config.Target
+	discovered    map[string][]config.Target // by discovery source name
+	stopDiscovery context.CancelFunc
+}
+
+// markLoaded records a successful (initial or reloaded) configuration load.
+func (r *reloader) markLoaded() {
+	configReloadSuccess.Set(1)
+	configReloadSeconds.Set(float64(time.Now().Unix()))
+}
+
+// load applies an already parsed configuration file at startup.
+func (r *reloader) load(file *config.File) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.applyFile(file)
+}
+
+// reload parses the configuration file and swaps it into the manager.
+// On error the running configuration is left untouched.
+func (r *reloader) reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.path == "" {
+		return errors.New("no config file configured (use -config.file)")
+	}
+
+	file, err := config.LoadFile(r.path)
+	if err != nil {
+		configReloadSuccess.Set(0)
+		r.logger.Error("failed to reload config file", "path", r.path, "err", err)
+		return err
+	}
+
+	r.applyFile(file)
+	r.markLoaded()
+	r.logger.Info("reloaded config file", "path", r.path, "targets", len(r.targets), "modules", len(r.modules))
+	return nil
+}
+
+// applyFile swaps in the file's modules and targets and restarts discovery.
+// Discovered targets of sources that are still configured are kept until
+// their next refresh so that their sessions survive the reload.
+// The caller must hold r.mu.
+func (r *reloader) applyFile(file *config.File) {
+	r.modules = file.ModuleConfigs(r.defaults.Config)
+	r.targets = file.ResolveTargets(r.defaults)
+
+	// Running sources exit on their own; results they deliver afterwards are discarded
+	if r.stopDiscovery != nil {
+		r.stopDiscovery()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r.stopDiscovery = cancel
+
+	discovered := make(map[string][]config.Target)
+	for _, d := range file.ResolveADMDiscoveries(r.defaults) {
+		discovered[d.Name] = r.discovered[d.Name]
+
+		adm, err := discovery.NewADM(d, r.logger)
+		if err != nil {
+			r.logger.Error("failed to create ADM discovery", "adm", d.Name, "err", err)
+			continue
+		}
+		go func() {
+			defer adm.Close()
+			adm.Run(ctx, func(targets []config.Target) {
+				r.setDiscovered(ctx, adm.Name(), targets)
+			})
+		}()
+	}
+	r.discovered = discovered
+
+	r.applyLocked()
+}
+
+// setDiscovered replaces the targets found by one discovery source.
+func (r *reloader) setDiscovered(ctx context.Context, name string, targets []config.Target) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Discard results of a source stopped by a concurrent reload
+	if ctx.Err() != nil {
+		return
+	}
+	r.discovered[name] = targets
+	r.applyLocked()
+}
+
+// applyLocked passes the configured and discovered targets to the manager.
+// Configured targets take precedence over discovered targets of the same name.
+// Exporters of removed targets are logged out in the background, so that an
+// unreachable appliance does not hold up the reload.
+// The caller must hold r.mu.
+func (r *reloader) applyLocked() {
+	targets := append([]config.Target(nil), r.targets...)
+	seen := make(map[string]bool, len(targets))
+	for _, t := range targets {
+		seen[t.Name] = true
+	}
+
+	sources := make([]string, 0, len(r.discovered))
+	for name := range r.discovered {
+		sources = append(sources, name)
+	}
+	sort.Strings(sources)
+	for _, name := range sources {
+		for _, t := range r.discovered[name] {
+			if seen[t.Name] {
+				r.logger.Debug("skipping discovered target with duplicate name", "adm", name, "target", t.Name)
+				continue
+			}
+			seen[t.Name] = true
+			targets = append(targets, t)
+		}
+	}
+
+	removed := r.manager.Reload(r.modules, targets)
+	if len(removed) > 0 {
+		go r.manager.CloseExporters(removed)
+	}
+}
+
+// watchSIGHUP reloads the configuration whenever the process receives SIGHUP.
+func (r *reloader) watchSIGHUP() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			r.logger.Info("received SIGHUP, reloading config file")
+			r.reload()
+		}
+	}()
+}
+
+// handler serves POST /-/reload.
+func (r *reloader) handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "only POST requests allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if err := r.reload(); err != nil {
+			http.Error(w, "failed to reload config: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+	}
+}
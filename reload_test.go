@@ -0,0 +1,231 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/elohmeier/netscaler-exporter/collector"
+	"github.com/elohmeier/netscaler-exporter/config"
+	"github.com/elohmeier/netscaler-exporter/netscaler/nitrotest"
+)
+
+// newTestReloader returns a reloader of the config file at path whose
+// exporters scrape only ns_stats.
+func newTestReloader(t *testing.T, path string) *reloader {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &config.Config{Labels: map[string]string{}, DisabledModules: allModulesExcept("ns_stats")}
+	defaults := config.Target{Type: "adc", Username: "user", Password: "pass", Module: config.DefaultModule, Config: cfg}
+	manager := collector.NewManager(defaults, map[string]*config.Config{config.DefaultModule: cfg}, nil, exporterFactory(4, 0, 0, logger), 0, logger)
+	t.Cleanup(manager.Close)
+	return &reloader{path: path, defaults: defaults, manager: manager, logger: logger}
+}
+
+func allModulesExcept(module string) []string {
+	var disabled []string
+	for _, m := range config.ModuleNames {
+		if m != module {
+			disabled = append(disabled, m)
+		}
+	}
+	return disabled
+}
+
+func writeConfig(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("writing config file: %v", err)
+	}
+}
+
+func targetNames(m *collector.Manager) []string {
+	var names []string
+	for _, t := range m.Targets() {
+		names = append(names, t.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// get returns the manager's exporter for target, releasing it right away.
+func get(t *testing.T, m *collector.Manager, target string) *collector.Exporter {
+	t.Helper()
+	e, release, err := m.Get(target, "")
+	if err != nil {
+		t.Fatalf("Get %s: %v", target, err)
+	}
+	release()
+	return e
+}
+
+// TestReload checks that a reload keeps the exporters of unchanged targets,
+// replaces those of changed targets and keeps the running configuration when
+// the file is invalid.
+func TestReload(t *testing.T) {
+	srv := nitrotest.NewServer(nitrotest.Fixtures{"stat/ns": `{"ns":{"memusagepcnt":42}}`})
+	defer srv.Close()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, `
+targets:
+  - {name: adc1, url: "`+srv.URL+`"}
+  - {name: adc2, url: "`+srv.URL+`/a", labels: {site: a}}
+  - {name: adc3, url: "`+srv.URL+`/b"}
+`)
+	r := newTestReloader(t, path)
+	if err := r.reload(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if got := targetNames(r.manager); len(got) != 3 {
+		t.Fatalf("targets = %v, want adc1, adc2 and adc3", got)
+	}
+	adc1, adc2 := get(t, r.manager, "adc1"), get(t, r.manager, "adc2")
+
+	writeConfig(t, path, `
+targets:
+  - {name: adc1, url: "`+srv.URL+`"}
+  - {name: adc2, url: "`+srv.URL+`/a", labels: {site: b}}
+  - {name: adc4, url: "`+srv.URL+`/c"}
+`)
+	if err := r.reload(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if got, want := targetNames(r.manager), []string{"adc1", "adc2", "adc4"}; !slices.Equal(got, want) {
+		t.Errorf("targets after reload = %v, want %v", got, want)
+	}
+	if get(t, r.manager, "adc1") != adc1 {
+		t.Error("unchanged target adc1 got a new exporter")
+	}
+	if get(t, r.manager, "adc2") == adc2 {
+		t.Error("changed target adc2 kept its exporter")
+	}
+	if v := testutil.ToFloat64(configReloadSuccess); v != 1 {
+		t.Errorf("config_last_reload_successful = %v, want 1", v)
+	}
+
+	writeConfig(t, path, "targets:\n  - {name: adc5}\n")
+	if err := r.reload(); err == nil {
+		t.Fatal("reload of an invalid file succeeded")
+	}
+	if got, want := targetNames(r.manager), []string{"adc1", "adc2", "adc4"}; !slices.Equal(got, want) {
+		t.Errorf("targets after failed reload = %v, want %v", got, want)
+	}
+	if v := testutil.ToFloat64(configReloadSuccess); v != 0 {
+		t.Errorf("config_last_reload_successful = %v, want 0", v)
+	}
+}
+
+// TestReloadSlowLogout checks that logging out a removed target does not
+// hold up the reload or probes of the other targets.
+func TestReloadSlowLogout(t *testing.T) {
+	srv := nitrotest.NewServer(nitrotest.Fixtures{"stat/ns": `{"ns":{"memusagepcnt":42}}`})
+	defer srv.Close()
+	srv.SetCredentials("user", "pass")
+	srv.AddFault(nitrotest.Fault{Resource: "config/logout", Latency: 2 * time.Second})
+
+	// Both targets are served by srv, under different URLs
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, `
+targets:
+  - {name: adc1, url: "`+srv.URL+`"}
+  - {name: adc2, url: "`+strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)+`"}
+`)
+	r := newTestReloader(t, path)
+	if err := r.reload(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(get(t, r.manager, "adc2"))
+	if _, err := reg.Gather(); err != nil {
+		t.Fatalf("gather failed: %v", err)
+	}
+
+	writeConfig(t, path, `
+targets:
+  - {name: adc1, url: "`+srv.URL+`"}
+`)
+	start := time.Now()
+	if err := r.reload(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	get(t, r.manager, "adc1")
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("reload and probe took %s, want them not to wait for the logout", elapsed)
+	}
+
+	// The logout is sent in the background
+	for deadline := time.Now().Add(time.Second); srv.Count("config/logout") == 0 && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := srv.Count("config/logout"); n != 1 {
+		t.Errorf("logouts = %d, want the removed target logged out", n)
+	}
+}
+
+// memUsage scrapes e and returns its netscaler_mem_usage.
+func memUsage(t *testing.T, e *collector.Exporter) float64 {
+	t.Helper()
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(e)
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather failed: %v", err)
+	}
+	for _, mf := range families {
+		if mf.GetName() == "netscaler_mem_usage" {
+			return mf.GetMetric()[0].GetGauge().GetValue()
+		}
+	}
+	t.Fatal("no netscaler_mem_usage")
+	return 0
+}
+
+// TestReloadReplayTargets checks that one config file can mix a target
+// replayed from a recording with a live target.
+func TestReloadReplayTargets(t *testing.T) {
+	dir := t.TempDir()
+	recorded := nitrotest.NewServer(nitrotest.Fixtures{"stat/ns": `{"ns":{"memusagepcnt":42}}`})
+	recorded.SetCredentials("user", "pass")
+	r := newTestReloader(t, filepath.Join(t.TempDir(), "config.yaml"))
+	target := r.defaults
+	target.URL = recorded.URL
+	target.RecordDir = dir
+	e, err := exporterFactory(4, 0, 0, r.logger)(target)
+	if err != nil {
+		t.Fatalf("creating exporter: %v", err)
+	}
+	if v := memUsage(t, e); v != 42 {
+		t.Fatalf("recorded mem_usage = %v, want 42", v)
+	}
+	e.Close()
+	recorded.Close()
+
+	live := nitrotest.NewServer(nitrotest.Fixtures{"stat/ns": `{"ns":{"memusagepcnt":7}}`})
+	defer live.Close()
+	writeConfig(t, r.path, `
+targets:
+  - {name: live, url: "`+live.URL+`"}
+  - {name: replayed, url: "`+recorded.URL+`", type: replay, replay: {dir: "`+dir+`"}}
+`)
+	if err := r.reload(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if v := memUsage(t, get(t, r.manager, "replayed")); v != 42 {
+		t.Errorf("replayed mem_usage = %v, want the recorded 42", v)
+	}
+	if v := memUsage(t, get(t, r.manager, "live")); v != 7 {
+		t.Errorf("live mem_usage = %v, want 7", v)
+	}
+	if n := live.Count("stat/ns"); n != 1 {
+		t.Errorf("live target scraped %d times, want 1", n)
+	}
+}
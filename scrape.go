@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/elohmeier/netscaler-exporter/collector"
+)
+
+// scrapeTimeoutHeader carries the scrape_timeout of the Prometheus job.
+const scrapeTimeoutHeader = "X-Prometheus-Scrape-Timeout-Seconds"
+
+// scrapeContext returns the context a scrape triggered by r runs in: the request
+// context, bounded by the Prometheus scrape timeout minus offset so that the
+// response arrives before Prometheus gives up. Without the header the exporter's
+// default scrape timeout applies.
+func scrapeContext(r *http.Request, offset time.Duration) (context.Context, context.CancelFunc) {
+	seconds, err := strconv.ParseFloat(r.Header.Get(scrapeTimeoutHeader), 64)
+	if err != nil || seconds <= 0 {
+		return context.WithCancel(r.Context())
+	}
+
+	timeout := time.Duration(seconds * float64(time.Second))
+	if timeout > offset {
+		timeout -= offset
+	}
+	return context.WithTimeout(r.Context(), timeout)
+}
+
+// metricsHandler serves /metrics: the default registry and, if configured, the
+// implicit target's exporter scraped within the request's scrape context.
+func metricsHandler(exporter *collector.Exporter, offset time.Duration) http.Handler {
+	if exporter == nil {
+		return promhttp.Handler()
+	}
+	return promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := scrapeContext(r, offset)
+		defer cancel()
+
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(exporter.WithContext(ctx))
+		gatherers := prometheus.Gatherers{prometheus.DefaultGatherer, registry}
+		promhttp.HandlerFor(gatherers, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+	}))
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/elohmeier/netscaler-exporter/collector"
+)
+
+// sdTargetGroup is one entry of the Prometheus HTTP service discovery format.
+type sdTargetGroup struct {
+	Targets []string          `json:"targets"`
+	Labels  map[string]string `json:"labels"`
+}
+
+// sdHandler serves /sd in the Prometheus http_sd_config format.
+// Every configured or discovered target is listed with the exporter itself,
+// as reached at externalURL, as address and the /probe parameters as __param_
+// labels.
+func sdHandler(manager *collector.Manager, externalURL *url.URL, logger *slog.Logger) http.HandlerFunc {
+	metricsPath := strings.TrimRight(externalURL.Path, "/") + "/probe"
+	return func(w http.ResponseWriter, r *http.Request) {
+		targets := manager.Targets()
+
+		groups := make([]sdTargetGroup, 0, len(targets))
+		for _, t := range targets {
+			labels := make(map[string]string, len(t.Config.Labels)+5)
+			for k, v := range t.Config.Labels {
+				labels[k] = v
+			}
+			labels["instance"] = t.Name
+			labels["__scheme__"] = externalURL.Scheme
+			labels["__metrics_path__"] = metricsPath
+			labels["__param_target"] = t.Name
+			labels["__param_module"] = t.Module
+			groups = append(groups, sdTargetGroup{
+				Targets: []string{externalURL.Host},
+				Labels:  labels,
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(groups); err != nil {
+			logger.Error("failed to write service discovery response", "err", err)
+		}
+	}
+}
+
+// externalURL returns the URL Prometheus reaches the exporter at: rawURL if
+// set, otherwise http://<host name>:<port>. The request's Host header is not
+// used, since the client controls it and it is wrong behind a reverse proxy.
+func externalURL(rawURL string, port int) (*url.URL, error) {
+	if rawURL == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get host name: %w", err)
+		}
+		return &url.URL{Scheme: "http", Host: net.JoinHostPort(hostname, strconv.Itoa(port))}, nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%q is not an absolute http or https URL", rawURL)
+	}
+	return u, nil
+}
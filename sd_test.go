@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/elohmeier/netscaler-exporter/collector"
+	"github.com/elohmeier/netscaler-exporter/config"
+)
+
+// TestSDHandler checks the target groups of configured targets and that the
+// exporter's address comes from the external URL, not the request.
+func TestSDHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &config.Config{Labels: map[string]string{"env": "prod"}}
+	factory := func(t config.Target) (*collector.Exporter, error) {
+		return collector.NewExporter(t.Config, t.URL, t.Type, t.AuthMode, t.Credentials(), t.TLS, 4, logger)
+	}
+	targets := []config.Target{
+		{Name: "adc2", URL: "https://adc2", Type: "adc", Module: "lb", Config: &config.Config{Labels: map[string]string{"env": "prod", "site": "b"}}},
+		{Name: "adc1", URL: "https://adc1", Type: "adc", Module: config.DefaultModule, Config: cfg},
+	}
+	manager := collector.NewManager(config.Target{}, map[string]*config.Config{config.DefaultModule: cfg, "lb": cfg}, targets, factory, 0, logger)
+	defer manager.Close()
+
+	u, err := externalURL("https://exporter.example.com/netscaler/", 9280)
+	if err != nil {
+		t.Fatalf("externalURL: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/sd", nil)
+	req.Host = "attacker.example"
+	rec := httptest.NewRecorder()
+	sdHandler(manager, u, logger).ServeHTTP(rec, req)
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	var groups []sdTargetGroup
+	if err := json.Unmarshal(rec.Body.Bytes(), &groups); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	want := []sdTargetGroup{
+		{Targets: []string{"exporter.example.com"}, Labels: map[string]string{
+			"env": "prod", "instance": "adc1", "__scheme__": "https", "__metrics_path__": "/netscaler/probe",
+			"__param_target": "adc1", "__param_module": "default",
+		}},
+		{Targets: []string{"exporter.example.com"}, Labels: map[string]string{
+			"env": "prod", "site": "b", "instance": "adc2", "__scheme__": "https", "__metrics_path__": "/netscaler/probe",
+			"__param_target": "adc2", "__param_module": "lb",
+		}},
+	}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("target groups = %+v, want %+v", groups, want)
+	}
+}
+
+func TestExternalURL(t *testing.T) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Skipf("no host name: %v", err)
+	}
+	tests := []struct {
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{raw: "", want: "http://" + hostname + ":9280"},
+		{raw: "http://exporter:9280", want: "http://exporter:9280"},
+		{raw: "https://exporter.example.com/netscaler", want: "https://exporter.example.com/netscaler"},
+		{raw: "exporter:9280", wantErr: true},
+		{raw: "/netscaler", wantErr: true},
+		{raw: "ftp://exporter", wantErr: true},
+	}
+	for _, tt := range tests {
+		u, err := externalURL(tt.raw, 9280)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("externalURL(%q) = %s, want an error", tt.raw, u)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("externalURL(%q): %v", tt.raw, err)
+			continue
+		}
+		if u.String() != tt.want {
+			t.Errorf("externalURL(%q) = %s, want %s", tt.raw, u, tt.want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/elohmeier/netscaler-exporter/simulator"
+)
+
+// runSimulate implements the simulate subcommand: it serves the Nitro API of
+// a simulated HA pair, node 0 on -bind-port and node 1 on the port after it.
+func runSimulate(args []string) {
+	var (
+		cfg      simulator.Config
+		bindPort int
+		debug    bool
+	)
+
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	fs.IntVar(&bindPort, "bind-port", 8080, "Port to serve the Nitro API of the first HA node on; the second node is served on the next port")
+	fs.IntVar(&cfg.CSVServers, "cs-vservers", 3, "Number of CS vservers")
+	fs.IntVar(&cfg.LBVServers, "lb-vservers", 3, "Number of LB vservers behind each CS vserver, each with its own service group")
+	fs.IntVar(&cfg.Members, "members", 4, "Number of members in each service group")
+	fs.DurationVar(&cfg.FlapInterval, "flap-interval", 2*time.Minute, "Take down another service group member at this interval (0 disables flapping)")
+	fs.DurationVar(&cfg.FailoverInterval, "failover-interval", 30*time.Minute, "Fail over to the other HA node at this interval (0 disables failovers)")
+	fs.Uint64Var(&cfg.Seed, "seed", 1, "Seed of the generated request rates, latencies and flapping members")
+	fs.StringVar(&cfg.Username, "username", "", "Username required to log in (empty accepts any credentials)")
+	fs.StringVar(&cfg.Password, "password", "", "Password required to log in")
+	fs.BoolVar(&debug, "debug", false, "Enable debug logging")
+	fs.Parse(args)
+
+	logLevel := slog.LevelInfo
+	if debug {
+		logLevel = slog.LevelDebug
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level:     logLevel,
+		AddSource: true,
+	})).With("app", app, "version", "v"+version, "build", build)
+
+	sim := simulator.New(cfg)
+	logger.Info("simulating NetScaler HA pair", "cs_vservers", cfg.CSVServers, "lb_vservers", cfg.CSVServers*cfg.LBVServers, "members", cfg.CSVServers*cfg.LBVServers*cfg.Members)
+
+	errs := make(chan error, 2)
+	for node := range 2 {
+		listenAddr := ":" + strconv.Itoa(bindPort+node)
+		logger.Info("starting simulated Nitro API", "node", node, "addr", listenAddr)
+		srv := &http.Server{
+			Addr:              listenAddr,
+			Handler:           sim.Handler(node),
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+		go func() { errs <- srv.ListenAndServe() }()
+	}
+	logger.Error("server error", "err", <-errs)
+	os.Exit(1)
+}
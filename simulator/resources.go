@@ -0,0 +1,356 @@
+package simulator
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// haNodes are the names and addresses of the simulated HA pair.
+var haNodes = [2]struct{ name, ip string }{
+	{"ns-a", "10.0.0.11"},
+	{"ns-b", "10.0.0.12"},
+}
+
+// Bytes per request and response, and share of the requests answered.
+const (
+	requestBytes  = 450
+	responseBytes = 12000
+	responseRatio = 0.995
+)
+
+// totals are the sums of the member counters behind an entity.
+type totals struct {
+	requests float64
+	rate     float64 // requests per second
+	up       int
+	members  int
+}
+
+func (t *totals) add(m *member, load float64) {
+	t.requests += m.requests
+	t.members++
+	if m.up {
+		t.rate += m.rate * load
+		t.up++
+	}
+}
+
+// conns and serverConns are the open client and server connections.
+func (t totals) conns() float64       { return math.Round(t.rate * 0.4) }
+func (t totals) serverConns() float64 { return math.Round(t.rate * 0.32) }
+
+func (t totals) state() string {
+	if t.up > 0 {
+		return "UP"
+	}
+	return "DOWN"
+}
+
+func (t totals) health() float64 {
+	if t.members == 0 {
+		return 0
+	}
+	return float64(t.up) * 100 / float64(t.members)
+}
+
+func lbTotals(lb *lbvserver, load float64) totals {
+	var t totals
+	for _, m := range lb.members {
+		t.add(m, load)
+	}
+	return t
+}
+
+func csTotals(cs *csvserver, load float64) totals {
+	var t totals
+	for _, lb := range cs.lbs {
+		for _, m := range lb.members {
+			t.add(m, load)
+		}
+	}
+	return t
+}
+
+func (s *Simulator) allTotals(load float64) totals {
+	var t totals
+	for _, m := range s.members() {
+		t.add(m, load)
+	}
+	return t
+}
+
+// resource returns the response body of resource as seen from the given HA
+// node, or false if the resource names an entity that does not exist.
+// Resources the simulator does not model are empty. The caller must hold s.mu.
+func (s *Simulator) resource(node int, resource string, now time.Time) (map[string]any, bool) {
+	load := s.load(now)
+	elapsed := now.Sub(s.start).Seconds()
+
+	if name, ok := strings.CutPrefix(resource, "stat/servicegroup/"); ok {
+		for _, cs := range s.csvservers {
+			for _, lb := range cs.lbs {
+				if lb.servicegroup == name {
+					return map[string]any{"servicegroup": []map[string]any{{"servicegroupname": name, "servicegroupmember": s.memberStats(lb, load)}}}, true
+				}
+			}
+		}
+		return nil, false
+	}
+
+	switch resource {
+	case "config/nsversion":
+		return map[string]any{"nsversion": map[string]any{"version": "NetScaler NS14.1: Build 0.0.nc (simulated)"}}, true
+	case "config/nsmode":
+		return map[string]any{"nsmode": map[string]any{"mode": []string{"FR", "L3", "Edge", "USNIP", "PMTUD"}}}, true
+	case "config/nslicense":
+		return map[string]any{"nslicense": map[string]any{"modelid": "1000", "lb": true, "cs": true, "ssl": true, "gslb": false, "sslvpn": false, "aaa": false}}, true
+	case "config/nsfeature":
+		return map[string]any{"nsfeature": map[string]any{"lb": true, "cs": true, "ssl": true, "gslb": false, "sslvpn": false, "aaa": false}}, true
+
+	case "config/hanode":
+		var nodes []map[string]any
+		for i, id := range []int{node, 1 - node} {
+			state, since := "Secondary", 0
+			if id == s.primary {
+				state, since = "Primary", int(now.Sub(s.failover).Seconds())
+			}
+			nodes = append(nodes, map[string]any{
+				"id": strconv.Itoa(i), "name": haNodes[id].name, "ipaddress": haNodes[id].ip,
+				"state": state, "hastatus": "UP", "hasync": "SUCCESS", "haprop": "ENABLED", "masterstatetime": since,
+			})
+		}
+		return map[string]any{"hanode": nodes}, true
+	case "stat/hanode":
+		state := "Secondary"
+		if node == s.primary {
+			state = "Primary"
+		}
+		return map[string]any{"hanode": map[string]any{
+			"hacurmasterstate": state, "hacurstate": "UP", "hacurstatus": "YES",
+			"hatotpktrx": counter(elapsed * 2), "hatotpkttx": counter(elapsed * 2), "hapktrxrate": 2, "hapkttxrate": 2,
+			"haerrsyncfailure": "0", "haerrproptimeout": "0", "transtime": s.failover.Format(time.ANSIC),
+		}}, true
+
+	case "stat/ns":
+		t := s.allTotals(load)
+		cpu := 10 + 40*(load-0.4)
+		return map[string]any{"ns": map[string]any{
+			"cpuusagepcnt": round(cpu), "pktcpuusagepcnt": round(cpu * 0.9), "mgmtcpuusagepcnt": 2.5,
+			"memusagepcnt": round(38 + 4*math.Sin(elapsed/900)), "disk0perusage": 24, "disk1perusage": 51,
+			"totrxmbits": counter(t.requests * requestBytes * 8 / 1e6), "tottxmbits": counter(t.requests * responseBytes * 8 / 1e6),
+			"httptotrequests": counter(t.requests), "httptotresponses": counter(t.requests * responseRatio),
+			"tcpcurclientconn": gauge(t.conns() + 20), "tcpcurclientconnestablished": gauge(t.conns()),
+			"tcpcurserverconn": gauge(t.serverConns() + 10), "tcpcurserverconnestablished": gauge(t.serverConns()),
+		}}, true
+	case "stat/systemcpu":
+		cpu := 10 + 40*(load-0.4)
+		var cpus []map[string]any
+		for i := range 4 {
+			cpus = append(cpus, map[string]any{"id": strconv.Itoa(i), "percpuuse": gauge(round(cpu + float64(i)*3))})
+		}
+		return map[string]any{"systemcpu": cpus}, true
+	case "stat/nscapacity":
+		return map[string]any{"nscapacity": map[string]any{"maxbandwidth": "10000", "minbandwidth": "10", "actualbandwidth": "1000", "bandwidth": "1000"}}, true
+	case "stat/Interface":
+		t := s.allTotals(load)
+		in, out := t.requests*requestBytes, t.requests*responseBytes
+		return map[string]any{"Interface": []map[string]any{
+			interfaceStats("0/1", "management", elapsed*1500, elapsed*3000),
+			interfaceStats("1/1", "client", in, out),
+			interfaceStats("1/2", "server", out, in),
+		}}, true
+
+	case "stat/csvserver":
+		var stats []map[string]any
+		for _, cs := range s.csvservers {
+			t := csTotals(cs, load)
+			stats = append(stats, map[string]any{
+				"name": cs.name, "state": t.state(), "tothits": counter(t.requests),
+				"totalrequests": counter(t.requests), "totalresponses": counter(t.requests * responseRatio),
+				"totalrequestbytes": counter(t.requests * requestBytes), "totalresponsebytes": counter(t.requests * responseBytes),
+				"curclntconnections": gauge(t.conns()), "cursrvrconnections": gauge(t.serverConns()), "establishedconn": gauge(t.conns()),
+				"totalpktsrecvd": counter(t.requests * 4), "totalpktssent": counter(t.requests * 10),
+				"totspillovers": "0", "deferredreq": "0", "invalidrequestresponse": "0", "invalidrequestresponsedropped": "0",
+				"totvserverdownbackuphits": "0", "curmptcpsessions": "0", "cursubflowconn": "0",
+			})
+		}
+		return map[string]any{"csvserver": stats}, true
+	case "stat/lbvserver":
+		var stats []map[string]any
+		for _, cs := range s.csvservers {
+			for _, lb := range cs.lbs {
+				t := lbTotals(lb, load)
+				stats = append(stats, map[string]any{
+					"name": lb.name, "state": t.state(), "vslbhealth": gauge(round(t.health())),
+					"actsvcs": strconv.Itoa(t.up), "inactsvcs": strconv.Itoa(t.members - t.up), "vsvrsurgecount": "0",
+					"tothits": counter(t.requests), "totalrequests": counter(t.requests), "totalresponses": counter(t.requests * responseRatio),
+					"totalrequestbytes": counter(t.requests * requestBytes), "totalresponsebytes": counter(t.requests * responseBytes),
+					"curclntconnections": gauge(t.conns()), "cursrvrconnections": gauge(t.serverConns()),
+				})
+			}
+		}
+		return map[string]any{"lbvserver": stats}, true
+	case "config/servicegroup":
+		var groups []map[string]any
+		for _, cs := range s.csvservers {
+			for _, lb := range cs.lbs {
+				groups = append(groups, map[string]any{"servicegroupname": lb.servicegroup})
+			}
+		}
+		return map[string]any{"servicegroup": groups}, true
+
+	case "config/lbvserver_servicegroup_binding":
+		var bindings []map[string]any
+		for _, cs := range s.csvservers {
+			for _, lb := range cs.lbs {
+				bindings = append(bindings, map[string]any{"name": lb.name, "servicegroupname": lb.servicegroup, "weight": "1"})
+			}
+		}
+		return map[string]any{"lbvserver_servicegroup_binding": bindings}, true
+	case "config/csvserver_lbvserver_binding":
+		// The first LB vserver of each CS vserver is its default
+		var bindings []map[string]any
+		for _, cs := range s.csvservers {
+			if len(cs.lbs) > 0 {
+				bindings = append(bindings, map[string]any{"name": cs.name, "lbvserver": cs.lbs[0].name})
+			}
+		}
+		return map[string]any{"csvserver_lbvserver_binding": bindings}, true
+	case "config/csvserver_cspolicy_binding", "config/cspolicy", "config/csaction":
+		// The other LB vservers are switched to by a policy and its action
+		var bindings, policies, actions []map[string]any
+		for _, cs := range s.csvservers {
+			for i, lb := range cs.lbs[min(1, len(cs.lbs)):] {
+				policy, action := "pol-"+strings.TrimPrefix(lb.name, "lb-"), "act-"+strings.TrimPrefix(lb.name, "lb-")
+				bindings = append(bindings, map[string]any{"name": cs.name, "policyname": policy, "priority": strconv.Itoa((i + 1) * 100)})
+				policies = append(policies, map[string]any{"policyname": policy, "action": action, "rule": `HTTP.REQ.URL.PATH.STARTSWITH("/` + lb.name + `")`})
+				actions = append(actions, map[string]any{"name": action, "targetlbvserver": lb.name})
+			}
+		}
+		switch resource {
+		case "config/csvserver_cspolicy_binding":
+			return map[string]any{"csvserver_cspolicy_binding": bindings}, true
+		case "config/cspolicy":
+			return map[string]any{"cspolicy": policies}, true
+		default:
+			return map[string]any{"csaction": actions}, true
+		}
+
+	case "stat/protocolhttp":
+		t := s.allTotals(load)
+		return map[string]any{"protocolhttp": map[string]any{
+			"httptotrequests": counter(t.requests), "httptotresponses": counter(t.requests * responseRatio),
+			"httptotgets": counter(t.requests * 0.8), "httptotposts": counter(t.requests * 0.15), "httptotothers": counter(t.requests * 0.05),
+			"httptotrxrequestbytes": counter(t.requests * requestBytes), "httptotrxresponsebytes": counter(t.requests * responseBytes),
+			"httptottxrequestbytes": counter(t.requests * requestBytes),
+			"httptot11requests":     counter(t.requests), "httptot11responses": counter(t.requests * responseRatio),
+			"httprequestsrate": round(t.rate), "httpresponsesrate": round(t.rate * responseRatio),
+			"httpgetsrate": round(t.rate * 0.8), "httppostsrate": round(t.rate * 0.15), "httpothersrate": round(t.rate * 0.05),
+			"httprxrequestbytesrate": round(t.rate * requestBytes), "httprxresponsebytesrate": round(t.rate * responseBytes),
+			"http11requestsrate": round(t.rate), "http11responsesrate": round(t.rate * responseRatio),
+		}}, true
+	case "stat/protocoltcp":
+		t := s.allTotals(load)
+		return map[string]any{"protocoltcp": map[string]any{
+			"tcptotrxpkts": counter(t.requests * 8), "tcptottxpkts": counter(t.requests * 14),
+			"tcptotrxbytes": counter(t.requests * (requestBytes + responseBytes)), "tcptottxbytes": counter(t.requests * (requestBytes + responseBytes)),
+			"tcptotclientconnopened": counter(t.requests / 5), "tcptotserverconnopened": counter(t.requests / 20),
+			"tcptotsyn": counter(t.requests / 5), "tcptotcltfin": counter(t.requests / 5), "tcptotsvrfin": counter(t.requests / 20),
+			"tcpcurclientconnestablished": gauge(t.conns()), "tcpcurserverconnestablished": gauge(t.serverConns()),
+			"tcpactiveserverconn": gauge(t.serverConns()),
+			"tcprxpktsrate":       round(t.rate * 8), "tcptxpktsrate": round(t.rate * 14), "tcpclientconnopenedrate": round(t.rate / 5),
+			"tcprxbytesrate": round(t.rate * (requestBytes + responseBytes)), "tcptxbytesrate": round(t.rate * (requestBytes + responseBytes)),
+			"tcpsynrate": round(t.rate / 5),
+		}}, true
+	case "stat/protocolip":
+		t := s.allTotals(load)
+		bytes := t.requests * (requestBytes + responseBytes)
+		return map[string]any{"protocolip": map[string]any{
+			"iptotrxpkts": counter(t.requests * 8), "iptottxpkts": counter(t.requests * 14),
+			"iptotrxbytes": counter(bytes), "iptottxbytes": counter(bytes),
+			"iptotrxmbits": counter(bytes * 8 / 1e6), "iptottxmbits": counter(bytes * 8 / 1e6),
+			"iprxpktsrate": round(t.rate * 8), "iptxpktsrate": round(t.rate * 14),
+			"iprxbytesrate": round(t.rate * (requestBytes + responseBytes)), "iptxbytesrate": round(t.rate * (requestBytes + responseBytes)),
+		}}, true
+
+	case "stat/ssl":
+		t := s.allTotals(load)
+		return map[string]any{"ssl": map[string]any{
+			"ssltotsessions": counter(t.requests / 10), "ssltotnewsessions": counter(t.requests / 40),
+			"ssltotenc": counter(t.requests * responseBytes), "ssltottlsv11sessions": "0", "ssltotsslv2sessions": "0", "ssltotsslv2handshakes": "0",
+			"sslsessionsrate": round(t.rate / 10), "sslnewsessionsrate": round(t.rate / 40),
+			"sslencrate": round(t.rate * responseBytes), "ssldecrate": round(t.rate * requestBytes), "sslcryptoutilizationstat": round(load * 8),
+		}}, true
+	case "config/sslcertkey":
+		var certs []map[string]any
+		for _, c := range s.certs {
+			certs = append(certs, map[string]any{"certkey": c.name, "daystoexpiration": int(math.Ceil(c.expires.Sub(now).Hours() / 24))})
+		}
+		return map[string]any{"sslcertkey": certs}, true
+	case "stat/sslvserver":
+		var stats []map[string]any
+		for _, cs := range s.csvservers {
+			t := csTotals(cs, load)
+			stats = append(stats, map[string]any{
+				"vservername": cs.name, "type": "CONTENT", "primaryipaddress": cs.ip, "state": t.state(),
+				"sslctxtotdecbytes": counter(t.requests * requestBytes), "sslctxtotencbytes": counter(t.requests * responseBytes),
+				"sslctxtotsessionnew": counter(t.requests / 40), "sslctxtotsessionhits": counter(t.requests / 10),
+				"vslbhealth": gauge(round(t.health())), "actsvcs": strconv.Itoa(len(cs.lbs)),
+				"sslctxencbytesrate": round(t.rate * responseBytes), "sslctxdecbytesrate": round(t.rate * requestBytes),
+				"sslctxsessionnewrate": round(t.rate / 40), "sslctxsessionhitsrate": round(t.rate / 10),
+			})
+		}
+		return map[string]any{"sslvserver": stats}, true
+	}
+
+	// Nitro omits the collection when it is empty
+	return map[string]any{}, true
+}
+
+// memberStats returns the stats of the members of lb's service group.
+func (s *Simulator) memberStats(lb *lbvserver, load float64) []map[string]any {
+	var stats []map[string]any
+	for _, m := range lb.members {
+		var t totals
+		t.add(m, load)
+		ttfb := 0.0
+		if m.up {
+			ttfb = round(m.ttfb * (0.5 + load))
+		}
+		stats = append(stats, map[string]any{
+			"servicegroupname": lb.servicegroup + "?" + m.name, "primaryipaddress": m.ip, "primaryport": m.port,
+			"state": t.state(), "avgsvrttfb": gauge(ttfb),
+			"totalrequests": counter(m.requests), "totalresponses": counter(m.requests * responseRatio),
+			"totalrequestbytes": counter(m.requests * requestBytes), "totalresponsebytes": counter(m.requests * responseBytes),
+			"curclntconnections": gauge(t.conns()), "cursrvrconnections": gauge(t.conns()), "svrestablishedconn": gauge(t.conns()),
+			"surgecount": "0", "curreusepool": gauge(math.Round(t.conns() / 2)), "maxclients": "0",
+		})
+	}
+	return stats
+}
+
+func interfaceStats(id, alias string, rxBytes, txBytes float64) map[string]any {
+	return map[string]any{
+		"id": id, "interfacealias": alias,
+		"totrxbytes": counter(rxBytes), "tottxbytes": counter(txBytes),
+		"totrxpkts": counter(rxBytes / 1000), "tottxpkts": counter(txBytes / 1000),
+		"jumbopktsreceived": "0", "jumbopktstransmitted": "0", "errpktrx": "0",
+	}
+}
+
+// counter formats a counter the way Nitro does: as a string of an integer.
+func counter(v float64) string {
+	return strconv.FormatFloat(math.Floor(v), 'f', 0, 64)
+}
+
+// gauge formats a gauge as a string.
+func gauge(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// round rounds v to two decimals.
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,306 @@
+// Package simulator serves a synthetic NetScaler Nitro API for developing
+// dashboards and alerts without an appliance.
+//
+// The simulated appliance is an HA pair with a generated topology of CS
+// vservers, each switching to LB vservers through a direct binding and CS
+// policies, with one service group of members behind every LB vserver.
+// Counters grow with a varying load, a member flaps at a fixed interval, the
+// HA nodes fail over and certificates approach their expiry.
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elohmeier/netscaler-exporter/netscaler"
+)
+
+// Config describes the simulated appliance.
+type Config struct {
+	CSVServers int // CS vservers
+	LBVServers int // LB vservers behind each CS vserver
+	Members    int // members of the service group of each LB vserver
+
+	// FlapInterval is how long a member stays down before it recovers and
+	// another one goes down (0 disables flapping).
+	FlapInterval time.Duration
+	// FailoverInterval is how long an HA node stays primary (0 disables failovers).
+	FailoverInterval time.Duration
+
+	// Seed makes the generated rates, latencies and flapping members repeatable.
+	Seed uint64
+
+	// Username and Password are required to log in, if set.
+	Username string
+	Password string
+}
+
+// certDays are the initial days to expiration of the generated certificates,
+// assigned in turn, so that some are about to expire.
+var certDays = []int{5, 21, 45, 180, 400}
+
+// Simulator holds the state of a simulated HA pair. Handler serves the Nitro
+// API of either node. It is safe for concurrent use.
+type Simulator struct {
+	cfg Config
+	now func() time.Time
+
+	mu           sync.Mutex
+	rng          *rand.Rand
+	start        time.Time
+	last         time.Time
+	csvservers   []*csvserver
+	certs        []cert
+	flapping     *member
+	nextFlap     time.Time
+	primary      int // HA node that is primary
+	failover     time.Time
+	nextFailover time.Time
+	sessions     map[string]bool
+	nextSession  int
+}
+
+type csvserver struct {
+	name string
+	ip   string
+	lbs  []*lbvserver
+}
+
+type lbvserver struct {
+	name         string
+	servicegroup string
+	members      []*member
+}
+
+type member struct {
+	name     string
+	ip       string
+	port     int
+	up       bool
+	rate     float64 // requests per second at full load
+	ttfb     float64 // average time to first byte in ms
+	requests float64
+}
+
+type cert struct {
+	name    string
+	expires time.Time
+}
+
+// New returns a simulator of the appliance described by cfg, starting now.
+func New(cfg Config) *Simulator {
+	return newSimulator(cfg, time.Now)
+}
+
+func newSimulator(cfg Config, now func() time.Time) *Simulator {
+	s := &Simulator{
+		cfg:      cfg,
+		now:      now,
+		rng:      rand.New(rand.NewPCG(cfg.Seed, cfg.Seed)),
+		sessions: make(map[string]bool),
+	}
+	s.start = now()
+	s.last = s.start
+	s.failover = s.start
+	s.nextFlap = s.start.Add(cfg.FlapInterval)
+	s.nextFailover = s.start.Add(cfg.FailoverInterval)
+
+	for c := 1; c <= cfg.CSVServers; c++ {
+		cs := &csvserver{name: fmt.Sprintf("cs-%d", c), ip: fmt.Sprintf("192.0.2.%d", c)}
+		for l := 1; l <= cfg.LBVServers; l++ {
+			lb := &lbvserver{name: fmt.Sprintf("lb-%d-%d", c, l), servicegroup: fmt.Sprintf("sg-%d-%d", c, l)}
+			for m := 1; m <= cfg.Members; m++ {
+				lb.members = append(lb.members, &member{
+					name: fmt.Sprintf("srv-%d-%d-%d", c, l, m),
+					ip:   fmt.Sprintf("10.%d.%d.%d", c, l, m),
+					port: 8080,
+					up:   true,
+					rate: 5 + s.rng.Float64()*45,
+					ttfb: 2 + s.rng.Float64()*60,
+				})
+			}
+			cs.lbs = append(cs.lbs, lb)
+		}
+		s.csvservers = append(s.csvservers, cs)
+		days := certDays[(c-1)%len(certDays)]
+		s.certs = append(s.certs, cert{name: cs.name + "-cert", expires: s.start.Add(time.Duration(days) * 24 * time.Hour)})
+	}
+	return s
+}
+
+// advance moves the simulation to now: members flap, the HA nodes fail over
+// and the counters grow. The caller must hold s.mu.
+func (s *Simulator) advance(now time.Time) {
+	for s.cfg.FlapInterval > 0 && !now.Before(s.nextFlap) {
+		s.flap()
+		s.nextFlap = s.nextFlap.Add(s.cfg.FlapInterval)
+	}
+	for s.cfg.FailoverInterval > 0 && !now.Before(s.nextFailover) {
+		s.primary = 1 - s.primary
+		s.failover = s.nextFailover
+		s.nextFailover = s.nextFailover.Add(s.cfg.FailoverInterval)
+	}
+
+	dt := now.Sub(s.last).Seconds()
+	if dt <= 0 {
+		return
+	}
+	load := s.load(now)
+	for _, m := range s.members() {
+		if m.up {
+			m.requests += m.rate * load * dt
+		}
+	}
+	s.last = now
+}
+
+// flap recovers the flapping member and takes down another one.
+func (s *Simulator) flap() {
+	if s.flapping != nil {
+		s.flapping.up = true
+	}
+	members := s.members()
+	if len(members) == 0 {
+		return
+	}
+	s.flapping = members[s.rng.IntN(len(members))]
+	s.flapping.up = false
+}
+
+// load is the share of the members' request rates served at now, varying
+// over a ten minute period.
+func (s *Simulator) load(now time.Time) float64 {
+	return 0.7 + 0.3*math.Sin(2*math.Pi*now.Sub(s.start).Seconds()/600)
+}
+
+func (s *Simulator) members() []*member {
+	var members []*member
+	for _, cs := range s.csvservers {
+		for _, lb := range cs.lbs {
+			members = append(members, lb.members...)
+		}
+	}
+	return members
+}
+
+// Handler returns the Nitro API of HA node 0 or 1.
+func (s *Simulator) Handler(node int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resource, ok := strings.CutPrefix(r.URL.Path, netscaler.APIPathV1)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		switch {
+		case r.Method == http.MethodPost && resource == "config/login":
+			s.login(w, r)
+			return
+		case r.Method == http.MethodPost && resource == "config/logout":
+			if cookie, err := r.Cookie("sessionid"); err == nil {
+				delete(s.sessions, cookie.Value)
+			}
+			writeJSON(w, http.StatusCreated, map[string]any{})
+			return
+		case r.Method != http.MethodGet:
+			writeError(w, http.StatusMethodNotAllowed, 0, "Method not allowed")
+			return
+		}
+		if !s.authorized(r) {
+			writeError(w, http.StatusOK, netscaler.NSERR_SESSION_EXPIRED, "Session expired or killed. Please login again")
+			return
+		}
+
+		now := s.now()
+		s.advance(now)
+		body, ok := s.resource(node, resource, now)
+		if !ok {
+			writeError(w, http.StatusNotFound, netscaler.NSERR_NOENT, "No such resource ["+resource+"]")
+			return
+		}
+		page(body, resource, r.URL.Query().Get("count") == "yes", r.URL.Query().Get("pagesize"), r.URL.Query().Get("pageno"))
+		writeJSON(w, http.StatusOK, body)
+	})
+}
+
+// login starts a session if the credentials match. The caller must hold s.mu.
+func (s *Simulator) login(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Login struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"login"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, 0, "Invalid login request: "+err.Error())
+		return
+	}
+	if s.cfg.Username != "" && (payload.Login.Username != s.cfg.Username || payload.Login.Password != s.cfg.Password) {
+		writeError(w, http.StatusUnauthorized, netscaler.NSERR_NOUSER, "Invalid username or password")
+		return
+	}
+	s.nextSession++
+	sessionID := "simulated-" + strconv.Itoa(s.nextSession)
+	s.sessions[sessionID] = true
+	writeJSON(w, http.StatusCreated, map[string]any{"sessionid": sessionID})
+}
+
+// authorized reports whether r has a session or valid header credentials.
+// Without configured credentials every request is authorized. The caller
+// must hold s.mu.
+func (s *Simulator) authorized(r *http.Request) bool {
+	if s.cfg.Username == "" {
+		return true
+	}
+	if cookie, err := r.Cookie("sessionid"); err == nil {
+		return s.sessions[cookie.Value]
+	}
+	return r.Header.Get("X-NITRO-USER") == s.cfg.Username && r.Header.Get("X-NITRO-PASS") == s.cfg.Password
+}
+
+// page applies the count=yes and pagesize/pageno parameters to the collection
+// of body, so that the exporter's paging works against the simulator.
+func page(body map[string]any, resource string, count bool, pagesize, pageno string) {
+	key := resource[strings.Index(resource, "/")+1:]
+	entries, ok := body[key].([]map[string]any)
+	if !ok {
+		return
+	}
+	if count {
+		body[key] = []map[string]any{{"__count": len(entries)}}
+		return
+	}
+	size, err1 := strconv.Atoi(pagesize)
+	no, err2 := strconv.Atoi(pageno)
+	if err1 != nil || err2 != nil || size < 1 || no < 1 {
+		return
+	}
+	first := min((no-1)*size, len(entries))
+	body[key] = entries[first:min(first+size, len(entries))]
+}
+
+func writeError(w http.ResponseWriter, status, errorCode int, message string) {
+	writeJSON(w, status, map[string]any{"errorcode": errorCode, "message": message, "severity": "ERROR"})
+}
+
+// writeJSON writes body with the status fields of a successful response,
+// unless it has its own.
+func writeJSON(w http.ResponseWriter, status int, body map[string]any) {
+	if _, ok := body["errorcode"]; !ok {
+		body["errorcode"] = 0
+		body["message"] = "Done"
+		body["severity"] = "NONE"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
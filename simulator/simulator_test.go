@@ -0,0 +1,146 @@
+package simulator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elohmeier/netscaler-exporter/netscaler"
+)
+
+// TestSimulator checks that the simulated appliance evolves: counters grow, a
+// member flaps, the HA nodes fail over and certificates approach expiry.
+func TestSimulator(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sim := newSimulator(Config{
+		CSVServers:       2,
+		LBVServers:       3,
+		Members:          2,
+		FlapInterval:     time.Minute,
+		FailoverInterval: time.Hour,
+		Username:         "nsroot",
+		Password:         "nsroot",
+	}, func() time.Time { return now })
+
+	clients := make([]*netscaler.NitroClient, 2)
+	for node := range clients {
+		srv := httptest.NewServer(sim.Handler(node))
+		defer srv.Close()
+		c, err := netscaler.NewNitroClient(srv.URL, netscaler.NewSessionAuth(netscaler.StaticCredentials{Username: "nsroot", Password: "nsroot"}), netscaler.TLSConfig{}, nil)
+		if err != nil {
+			t.Fatalf("NewNitroClient: %v", err)
+		}
+		clients[node] = c
+	}
+	ctx := context.Background()
+
+	requests := func() (total float64, down int) {
+		t.Helper()
+		resp, err := netscaler.GetVirtualServerStats(ctx, clients[0], "")
+		if err != nil {
+			t.Fatalf("GetVirtualServerStats: %v", err)
+		}
+		if len(resp.VirtualServerStats) != 6 {
+			t.Fatalf("got %d lbvservers, want 6", len(resp.VirtualServerStats))
+		}
+		for _, vs := range resp.VirtualServerStats {
+			n, _ := strconv.ParseFloat(vs.TotalRequests, 64)
+			total += n
+			if vs.Health != "100" {
+				down++
+			}
+		}
+		return total, down
+	}
+	primary := func(node int) string {
+		t.Helper()
+		resp, err := netscaler.GetHANodeStats(ctx, clients[node])
+		if err != nil {
+			t.Fatalf("GetHANodeStats: %v", err)
+		}
+		return resp.HANode.HACurMasterState
+	}
+
+	before, down := requests()
+	if before != 0 || down != 0 {
+		t.Errorf("at start: %v requests and %d degraded lbvservers, want none", before, down)
+	}
+	if primary(0) != "Primary" || primary(1) != "Secondary" {
+		t.Errorf("at start: nodes are %s and %s, want Primary and Secondary", primary(0), primary(1))
+	}
+
+	now = now.Add(90 * time.Second)
+	after, down := requests()
+	if after <= before {
+		t.Errorf("requests went from %v to %v, want them to grow", before, after)
+	}
+	if down != 1 {
+		t.Errorf("%d degraded lbvservers after a flap, want 1", down)
+	}
+
+	now = now.Add(time.Hour)
+	if primary(0) != "Secondary" || primary(1) != "Primary" {
+		t.Errorf("after failover: nodes are %s and %s, want Secondary and Primary", primary(0), primary(1))
+	}
+
+	certs, err := netscaler.GetSSLCertKeys(ctx, clients[0], "")
+	if err != nil {
+		t.Fatalf("GetSSLCertKeys: %v", err)
+	}
+	if len(certs.SSLCertKeys) != 2 || string(certs.SSLCertKeys[0].DaysToExpiration) != "5" {
+		t.Errorf("got certificates %+v, want 2, the first expiring in 5 days", certs.SSLCertKeys)
+	}
+}
+
+// TestSimulatorSessions checks that a malformed login is rejected and that a
+// session ends with its logout.
+func TestSimulatorSessions(t *testing.T) {
+	sim := newSimulator(Config{CSVServers: 1, LBVServers: 1, Members: 1, Username: "nsroot", Password: "nsroot"}, time.Now)
+	srv := httptest.NewServer(sim.Handler(0))
+	defer srv.Close()
+
+	call := func(method, resource, body, session string) (int, map[string]any) {
+		t.Helper()
+		req, err := http.NewRequest(method, srv.URL+netscaler.APIPathV1+resource, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if session != "" {
+			req.AddCookie(&http.Cookie{Name: "sessionid", Value: session})
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, resource, err)
+		}
+		defer resp.Body.Close()
+		var payload map[string]any
+		if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+			t.Fatalf("decoding %s response: %v", resource, err)
+		}
+		return resp.StatusCode, payload
+	}
+
+	if status, _ := call(http.MethodPost, "config/login", `{"login":`, ""); status != http.StatusBadRequest {
+		t.Errorf("malformed login status = %d, want 400", status)
+	}
+	status, payload := call(http.MethodPost, "config/login", `{"login":{"username":"nsroot","password":"nsroot"}}`, "")
+	session, _ := payload["sessionid"].(string)
+	if status != http.StatusCreated || session == "" {
+		t.Fatalf("login = %d %v, want a session", status, payload)
+	}
+	if _, payload := call(http.MethodGet, "stat/ns", "", session); payload["errorcode"] != 0.0 {
+		t.Errorf("stat/ns with the session = %v, want errorcode 0", payload)
+	}
+
+	if status, _ := call(http.MethodPost, "config/logout", `{"logout":{}}`, session); status != http.StatusCreated {
+		t.Errorf("logout status = %d, want 201", status)
+	}
+	if _, payload := call(http.MethodGet, "stat/ns", "", session); payload["errorcode"] != float64(netscaler.NSERR_SESSION_EXPIRED) {
+		t.Errorf("stat/ns after logout = %v, want errorcode %d", payload, netscaler.NSERR_SESSION_EXPIRED)
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/elohmeier/netscaler-exporter/collector"
+	"github.com/elohmeier/netscaler-exporter/config"
+)
+
+// statusHandler serves /status/modules: the failing modules of every target,
+// whether their circuit is open and why. The implicit target from -url is
+// listed first if configured.
+func statusHandler(exporter *collector.Exporter, url string, manager *collector.Manager, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var status []collector.TargetStatus
+		if exporter != nil {
+			status = append(status, collector.TargetStatus{Target: url, Module: config.DefaultModule, Modules: exporter.ModuleStatus()})
+		}
+		status = append(status, manager.Status()...)
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(status); err != nil {
+			logger.Error("failed to write module status response", "err", err)
+		}
+	}
+}